@@ -1,83 +0,0 @@
-package aaa
-
-import (
-	"fmt"
-	"log/slog"
-	"os"
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
-)
-
-const secretKey = "something secret here"
-const adminRole = "superuser"
-
-type AAA struct {
-	users    map[string]string
-	tokenTTL time.Duration
-	log      *slog.Logger
-}
-
-func New(tokenTTL time.Duration, log *slog.Logger) (AAA, error) {
-	const adminUser = "ADMIN_USER"
-	const adminPass = "ADMIN_PASSWORD"
-	user, ok := os.LookupEnv(adminUser)
-	if !ok {
-		return AAA{}, fmt.Errorf("could not get admin user from enviroment")
-	}
-	password, ok := os.LookupEnv(adminPass)
-	if !ok {
-		return AAA{}, fmt.Errorf("could not get admin password from enviroment")
-	}
-
-	return AAA{
-		users:    map[string]string{user: password},
-		tokenTTL: tokenTTL,
-		log:      log,
-	}, nil
-}
-
-func (a AAA) Login(name, password string) (string, error) {
-	pass, ok := a.users[name]
-	if !ok || pass != password {
-		return "", fmt.Errorf("invalid credentials")
-	}
-
-	claims := jwt.RegisteredClaims{
-		Subject:   adminRole,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.tokenTTL)),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secretKey))
-	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
-	}
-
-	return tokenString, nil
-}
-
-func (a AAA) Verify(tokenString string) error {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secretKey), nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to parse token: %w", err)
-	}
-
-	if !token.Valid {
-		return fmt.Errorf("invalid token")
-	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		if sub, err := claims.GetSubject(); err == nil && sub != adminRole {
-			return fmt.Errorf("invalid subject")
-		}
-	}
-
-	return nil
-}
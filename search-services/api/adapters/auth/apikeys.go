@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"yadro.com/course/api/core"
+)
+
+// maxKeyName limits API key names in runes.
+const maxKeyName = 64
+
+// APIKeyIssuer makes API keys, see APIKeyAuth.Issue.
+type APIKeyIssuer interface {
+	Issue(ctx context.Context, key core.APIKey) (string, core.APIKey, error)
+}
+
+// APIKeyAuth issues API keys and authenticates requests sent with them as
+// "ApiKey <id>.<secret>". Only a hash of the secret is stored.
+type APIKeyAuth struct {
+	keys core.APIKeys
+}
+
+func NewAPIKeyAuth(keys core.APIKeys) *APIKeyAuth {
+	return &APIKeyAuth{keys: keys}
+}
+
+// Issue stores a new key and returns it with the secret, which cannot be
+// recovered afterwards.
+func (a *APIKeyAuth) Issue(ctx context.Context, key core.APIKey) (string, core.APIKey, error) {
+	key.Name = strings.TrimSpace(key.Name)
+	if key.Name == "" || utf8.RuneCountInString(key.Name) > maxKeyName {
+		return "", core.APIKey{}, fmt.Errorf("%w: key name must be 1 to %d characters long", core.ErrBadArguments, maxKeyName)
+	}
+	if !key.Role.Valid() {
+		return "", core.APIKey{}, fmt.Errorf("%w: unknown role %q", core.ErrBadArguments, key.Role)
+	}
+	if key.Rate < 0 {
+		return "", core.APIKey{}, fmt.Errorf("%w: negative rate", core.ErrBadArguments)
+	}
+	if !key.ExpiresAt.IsZero() && key.ExpiresAt.Before(time.Now()) {
+		return "", core.APIKey{}, fmt.Errorf("%w: key expires in the past", core.ErrBadArguments)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", core.APIKey{}, err
+	}
+	secret, err := randomToken()
+	if err != nil {
+		return "", core.APIKey{}, err
+	}
+	key.ID = hex.EncodeToString(id)
+	key.CreatedAt = time.Now()
+	key.LastUsedAt = time.Time{}
+	if err := a.keys.CreateAPIKey(ctx, key, hashToken(secret)); err != nil {
+		return "", core.APIKey{}, err
+	}
+	return key.ID + "." + secret, key, nil
+}
+
+func (a *APIKeyAuth) Authenticate(ctx context.Context, scheme, credentials string) (*CustomClaims, error) {
+	if scheme != "ApiKey" {
+		return nil, ErrUnsupportedScheme
+	}
+	id, secret, ok := strings.Cut(credentials, ".")
+	if !ok {
+		return nil, fmt.Errorf("malformed api key")
+	}
+	key, hash, err := a.keys.APIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			return nil, fmt.Errorf("unknown api key")
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(hashToken(secret))) != 1 {
+		return nil, fmt.Errorf("unknown api key")
+	}
+	if !key.ExpiresAt.IsZero() && key.ExpiresAt.Before(time.Now()) {
+		return nil, fmt.Errorf("api key expired")
+	}
+	if err := a.keys.TouchAPIKey(ctx, key.ID); err != nil {
+		return nil, err
+	}
+	return &CustomClaims{
+		User:   "apikey:" + key.Name,
+		Role:   key.Role,
+		APIKey: key.ID,
+		Rate:   key.Rate,
+	}, nil
+}
@@ -1,71 +1,205 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"yadro.com/course/api/core"
 )
 
 type Authorizer interface {
-	Login(user, password string) (string, error)
-	Validate(tokenString string) (*CustomClaims, error)
+	Login(ctx context.Context, user, password string) (TokenPair, error)
+	// LoginExternal logs in a user authenticated elsewhere, e.g. by an
+	// OIDC provider, keeping it among the users with its current role.
+	LoginExternal(ctx context.Context, user core.User) (TokenPair, error)
+	// Refresh exchanges a refresh token for a new pair, the old refresh
+	// token is used up.
+	Refresh(ctx context.Context, refreshToken string) (TokenPair, error)
+	// Logout revokes the access token of claims and, if given, the
+	// refresh token of the same user.
+	Logout(ctx context.Context, claims *CustomClaims, refreshToken string) error
+	Validate(ctx context.Context, tokenString string) (*CustomClaims, error)
+}
+
+// KeyPublisher exposes the public keys tokens can be verified with.
+type KeyPublisher interface {
+	JWKS() JWKS
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the access token lifetime in seconds.
+	ExpiresIn int64 `json:"expires_in"`
 }
 
 type Auth struct {
-	adminUser     string
-	adminPassword string
-	tokenTTL      time.Duration
-	secretKey     []byte
+	users      core.Users
+	sessions   core.Sessions
+	keys       *Keys
+	tokenTTL   time.Duration
+	refreshTTL time.Duration
 }
 
 type CustomClaims struct {
-	User string `json:"user"`
+	User string    `json:"user"`
+	Role core.Role `json:"role"`
+	// APIKey and its Rate are set for requests with an API key,
+	// they never go into tokens
+	APIKey string `json:"-"`
+	Rate   int    `json:"-"`
 	jwt.RegisteredClaims
 }
 
-func New(adminUser, adminPassword string, ttl time.Duration) *Auth {
+func New(users core.Users, sessions core.Sessions, keys *Keys, ttl, refreshTTL time.Duration) *Auth {
 	return &Auth{
-		adminUser:     adminUser,
-		adminPassword: adminPassword,
-		tokenTTL:      ttl,
-		secretKey: []byte("super-secret-key-for-task-7"),
+		users:      users,
+		sessions:   sessions,
+		keys:       keys,
+		tokenTTL:   ttl,
+		refreshTTL: refreshTTL,
+	}
+}
+
+func (a *Auth) Login(ctx context.Context, user, password string) (TokenPair, error) {
+	u, err := a.users.Verify(ctx, user, password)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			return TokenPair{}, fmt.Errorf("invalid credentials")
+		}
+		return TokenPair{}, err
 	}
+	return a.issue(ctx, u)
 }
 
-func (a *Auth) Login(user, password string) (string, error) {
-	if user != a.adminUser || password != a.adminPassword {
-		return "", fmt.Errorf("invalid credentials")
+func (a *Auth) LoginExternal(ctx context.Context, user core.User) (TokenPair, error) {
+	if err := a.users.ProvisionUser(ctx, user); err != nil {
+		return TokenPair{}, err
 	}
+	return a.issue(ctx, user)
+}
 
+func (a *Auth) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	u, err := a.sessions.TakeRefresh(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			return TokenPair{}, fmt.Errorf("invalid refresh token")
+		}
+		return TokenPair{}, err
+	}
+	return a.issue(ctx, u)
+}
+
+func (a *Auth) Logout(ctx context.Context, claims *CustomClaims, refreshToken string) error {
+	if claims.APIKey != "" {
+		return fmt.Errorf("%w: api keys are revoked by deleting them", core.ErrBadArguments)
+	}
+	if claims.ExpiresAt != nil {
+		if err := a.sessions.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+	if refreshToken != "" {
+		return a.sessions.DeleteRefresh(ctx, hashToken(refreshToken), claims.User)
+	}
+	return nil
+}
+
+// issue makes an access token with the current role of the user and
+// a refresh token kept in sessions.
+func (a *Auth) issue(ctx context.Context, u core.User) (TokenPair, error) {
+	jti, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	now := time.Now()
 	claims := CustomClaims{
-		User: "superuser",
+		User: u.Name,
+		Role: u.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.tokenTTL)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   "superuser",
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   u.Name,
 		},
 	}
+	access, err := a.keys.sign(claims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refresh, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if err := a.sessions.SaveRefresh(ctx, hashToken(refresh), u.Name, now.Add(a.refreshTTL)); err != nil {
+		return TokenPair{}, err
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(a.secretKey)
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(a.tokenTTL / time.Second),
+	}, nil
 }
 
-func (a *Auth) Validate(tokenString string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return a.secretKey, nil
-	})
+func (a *Auth) Validate(ctx context.Context, tokenString string) (*CustomClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, a.keys.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*CustomClaims)
+	if !ok || !token.Valid || claims.ID == "" {
+		return nil, fmt.Errorf("invalid token")
+	}
 
+	revoked, err := a.sessions.Revoked(ctx, claims.ID)
 	if err != nil {
 		return nil, err
 	}
+	if revoked {
+		return nil, fmt.Errorf("token is revoked")
+	}
+	return claims, nil
+}
+
+func (a *Auth) JWKS() JWKS {
+	return a.keys.JWKS()
+}
+
+type claimsKey struct{}
 
-	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid {
-		return claims, nil
+// NewContext returns a context carrying claims of an authorized request.
+func NewContext(ctx context.Context, claims *CustomClaims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+func FromContext(ctx context.Context) (*CustomClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*CustomClaims)
+	return claims, ok
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	return nil, fmt.Errorf("invalid token")
+// hashToken is how refresh tokens and API key secrets are stored,
+// they are random enough for a plain hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
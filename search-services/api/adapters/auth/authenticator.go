@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnsupportedScheme tells the next authenticator of a chain to try.
+var ErrUnsupportedScheme = errors.New("unsupported authorization scheme")
+
+// Authenticator checks credentials of an Authorization header, e.g.
+// scheme "Bearer" and a token as credentials.
+type Authenticator interface {
+	Authenticate(ctx context.Context, scheme, credentials string) (*CustomClaims, error)
+}
+
+// TokenValidator validates access tokens, see Auth.Validate.
+type TokenValidator interface {
+	Validate(ctx context.Context, tokenString string) (*CustomClaims, error)
+}
+
+type tokenAuthenticator struct {
+	validator TokenValidator
+}
+
+// NewTokenAuthenticator accepts access tokens sent as "Bearer" or "Token".
+func NewTokenAuthenticator(validator TokenValidator) Authenticator {
+	return tokenAuthenticator{validator: validator}
+}
+
+func (a tokenAuthenticator) Authenticate(ctx context.Context, scheme, credentials string) (*CustomClaims, error) {
+	if scheme != "Bearer" && scheme != "Token" {
+		return nil, ErrUnsupportedScheme
+	}
+	return a.validator.Validate(ctx, credentials)
+}
@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minSecret is the shortest HS256 secret accepted, as long as the hash.
+const minSecret = 32
+
+// KeyConfig selects the token signing key and the keys tokens are verified
+// with. The algorithm follows the key: HS256 for a secret, RS256 for an RSA
+// key and EdDSA for an Ed25519 key.
+type KeyConfig struct {
+	// KeyID is sent in the kid header, derived from the key if empty.
+	KeyID string
+	// Secret is an HS256 secret, used if KeyFile is empty.
+	Secret string
+	// KeyFile holds a PEM private key or an HS256 secret.
+	KeyFile string
+	// VerifyKeys maps key IDs to files with PEM public keys or secrets,
+	// e.g. of keys rotated out still having tokens around.
+	VerifyKeys map[string]string
+}
+
+type key struct {
+	id     string
+	method jwt.SigningMethod
+	// sign is nil for verification only keys
+	sign   any
+	verify any
+}
+
+// Keys signs tokens with one key and verifies them with any known one.
+type Keys struct {
+	signing   *key
+	byID      map[string]*key
+	generated bool
+}
+
+// LoadKeys reads the keys of cfg. Without a signing key configured
+// an Ed25519 key is generated, tokens then do not survive restarts.
+func LoadKeys(cfg KeyConfig) (*Keys, error) {
+	var signing *key
+	var generated bool
+	var err error
+	switch {
+	case cfg.KeyFile != "":
+		data, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read signing key: %w", err)
+		}
+		signing, err = parseKey(data, true)
+		if err != nil {
+			return nil, fmt.Errorf("bad signing key %s: %w", cfg.KeyFile, err)
+		}
+	case cfg.Secret != "":
+		if signing, err = secretKey([]byte(cfg.Secret)); err != nil {
+			return nil, err
+		}
+	default:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		signing = &key{method: jwt.SigningMethodEdDSA, sign: private, verify: private.Public()}
+		generated = true
+	}
+	if cfg.KeyID != "" {
+		signing.id = cfg.KeyID
+	} else if signing.id, err = keyID(signing.verify); err != nil {
+		return nil, err
+	}
+
+	keys := &Keys{
+		signing:   signing,
+		byID:      map[string]*key{signing.id: signing},
+		generated: generated,
+	}
+	for id, file := range cfg.VerifyKeys {
+		if _, ok := keys.byID[id]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read verification key: %w", err)
+		}
+		k, err := parseKey(data, false)
+		if err != nil {
+			return nil, fmt.Errorf("bad verification key %s: %w", file, err)
+		}
+		k.id = id
+		keys.byID[id] = k
+	}
+	return keys, nil
+}
+
+// Generated tells if the signing key was not configured.
+func (k *Keys) Generated() bool {
+	return k.generated
+}
+
+func (k *Keys) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	token.Header["kid"] = k.signing.id
+	return token.SignedString(k.signing.sign)
+}
+
+// keyFunc finds the key a token is signed with by its kid header.
+func (k *Keys) keyFunc(token *jwt.Token) (any, error) {
+	id, _ := token.Header["kid"].(string)
+	key, ok := k.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", id)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verify, nil
+}
+
+// JWK is a public key as published in a JWKS, RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys, HS256 secrets are never published.
+func (k *Keys) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range k.byID {
+		jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: key.method.Alg()}
+		switch public := key.verify.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// parseKey reads a PEM key, anything else is taken for a secret.
+// Private keys are only used to verify unless sign is set.
+func parseKey(data []byte, sign bool) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return secretKey([]byte(strings.TrimSpace(string(data))))
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var k key
+	switch parsed := parsed.(type) {
+	case *rsa.PrivateKey:
+		k = key{method: jwt.SigningMethodRS256, sign: parsed, verify: &parsed.PublicKey}
+	case ed25519.PrivateKey:
+		k = key{method: jwt.SigningMethodEdDSA, sign: parsed, verify: parsed.Public()}
+	case *rsa.PublicKey:
+		k = key{method: jwt.SigningMethodRS256, verify: parsed}
+	case ed25519.PublicKey:
+		k = key{method: jwt.SigningMethodEdDSA, verify: parsed}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if sign && k.sign == nil {
+		return nil, fmt.Errorf("signing needs a private key")
+	}
+	if !sign {
+		k.sign = nil
+	}
+	return &k, nil
+}
+
+func secretKey(secret []byte) (*key, error) {
+	if len(secret) < minSecret {
+		return nil, fmt.Errorf("secret must be at least %d bytes long", minSecret)
+	}
+	return &key{method: jwt.SigningMethodHS256, sign: secret, verify: secret}, nil
+}
+
+// keyID derives a key ID from the public key or the secret hash.
+func keyID(verify any) (string, error) {
+	data, ok := verify.([]byte)
+	if !ok {
+		var err error
+		if data, err = x509.MarshalPKIXPublicKey(verify); err != nil {
+			return "", err
+		}
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	name := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return name
+}
+
+func verify(keys *Keys, token string) error {
+	_, err := jwt.Parse(token, keys.keyFunc)
+	return err
+}
+
+func TestLoadKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	require.NoError(t, err)
+	edPublicDER, err := x509.MarshalPKIXPublicKey(edPublic)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		cfg  KeyConfig
+		alg  string
+		jwks int
+		err  string
+	}{
+		{name: "generated", alg: "EdDSA", jwks: 1},
+		{name: "secret", cfg: KeyConfig{Secret: strings.Repeat("s", minSecret)}, alg: "HS256"},
+		{name: "short secret", cfg: KeyConfig{Secret: "short"}, err: "at least"},
+		{name: "rsa", cfg: KeyConfig{KeyFile: writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))}, alg: "RS256", jwks: 1},
+		{name: "ed25519", cfg: KeyConfig{KeyID: "ed", KeyFile: writePEM(t, "PRIVATE KEY", edDER)}, alg: "EdDSA", jwks: 1},
+		{name: "public key cannot sign", cfg: KeyConfig{KeyFile: writePEM(t, "PUBLIC KEY", edPublicDER)}, err: "private key"},
+		{name: "missing file", cfg: KeyConfig{KeyFile: filepath.Join(t.TempDir(), "nope")}, err: "cannot read"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := LoadKeys(tc.cfg)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.name == "generated", keys.Generated())
+			assert.Equal(t, tc.alg, keys.signing.method.Alg())
+			if tc.cfg.KeyID != "" {
+				assert.Equal(t, tc.cfg.KeyID, keys.signing.id)
+			}
+
+			token, err := keys.sign(jwt.MapClaims{"sub": "ann"})
+			require.NoError(t, err)
+			assert.NoError(t, verify(keys, token))
+
+			// secrets are never published
+			set := keys.JWKS()
+			require.Len(t, set.Keys, tc.jwks)
+			for _, jwk := range set.Keys {
+				assert.Equal(t, keys.signing.id, jwk.KeyID)
+				assert.Equal(t, tc.alg, jwk.Algorithm)
+			}
+		})
+	}
+}
+
+func TestLoadKeys_Rotation(t *testing.T) {
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	oldDER, err := x509.MarshalPKCS8PrivateKey(oldKey)
+	require.NoError(t, err)
+	oldFile := writePEM(t, "PRIVATE KEY", oldDER)
+	old, err := LoadKeys(KeyConfig{KeyID: "old", KeyFile: oldFile})
+	require.NoError(t, err)
+	oldToken, err := old.sign(jwt.MapClaims{"sub": "ann"})
+	require.NoError(t, err)
+
+	// a new key without the old one rejects old tokens
+	fresh, err := LoadKeys(KeyConfig{KeyID: "new"})
+	require.NoError(t, err)
+	assert.ErrorContains(t, verify(fresh, oldToken), "unknown key id")
+
+	// the old key kept for verification accepts them, but never signs
+	rotated, err := LoadKeys(KeyConfig{KeyID: "new", VerifyKeys: map[string]string{"old": oldFile}})
+	require.NoError(t, err)
+	assert.NoError(t, verify(rotated, oldToken))
+	assert.Nil(t, rotated.byID["old"].sign)
+	newToken, err := rotated.sign(jwt.MapClaims{"sub": "ann"})
+	require.NoError(t, err)
+	assert.ErrorContains(t, verify(old, newToken), "unknown key id")
+
+	set := rotated.JWKS()
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, "new", set.Keys[0].KeyID)
+	assert.Equal(t, "old", set.Keys[1].KeyID)
+
+	_, err = LoadKeys(KeyConfig{KeyID: "old", VerifyKeys: map[string]string{"old": oldFile}})
+	assert.ErrorContains(t, err, "duplicate key id")
+
+	// a token claiming another algorithm for the key is refused
+	hs, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "ann"}).SignedString([]byte("x"))
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(hs, jwt.MapClaims{})
+	require.NoError(t, err)
+	parsed.Header["kid"] = "new"
+	_, err = rotated.keyFunc(parsed)
+	assert.ErrorContains(t, err, "unexpected signing method")
+}
@@ -0,0 +1,287 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"yadro.com/course/api/core"
+)
+
+// ErrNoRole is returned for users whose claims map to no role.
+var ErrNoRole = errors.New("no role for user")
+
+// Config of the relying party. RoleClaim values, a string or a list, are
+// mapped to local roles by Roles, the highest role wins. Users without
+// a mapped role get DefaultRole, or cannot log in if it is empty.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is where the provider sends users back with a code.
+	RedirectURL string
+	Scopes      []string
+	// UsernameClaim names users instead of the subject. The claim must be
+	// unique and never change: a renamed user comes back as a new one, and
+	// a reused name takes over the account of another user.
+	UsernameClaim string
+	RoleClaim     string
+	Roles         map[string]core.Role
+	DefaultRole   core.Role
+}
+
+// Flow is kept by the browser between Start and Finish.
+type Flow struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+// RelyingParty logs users in with an OIDC provider using the authorization
+// code flow with PKCE.
+type RelyingParty interface {
+	// Start returns the provider URL to send the user to.
+	Start(ctx context.Context) (string, Flow, error)
+	// Finish exchanges the code for an ID token and maps its claims
+	// to a user. It returns ErrNoRole if no role fits.
+	Finish(ctx context.Context, code string, flow Flow) (core.User, error)
+	// Issuer identifies the provider of the users.
+	Issuer() string
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	log    *slog.Logger
+	cfg    Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      *keySet
+}
+
+// New does not contact the provider, its configuration is fetched
+// on first use so the API starts while the provider is down.
+func New(log *slog.Logger, cfg Config, timeout time.Duration) (*Provider, error) {
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("oidc needs an issuer, a client id and a redirect url")
+	}
+	for value, role := range cfg.Roles {
+		if !role.Valid() {
+			return nil, fmt.Errorf("unknown role %q for %q", role, value)
+		}
+	}
+	if cfg.DefaultRole != "" && !cfg.DefaultRole.Valid() {
+		return nil, fmt.Errorf("unknown default role %q", cfg.DefaultRole)
+	}
+	client := &http.Client{Timeout: timeout}
+	return &Provider{
+		log:    log,
+		cfg:    cfg,
+		client: client,
+		keys:   newKeySet(client),
+	}, nil
+}
+
+func (p *Provider) Issuer() string {
+	return p.cfg.Issuer
+}
+
+func (p *Provider) Start(ctx context.Context) (string, Flow, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", Flow{}, err
+	}
+	var flow Flow
+	for _, s := range []*string{&flow.State, &flow.Nonce, &flow.Verifier} {
+		if *s, err = random(); err != nil {
+			return "", Flow{}, err
+		}
+	}
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {flow.State},
+		"nonce":                 {flow.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + params.Encode(), flow, nil
+}
+
+func (p *Provider) Finish(ctx context.Context, code string, flow Flow) (core.User, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return core.User{}, err
+	}
+	idToken, err := p.exchange(ctx, d, code, flow.Verifier)
+	if err != nil {
+		return core.User{}, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(idToken, claims,
+		func(token *jwt.Token) (any, error) { return p.keys.key(ctx, d.JWKSURI, token) },
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithValidMethods(validMethods),
+	)
+	if err != nil {
+		return core.User{}, fmt.Errorf("bad id token: %w", err)
+	}
+	if nonce, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(nonce), []byte(flow.Nonce)) != 1 {
+		return core.User{}, fmt.Errorf("bad id token: nonce mismatch")
+	}
+
+	name := p.username(claims)
+	if name == "" {
+		return core.User{}, fmt.Errorf("id token has no user name")
+	}
+	role := p.role(claims)
+	if role == "" {
+		return core.User{}, fmt.Errorf("%w %s", ErrNoRole, name)
+	}
+	return core.User{Name: name, Role: role}, nil
+}
+
+// username is the subject, stable and unique within the issuer, unless
+// another claim is configured. There is no fallback to a second claim,
+// so a user is never known under two names.
+func (p *Provider) username(claims jwt.MapClaims) string {
+	claim := p.cfg.UsernameClaim
+	if claim == "" {
+		claim = "sub"
+	}
+	name, _ := claims[claim].(string)
+	return name
+}
+
+func (p *Provider) role(claims jwt.MapClaims) core.Role {
+	var values []string
+	switch v := claims[p.cfg.RoleClaim].(type) {
+	case string:
+		values = []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	role := p.cfg.DefaultRole
+	for _, value := range values {
+		if mapped, ok := p.cfg.Roles[value]; ok && (role == "" || mapped.Allows(role)) {
+			role = mapped
+		}
+	}
+	return role
+}
+
+func (p *Provider) exchange(ctx context.Context, d *discovery, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var reply struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := getJSON(p.client, req, &reply); err != nil {
+		return "", fmt.Errorf("token exchange failed: %w", err)
+	}
+	if reply.IDToken == "" {
+		return "", fmt.Errorf("token exchange returned no id token")
+	}
+	return reply.IDToken, nil
+}
+
+// discover fetches the provider configuration once it is reachable.
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var d discovery
+	if err := getJSON(p.client, req, &d); err != nil {
+		p.log.Error("oidc discovery failed", "issuer", p.cfg.Issuer, "error", err)
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if d.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("provider issuer %q does not match %q", d.Issuer, p.cfg.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("provider configuration lacks endpoints")
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+func getJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+func random() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
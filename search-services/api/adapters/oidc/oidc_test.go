@@ -0,0 +1,213 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"yadro.com/course/api/adapters/oidc"
+	"yadro.com/course/api/core"
+)
+
+var log = slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+// provider is a local OIDC provider signing ID tokens with an ES256 key,
+// claims and kid of the next token are set by tests.
+type provider struct {
+	*httptest.Server
+	key       *ecdsa.PrivateKey
+	kid       string
+	claims    jwt.MapClaims
+	challenge string
+}
+
+func newProvider(t *testing.T) *provider {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p := &provider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "EC", "crv": "P-256", "kid": "k1", "use": "sig",
+			"x": base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y": base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if user, _, _ := r.BasicAuth(); user != "search" ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, p.claims)
+		token.Header["kid"] = p.kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func TestFinish(t *testing.T) {
+	p := newProvider(t)
+	cfg := oidc.Config{
+		Issuer:       p.URL,
+		ClientID:     "search",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/api/oidc/callback",
+		Scopes:       []string{"openid"},
+		RoleClaim:    "groups",
+		Roles:        map[string]core.Role{"comics": core.RoleEditor, "ops": core.RoleAdmin},
+	}
+	rp, err := oidc.New(log, cfg, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, p.URL, rp.Issuer())
+	cfg.UsernameClaim = "preferred_username"
+	named, err := oidc.New(log, cfg, time.Second)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		named  bool
+		kid    string
+		claims jwt.MapClaims
+		nonce  string
+		want   core.User
+		err    error
+		errMsg string
+	}{
+		{
+			name:   "highest mapped role",
+			claims: jwt.MapClaims{"preferred_username": "ann", "groups": []string{"comics", "ops", "sales"}},
+			want:   core.User{Name: "42", Role: core.RoleAdmin},
+		},
+		{
+			name:   "email is not a name",
+			claims: jwt.MapClaims{"email": "ann@example.com", "email_verified": true, "groups": "comics"},
+			want:   core.User{Name: "42", Role: core.RoleEditor},
+		},
+		{
+			name:   "configured claim",
+			named:  true,
+			claims: jwt.MapClaims{"preferred_username": "ann", "groups": "comics"},
+			want:   core.User{Name: "ann", Role: core.RoleEditor},
+		},
+		{
+			name:   "configured claim missing",
+			named:  true,
+			claims: jwt.MapClaims{"email": "ann@example.com", "email_verified": true, "groups": "comics"},
+			errMsg: "no user name",
+		},
+		{
+			name:   "no role",
+			claims: jwt.MapClaims{"preferred_username": "bob", "groups": "sales"},
+			err:    oidc.ErrNoRole,
+		},
+		{name: "nonce mismatch", nonce: "replayed", errMsg: "nonce mismatch"},
+		{name: "wrong aud", claims: jwt.MapClaims{"aud": "other-client"}, errMsg: "audience"},
+		{name: "wrong issuer", claims: jwt.MapClaims{"iss": "https://evil.example.com"}, errMsg: "issuer"},
+		{name: "expired", claims: jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}, errMsg: "expired"},
+		{name: "unknown kid", kid: "k2", errMsg: "unknown key id"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			target, flow, err := rp.Start(context.Background())
+			require.NoError(t, err)
+			u, err := url.Parse(target)
+			require.NoError(t, err)
+			q := u.Query()
+			assert.Equal(t, flow.State, q.Get("state"))
+			assert.Equal(t, flow.Nonce, q.Get("nonce"))
+			assert.Equal(t, "S256", q.Get("code_challenge_method"))
+			assert.NotContains(t, target, flow.Verifier, "the verifier stays with the client")
+			p.challenge = q.Get("code_challenge")
+
+			p.kid = "k1"
+			if tc.kid != "" {
+				p.kid = tc.kid
+			}
+			nonce := flow.Nonce
+			if tc.nonce != "" {
+				nonce = tc.nonce
+			}
+			p.claims = jwt.MapClaims{
+				"iss": p.URL, "aud": "search", "sub": "42", "nonce": nonce,
+				"exp": time.Now().Add(time.Minute).Unix(),
+			}
+			maps.Copy(p.claims, tc.claims)
+
+			finisher := rp
+			if tc.named {
+				finisher = named
+			}
+			user, err := finisher.Finish(context.Background(), "code", flow)
+			switch {
+			case tc.err != nil:
+				assert.ErrorIs(t, err, tc.err)
+			case tc.errMsg != "":
+				assert.ErrorContains(t, err, tc.errMsg)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, user)
+			}
+		})
+	}
+
+	// a wrong verifier fails the code exchange
+	_, flow, err := rp.Start(context.Background())
+	require.NoError(t, err)
+	flow.Verifier = "stolen"
+	_, err = rp.Finish(context.Background(), "code", flow)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestNew(t *testing.T) {
+	cfg := oidc.Config{Issuer: "https://sso.example.com", ClientID: "search", RedirectURL: "http://localhost/cb"}
+	_, err := oidc.New(log, cfg, time.Second)
+	assert.NoError(t, err, "the provider is not contacted")
+
+	bad := cfg
+	bad.Roles = map[string]core.Role{"ops": "root"}
+	_, err = oidc.New(log, bad, time.Second)
+	assert.Error(t, err)
+
+	bad = cfg
+	bad.DefaultRole = "root"
+	_, err = oidc.New(log, bad, time.Second)
+	assert.Error(t, err)
+
+	bad = cfg
+	bad.ClientID = ""
+	_, err = oidc.New(log, bad, time.Second)
+	assert.Error(t, err)
+}
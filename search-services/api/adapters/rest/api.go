@@ -1,13 +1,22 @@
 package rest
 
 import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"yadro.com/course/api/adapters/auth"
+	"yadro.com/course/api/adapters/oidc"
 	"yadro.com/course/api/core"
 )
 
@@ -35,13 +44,108 @@ func NewPingHandler(log *slog.Logger, pingers map[string]core.Pinger) http.Handl
 
 func NewUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := updater.Update(r.Context())
+		opts, err := updateOptions(r)
 		if err != nil {
-			if strings.Contains(err.Error(), "update in progress") {
-				w.WriteHeader(http.StatusAccepted)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job, err := updater.Update(r.Context(), opts)
+		if errors.Is(err, core.ErrBadArguments) {
+			http.Error(w, "invalid refresh range", http.StatusBadRequest)
+			return
+		}
+		writeJob(w, log, job, err)
+	}
+}
+
+func NewReindexHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := updater.Reindex(r.Context())
+		writeJob(w, log, job, err)
+	}
+}
+
+// jobReply tells which job runs, AlreadyRunning is set if no new job
+// was started.
+type jobReply struct {
+	Job            string `json:"job"`
+	AlreadyRunning bool   `json:"already_running"`
+}
+
+// writeJob replies with the ID of a started or already running job.
+func writeJob(w http.ResponseWriter, log *slog.Logger, job string, err error) {
+	running := errors.Is(err, core.ErrAlreadyExists)
+	if err != nil && !running {
+		log.Error("update failed", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := jobReply{Job: job, AlreadyRunning: running}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/db/update/"+job)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error("failed to encode response", "error", err)
+	}
+}
+
+// updateOptions reads update mode and refresh filter from the query,
+// e.g. ?mode=refresh&from=1&to=100 or ?mode=refresh&older_than_days=30.
+func updateOptions(r *http.Request) (core.UpdateOptions, error) {
+	q := r.URL.Query()
+	opts := core.UpdateOptions{Mode: core.UpdateMode(q.Get("mode"))}
+	switch opts.Mode {
+	case "":
+		opts.Mode = core.UpdateModeNew
+	case core.UpdateModeNew, core.UpdateModeFailed:
+	case core.UpdateModeRefresh:
+		for name, v := range map[string]*int{"from": &opts.FromID, "to": &opts.ToID, "older_than_days": &opts.OlderThanDays} {
+			if s := q.Get(name); s != "" {
+				if _, err := fmt.Sscanf(s, "%d", v); err != nil || *v <= 0 {
+					return core.UpdateOptions{}, fmt.Errorf("invalid %s", name)
+				}
+			}
+		}
+		if opts.FromID == 0 && opts.ToID == 0 && opts.OlderThanDays == 0 {
+			return core.UpdateOptions{}, errors.New("refresh needs from, to or older_than_days")
+		}
+	default:
+		return core.UpdateOptions{}, errors.New("invalid mode")
+	}
+	return opts, nil
+}
+
+func NewUpdateProgressHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		progress, err := updater.Progress(r.Context(), r.PathValue("job"))
+		if err != nil {
+			if errors.Is(err, core.ErrNotFound) {
+				http.Error(w, "update job not found", http.StatusNotFound)
 				return
 			}
-			log.Error("update failed", "error", err)
+			log.Error("failed to get update progress", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(progress); err != nil {
+			log.Error("failed to encode response", "error", err)
+		}
+	}
+}
+
+func NewUpdateCancelHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := updater.Cancel(r.Context(), r.PathValue("job")); err != nil {
+			if errors.Is(err, core.ErrNotFound) {
+				http.Error(w, "update job not found", http.StatusNotFound)
+				return
+			}
+			log.Error("failed to cancel update", "error", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -49,6 +153,144 @@ func NewUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	}
 }
 
+func NewDeleteComicHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return comicHandler(log, "delete", updater.DeleteComic)
+}
+
+func NewHideComicHandler(log *slog.Logger, updater core.Updater, hidden bool) http.HandlerFunc {
+	action := "hide"
+	if !hidden {
+		action = "unhide"
+	}
+	return comicHandler(log, action, func(ctx context.Context, id int) error {
+		return updater.HideComic(ctx, id, hidden)
+	})
+}
+
+func NewRefetchComicHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return comicHandler(log, "refetch", updater.RefetchComic)
+}
+
+func comicHandler(log *slog.Logger, action string, op func(context.Context, int) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id < 1 {
+			http.Error(w, "bad comic id", http.StatusBadRequest)
+			return
+		}
+
+		if err := op(r.Context(), id); err != nil {
+			switch {
+			case errors.Is(err, core.ErrNotFound):
+				http.Error(w, "comic not found", http.StatusNotFound)
+			case errors.Is(err, core.ErrBadArguments):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				log.Error("failed to "+action+" comic", "id", id, "error", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// maxAnnotationSize limits the body of an annotation request.
+const maxAnnotationSize = 1 << 20
+
+func NewAnnotationHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id < 1 {
+			http.Error(w, "bad comic id", http.StatusBadRequest)
+			return
+		}
+
+		note, err := updater.Annotation(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, core.ErrNotFound) {
+				http.Error(w, "annotation not found", http.StatusNotFound)
+				return
+			}
+			log.Error("failed to get annotation", "id", id, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(note); err != nil {
+			log.Error("failed to encode response", "error", err)
+		}
+	}
+}
+
+type annotationRequest struct {
+	Transcript string   `json:"transcript"`
+	Keywords   []string `json:"keywords"`
+}
+
+func NewSetAnnotationHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req annotationRequest
+		r.Body = http.MaxBytesReader(w, r.Body, maxAnnotationSize)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad annotation", http.StatusBadRequest)
+			return
+		}
+
+		comicHandler(log, "annotate", func(ctx context.Context, id int) error {
+			return updater.SetAnnotation(ctx, core.Annotation{
+				Comic:      id,
+				Transcript: req.Transcript,
+				Keywords:   req.Keywords,
+			})
+		})(w, r)
+	}
+}
+
+func NewDeleteAnnotationHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return comicHandler(log, "delete annotation of", updater.DeleteAnnotation)
+}
+
+// imageMaxAge is how long clients may use a comic image without
+// revalidating it.
+const imageMaxAge = 24 * time.Hour
+
+func NewComicImageHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id < 1 {
+			http.Error(w, "bad comic id", http.StatusBadRequest)
+			return
+		}
+
+		img, err := updater.Image(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, core.ErrNotFound) {
+				http.Error(w, "image not found", http.StatusNotFound)
+				return
+			}
+			log.Error("failed to get comic image", "id", id, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		etag := `"` + img.Hash + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(imageMaxAge.Seconds())))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", http.DetectContentType(img.Data))
+		w.Header().Set("Content-Length", strconv.Itoa(len(img.Data)))
+		if _, err := w.Write(img.Data); err != nil {
+			log.Error("failed to write image", "id", id, "error", err)
+		}
+	}
+}
+
 func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		stats, err := updater.Stats(r.Context())
@@ -58,11 +300,15 @@ func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater) http.HandlerF
 			return
 		}
 
-		resp := map[string]int{
-			"words_total":    stats.WordsTotal,
-			"words_unique":   stats.WordsUnique,
-			"comics_fetched": stats.ComicsFetched,
-			"comics_total":   stats.ComicsTotal,
+		resp := map[string]any{
+			"words_total":          stats.WordsTotal,
+			"words_unique":         stats.WordsUnique,
+			"comics_fetched":       stats.ComicsFetched,
+			"comics_total":         stats.ComicsTotal,
+			"latest_checked_at":    stats.LatestCheckedAt,
+			"comics_no_title":      stats.NoTitle,
+			"comics_no_alt":        stats.NoAlt,
+			"comics_no_transcript": stats.NoTranscript,
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -72,6 +318,56 @@ func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater) http.HandlerF
 	}
 }
 
+func NewCoverageHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 50
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			_, err := fmt.Sscanf(limitStr, "%d", &limit)
+			if err != nil || limit <= 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		coverage, err := updater.Coverage(r.Context(), limit)
+		if err != nil {
+			log.Error("failed to get text coverage", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(coverage); err != nil {
+			log.Error("failed to encode response", "error", err)
+		}
+	}
+}
+
+func NewUpdateRunsHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 20
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			_, err := fmt.Sscanf(limitStr, "%d", &limit)
+			if err != nil || limit <= 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		runs, err := updater.Runs(r.Context(), limit)
+		if err != nil {
+			log.Error("failed to get update runs", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(runs); err != nil {
+			log.Error("failed to encode response", "error", err)
+		}
+	}
+}
+
 func NewUpdateStatusHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		status, err := updater.Status(r.Context())
@@ -92,6 +388,59 @@ func NewUpdateStatusHandler(log *slog.Logger, updater core.Updater) http.Handler
 	}
 }
 
+// sseHeartbeat keeps idle event streams open behind proxies.
+const sseHeartbeat = 15 * time.Second
+
+// NewUpdateEventsHandler relays update events as Server-Sent Events.
+func NewUpdateEventsHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		events, err := updater.Watch(r.Context())
+		if err != nil {
+			log.Error("failed to watch update events", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			log.Error("response streaming is not supported", "error", err)
+			return
+		}
+
+		heartbeat := time.NewTicker(sseHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					log.Error("failed to encode event", "error", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
 func NewDropHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := updater.Drop(r.Context()); err != nil {
@@ -122,8 +471,16 @@ func NewSearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc
 			}
 		}
 
-		result, err := searcher.Search(r.Context(), phrase, limit)
+		filter := core.SearchFilter{
+			Tag:        r.URL.Query().Get("tag"),
+			Collection: r.URL.Query().Get("collection"),
+		}
+		result, err := searcher.Search(r.Context(), phrase, filter, limit)
 		if err != nil {
+			if errors.Is(err, core.ErrBadArguments) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			log.Error("failed to search comics", "error", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -155,8 +512,16 @@ func NewISearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFun
 			}
 		}
 
-		result, err := searcher.ISearch(r.Context(), phrase, limit)
+		filter := core.SearchFilter{
+			Tag:        r.URL.Query().Get("tag"),
+			Collection: r.URL.Query().Get("collection"),
+		}
+		result, err := searcher.ISearch(r.Context(), phrase, filter, limit)
 		if err != nil {
+			if errors.Is(err, core.ErrBadArguments) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			log.Error("failed to isearch comics", "error", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -169,6 +534,106 @@ func NewISearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFun
 	}
 }
 
+func NewVisuallySimilarHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id < 1 {
+			http.Error(w, "bad comic id", http.StatusBadRequest)
+			return
+		}
+		limit, ok := similarLimit(w, r)
+		if !ok {
+			return
+		}
+
+		comics, err := searcher.Similar(r.Context(), id, limit)
+		if err != nil {
+			if errors.Is(err, core.ErrNotFound) {
+				http.Error(w, "comic image not found", http.StatusNotFound)
+				return
+			}
+			log.Error("failed to find similar comics", "id", id, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeSimilar(log, w, comics)
+	}
+}
+
+// maxUploadSize limits pictures uploaded to find a comic.
+const maxUploadSize = 8 << 20
+
+// NewMatchImageHandler finds comics looking like an uploaded picture sent
+// either as the request body or as the "image" field of a multipart form.
+func NewMatchImageHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := similarLimit(w, r)
+		if !ok {
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+		var data []byte
+		var err error
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			data, err = formFile(r, "image")
+		} else {
+			data, err = io.ReadAll(r.Body)
+		}
+		if err != nil {
+			http.Error(w, "failed to read image: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(data) == 0 {
+			http.Error(w, "image is required", http.StatusBadRequest)
+			return
+		}
+
+		comics, err := searcher.Match(r.Context(), data, limit)
+		if err != nil {
+			if errors.Is(err, core.ErrBadArguments) {
+				http.Error(w, "not a supported image", http.StatusBadRequest)
+				return
+			}
+			log.Error("failed to match image", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeSimilar(log, w, comics)
+	}
+}
+
+func formFile(r *http.Request, field string) ([]byte, error) {
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return nil, err
+	}
+	f, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return io.ReadAll(f)
+}
+
+func similarLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		_, err := fmt.Sscanf(limitStr, "%d", &limit)
+		if err != nil || limit <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return 0, false
+		}
+	}
+	return limit, true
+}
+
+func writeSimilar(log *slog.Logger, w http.ResponseWriter, comics []core.SimilarComic) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]core.SimilarComic{"comics": comics}); err != nil {
+		log.Error("failed to encode response", "error", err)
+	}
+}
+
 func NewLoginHandler(log *slog.Logger, auth auth.Authorizer) http.HandlerFunc {
 	type loginRequest struct {
 		Name     string `json:"name"`
@@ -183,16 +648,460 @@ func NewLoginHandler(log *slog.Logger, auth auth.Authorizer) http.HandlerFunc {
 			return
 		}
 
-		token, err := auth.Login(req.Name, req.Password)
+		tokens, err := auth.Login(r.Context(), req.Name, req.Password)
 		if err != nil {
 			log.Warn("login failed", "user", req.Name, "error", err)
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		w.Header().Set("Content-Type", "text/plain")
-		if _, err := w.Write([]byte(token)); err != nil {
-			log.Error("failed to write response", "error", err)
+		writeJSON(w, log, tokens)
+	}
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// NewRefreshHandler exchanges a refresh token for new access and refresh
+// tokens.
+func NewRefreshHandler(log *slog.Logger, auth auth.Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req refreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		tokens, err := auth.Refresh(r.Context(), req.RefreshToken)
+		if err != nil {
+			log.Warn("token refresh failed", "error", err)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		writeJSON(w, log, tokens)
+	}
+}
+
+// NewLogoutHandler revokes the access token of the request and the refresh
+// token in the body, if any. It runs behind AuthMiddleware.
+func NewLogoutHandler(log *slog.Logger, authorizer auth.Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req refreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
 		}
+
+		if err := authorizer.Logout(r.Context(), claims, req.RefreshToken); err != nil {
+			if errors.Is(err, core.ErrBadArguments) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Error("failed to log out", "user", claims.User, "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		log.Info("logged out", "user", claims.User)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// oidcCookie keeps the OIDC flow in the browser between login and callback.
+const oidcCookie = "oidc_flow"
+
+// NewOIDCLoginHandler sends the user to the OIDC provider.
+func NewOIDCLoginHandler(log *slog.Logger, rp oidc.RelyingParty) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		target, flow, err := rp.Start(r.Context())
+		if err != nil {
+			log.Error("failed to start oidc login", "error", err)
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		data, err := json.Marshal(flow)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     oidcCookie,
+			Value:    base64.RawURLEncoding.EncodeToString(data),
+			Path:     "/api/oidc",
+			MaxAge:   600,
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, target, http.StatusFound)
+	}
+}
+
+// NewOIDCCallbackHandler finishes an OIDC login and redirects to
+// postLogin with the tokens in the URL fragment, which browsers do not
+// send to servers.
+func NewOIDCCallbackHandler(log *slog.Logger, rp oidc.RelyingParty, authorizer auth.Authorizer, postLogin string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if e := query.Get("error"); e != "" {
+			log.Warn("oidc login refused", "error", e, "description", query.Get("error_description"))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var flow oidc.Flow
+		cookie, err := r.Cookie(oidcCookie)
+		if err == nil {
+			var data []byte
+			if data, err = base64.RawURLEncoding.DecodeString(cookie.Value); err == nil {
+				err = json.Unmarshal(data, &flow)
+			}
+		}
+		state := query.Get("state")
+		if err != nil || flow.State == "" || subtle.ConstantTimeCompare([]byte(state), []byte(flow.State)) != 1 {
+			http.Error(w, "bad oidc state", http.StatusBadRequest)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: oidcCookie, Path: "/api/oidc", MaxAge: -1})
+
+		user, err := rp.Finish(r.Context(), query.Get("code"), flow)
+		if err != nil {
+			log.Warn("oidc login failed", "error", err)
+			if errors.Is(err, oidc.ErrNoRole) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		user.Provider = rp.Issuer()
+
+		tokens, err := authorizer.LoginExternal(r.Context(), user)
+		if err != nil {
+			switch {
+			case errors.Is(err, core.ErrAlreadyExists):
+				log.Warn("oidc user clashes with another user", "user", user.Name)
+				http.Error(w, "user already exists", http.StatusConflict)
+			case errors.Is(err, core.ErrBadArguments):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				log.Error("failed to log in oidc user", "user", user.Name, "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+		log.Info("oidc login", "user", user.Name, "role", user.Role)
+
+		fragment := url.Values{
+			"access_token":  {tokens.AccessToken},
+			"refresh_token": {tokens.RefreshToken},
+			"token_type":    {tokens.TokenType},
+			"expires_in":    {strconv.FormatInt(tokens.ExpiresIn, 10)},
+		}
+		http.Redirect(w, r, postLogin+"#"+fragment.Encode(), http.StatusFound)
+	}
+}
+
+// NewJWKSHandler publishes the keys other services verify tokens with.
+func NewJWKSHandler(log *slog.Logger, keys auth.KeyPublisher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		writeJSON(w, log, keys.JWKS())
+	}
+}
+
+type userRequest struct {
+	Name     string    `json:"name"`
+	Password string    `json:"password"`
+	Role     core.Role `json:"role"`
+}
+
+// maxUserSize limits the body of a user request.
+const maxUserSize = 4 << 10
+
+func NewUsersHandler(log *slog.Logger, users core.Users) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := users.Users(r.Context())
+		if err != nil {
+			usersError(w, log, "list users", err)
+			return
+		}
+		writeJSON(w, log, map[string][]core.User{"users": list})
+	}
+}
+
+func NewCreateUserHandler(log *slog.Logger, users core.Users) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req userRequest
+		r.Body = http.MaxBytesReader(w, r.Body, maxUserSize)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad user", http.StatusBadRequest)
+			return
+		}
+		if err := users.CreateUser(r.Context(), core.User{Name: req.Name, Role: req.Role}, req.Password); err != nil {
+			usersError(w, log, "create user", err)
+			return
+		}
+		log.Info("user created", "user", req.Name, "role", req.Role)
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// NewUpdateUserHandler changes the role and/or the password of a user.
+func NewUpdateUserHandler(log *slog.Logger, users core.Users) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req userRequest
+		r.Body = http.MaxBytesReader(w, r.Body, maxUserSize)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad user", http.StatusBadRequest)
+			return
+		}
+		if req.Role == "" && req.Password == "" {
+			http.Error(w, "nothing to update", http.StatusBadRequest)
+			return
+		}
+		name := r.PathValue("name")
+		if err := users.UpdateUser(r.Context(), name, req.Role, req.Password); err != nil {
+			usersError(w, log, "update user", err)
+			return
+		}
+		log.Info("user updated", "user", name, "role", req.Role, "password_changed", req.Password != "")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func NewDeleteUserHandler(log *slog.Logger, users core.Users) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := users.DeleteUser(r.Context(), name); err != nil {
+			usersError(w, log, "delete user", err)
+			return
+		}
+		log.Info("user deleted", "user", name)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func NewAPIKeysHandler(log *slog.Logger, keys core.APIKeys) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := keys.APIKeys(r.Context())
+		if err != nil {
+			usersError(w, log, "list api keys", err)
+			return
+		}
+		writeJSON(w, log, map[string][]core.APIKey{"keys": list})
+	}
+}
+
+// NewCreateAPIKeyHandler issues a key for the requesting user, its secret
+// is only returned here.
+func NewCreateAPIKeyHandler(log *slog.Logger, issuer auth.APIKeyIssuer) http.HandlerFunc {
+	type keyRequest struct {
+		Name      string    `json:"name"`
+		Role      core.Role `json:"role"`
+		Rate      int       `json:"rate"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	type keyReply struct {
+		Key string `json:"key"`
+		core.APIKey
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req keyRequest
+		r.Body = http.MaxBytesReader(w, r.Body, maxUserSize)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad api key", http.StatusBadRequest)
+			return
+		}
+		secret, key, err := issuer.Issue(r.Context(), core.APIKey{
+			Name:      req.Name,
+			Role:      req.Role,
+			Rate:      req.Rate,
+			ExpiresAt: req.ExpiresAt,
+			CreatedBy: claims.User,
+		})
+		if err != nil {
+			usersError(w, log, "create api key", err)
+			return
+		}
+		log.Info("api key created", "key", key.ID, "name", key.Name, "role", key.Role, "by", claims.User)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, log, keyReply{Key: secret, APIKey: key})
+	}
+}
+
+func NewDeleteAPIKeyHandler(log *slog.Logger, keys core.APIKeys) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if err := keys.DeleteAPIKey(r.Context(), id); err != nil {
+			usersError(w, log, "delete api key", err)
+			return
+		}
+		log.Info("api key deleted", "key", id)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func usersError(w http.ResponseWriter, log *slog.Logger, action string, err error) {
+	if errors.Is(err, core.ErrAlreadyExists) {
+		http.Error(w, "user already exists", http.StatusConflict)
+		return
+	}
+	curatorError(w, log, action, err)
+}
+
+func NewTagsHandler(log *slog.Logger, curator core.Curator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tags, err := curator.Tags(r.Context())
+		if err != nil {
+			curatorError(w, log, "list tags", err)
+			return
+		}
+		writeJSON(w, log, map[string][]core.TagCount{"tags": tags})
+	}
+}
+
+func NewComicTagsHandler(log *slog.Logger, curator core.Curator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id < 1 {
+			http.Error(w, "bad comic id", http.StatusBadRequest)
+			return
+		}
+		tags, err := curator.ComicTags(r.Context(), id)
+		if err != nil {
+			curatorError(w, log, "get comic tags", err)
+			return
+		}
+		writeJSON(w, log, map[string][]string{"tags": tags})
+	}
+}
+
+// NewTagHandler tags a comic or, if remove is set, removes the tag.
+func NewTagHandler(log *slog.Logger, curator core.Curator, remove bool) http.HandlerFunc {
+	op, action := curator.AddTag, "tag comic"
+	if remove {
+		op, action = curator.RemoveTag, "untag comic"
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id < 1 {
+			http.Error(w, "bad comic id", http.StatusBadRequest)
+			return
+		}
+		if err := op(r.Context(), id, r.PathValue("tag")); err != nil {
+			curatorError(w, log, action, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func NewCollectionsHandler(log *slog.Logger, curator core.Curator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collections, err := curator.Collections(r.Context())
+		if err != nil {
+			curatorError(w, log, "list collections", err)
+			return
+		}
+		writeJSON(w, log, map[string][]core.Collection{"collections": collections})
+	}
+}
+
+func NewCollectionHandler(log *slog.Logger, curator core.Curator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collection, err := curator.Collection(r.Context(), r.PathValue("name"))
+		if err != nil {
+			curatorError(w, log, "get collection", err)
+			return
+		}
+		writeJSON(w, log, collection)
+	}
+}
+
+type collectionRequest struct {
+	Description string `json:"description"`
+}
+
+// maxCollectionSize limits the body of a collection request.
+const maxCollectionSize = 64 << 10
+
+func NewSaveCollectionHandler(log *slog.Logger, curator core.Curator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req collectionRequest
+		r.Body = http.MaxBytesReader(w, r.Body, maxCollectionSize)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "bad collection", http.StatusBadRequest)
+			return
+		}
+		collection := core.Collection{Name: r.PathValue("name"), Description: req.Description}
+		if err := curator.SaveCollection(r.Context(), collection); err != nil {
+			curatorError(w, log, "save collection", err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func NewDeleteCollectionHandler(log *slog.Logger, curator core.Curator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := curator.DeleteCollection(r.Context(), r.PathValue("name")); err != nil {
+			curatorError(w, log, "delete collection", err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewCollectionComicHandler adds a comic to a collection or, if remove
+// is set, removes it.
+func NewCollectionComicHandler(log *slog.Logger, curator core.Curator, remove bool) http.HandlerFunc {
+	op, action := curator.AddToCollection, "add comic to collection"
+	if remove {
+		op, action = curator.RemoveFromCollection, "remove comic from collection"
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id < 1 {
+			http.Error(w, "bad comic id", http.StatusBadRequest)
+			return
+		}
+		if err := op(r.Context(), r.PathValue("name"), id); err != nil {
+			curatorError(w, log, action, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func curatorError(w http.ResponseWriter, log *slog.Logger, action string, err error) {
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, core.ErrBadArguments):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Error("failed to "+action, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, log *slog.Logger, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("failed to encode response", "error", err)
 	}
 }
@@ -3,17 +3,33 @@ package rest_test
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"log/slog"
+	"maps"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"yadro.com/course/api/adapters/auth"
+	"yadro.com/course/api/adapters/oidc"
 	"yadro.com/course/api/adapters/rest"
 	"yadro.com/course/api/core"
 )
@@ -31,11 +47,59 @@ type MockUpdater struct {
 	mock.Mock
 }
 
-func (m *MockUpdater) Update(ctx context.Context) error {
+func (m *MockUpdater) Update(ctx context.Context, opts core.UpdateOptions) (string, error) {
+	args := m.Called(ctx, opts)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUpdater) Reindex(ctx context.Context) (string, error) {
 	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUpdater) Runs(ctx context.Context, limit int) (core.UpdateRuns, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).(core.UpdateRuns), args.Error(1)
+}
+
+func (m *MockUpdater) Annotation(ctx context.Context, id int) (core.Annotation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(core.Annotation), args.Error(1)
+}
+
+func (m *MockUpdater) SetAnnotation(ctx context.Context, note core.Annotation) error {
+	args := m.Called(ctx, note)
 	return args.Error(0)
 }
 
+func (m *MockUpdater) DeleteAnnotation(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUpdater) Coverage(ctx context.Context, limit int) (core.Coverage, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).(core.Coverage), args.Error(1)
+}
+
+func (m *MockUpdater) Progress(ctx context.Context, job string) (core.UpdateProgress, error) {
+	args := m.Called(ctx, job)
+	return args.Get(0).(core.UpdateProgress), args.Error(1)
+}
+
+func (m *MockUpdater) Cancel(ctx context.Context, job string) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockUpdater) Watch(ctx context.Context) (<-chan core.UpdateEvent, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan core.UpdateEvent), args.Error(1)
+}
+
 func (m *MockUpdater) Stats(ctx context.Context) (core.UpdateStats, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(core.UpdateStats), args.Error(1)
@@ -51,30 +115,216 @@ func (m *MockUpdater) Drop(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockUpdater) DeleteComic(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUpdater) HideComic(ctx context.Context, id int, hidden bool) error {
+	args := m.Called(ctx, id, hidden)
+	return args.Error(0)
+}
+
+func (m *MockUpdater) RefetchComic(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUpdater) Image(ctx context.Context, id int) (core.ComicImage, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(core.ComicImage), args.Error(1)
+}
+
 type MockSearcher struct {
 	mock.Mock
 }
 
-func (m *MockSearcher) Search(ctx context.Context, phrase string, limit int) (core.SearchResult, error) {
-	args := m.Called(ctx, phrase, limit)
+func (m *MockSearcher) Search(ctx context.Context, phrase string, filter core.SearchFilter, limit int) (core.SearchResult, error) {
+	args := m.Called(ctx, phrase, filter, limit)
 	return args.Get(0).(core.SearchResult), args.Error(1)
 }
 
-func (m *MockSearcher) ISearch(ctx context.Context, phrase string, limit int) (core.SearchResult, error) {
-	args := m.Called(ctx, phrase, limit)
+func (m *MockSearcher) ISearch(ctx context.Context, phrase string, filter core.SearchFilter, limit int) (core.SearchResult, error) {
+	args := m.Called(ctx, phrase, filter, limit)
 	return args.Get(0).(core.SearchResult), args.Error(1)
 }
 
+func (m *MockSearcher) Similar(ctx context.Context, id int64, limit int) ([]core.SimilarComic, error) {
+	args := m.Called(ctx, id, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]core.SimilarComic), args.Error(1)
+}
+
+func (m *MockSearcher) Match(ctx context.Context, image []byte, limit int) ([]core.SimilarComic, error) {
+	args := m.Called(ctx, image, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]core.SimilarComic), args.Error(1)
+}
+
+type MockCurator struct {
+	mock.Mock
+}
+
+func (m *MockCurator) Tags(ctx context.Context) ([]core.TagCount, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]core.TagCount), args.Error(1)
+}
+
+func (m *MockCurator) ComicTags(ctx context.Context, id int64) ([]string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockCurator) AddTag(ctx context.Context, id int64, tag string) error {
+	args := m.Called(ctx, id, tag)
+	return args.Error(0)
+}
+
+func (m *MockCurator) RemoveTag(ctx context.Context, id int64, tag string) error {
+	args := m.Called(ctx, id, tag)
+	return args.Error(0)
+}
+
+func (m *MockCurator) Collections(ctx context.Context) ([]core.Collection, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]core.Collection), args.Error(1)
+}
+
+func (m *MockCurator) Collection(ctx context.Context, name string) (core.Collection, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(core.Collection), args.Error(1)
+}
+
+func (m *MockCurator) SaveCollection(ctx context.Context, c core.Collection) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *MockCurator) DeleteCollection(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockCurator) AddToCollection(ctx context.Context, name string, id int64) error {
+	args := m.Called(ctx, name, id)
+	return args.Error(0)
+}
+
+func (m *MockCurator) RemoveFromCollection(ctx context.Context, name string, id int64) error {
+	args := m.Called(ctx, name, id)
+	return args.Error(0)
+}
+
+type MockUsers struct {
+	mock.Mock
+}
+
+func (m *MockUsers) Users(ctx context.Context) ([]core.User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]core.User), args.Error(1)
+}
+
+func (m *MockUsers) Verify(ctx context.Context, name, password string) (core.User, error) {
+	args := m.Called(ctx, name, password)
+	return args.Get(0).(core.User), args.Error(1)
+}
+
+func (m *MockUsers) CreateUser(ctx context.Context, user core.User, password string) error {
+	return m.Called(ctx, user, password).Error(0)
+}
+
+func (m *MockUsers) UpdateUser(ctx context.Context, name string, role core.Role, password string) error {
+	return m.Called(ctx, name, role, password).Error(0)
+}
+
+func (m *MockUsers) DeleteUser(ctx context.Context, name string) error {
+	return m.Called(ctx, name).Error(0)
+}
+
+func (m *MockUsers) ProvisionUser(ctx context.Context, user core.User) error {
+	return m.Called(ctx, user).Error(0)
+}
+
+type MockSessions struct {
+	mock.Mock
+}
+
+func (m *MockSessions) SaveRefresh(ctx context.Context, hash, user string, expires time.Time) error {
+	return m.Called(ctx, hash, user, expires).Error(0)
+}
+
+func (m *MockSessions) TakeRefresh(ctx context.Context, hash string) (core.User, error) {
+	args := m.Called(ctx, hash)
+	return args.Get(0).(core.User), args.Error(1)
+}
+
+func (m *MockSessions) DeleteRefresh(ctx context.Context, hash, user string) error {
+	return m.Called(ctx, hash, user).Error(0)
+}
+
+func (m *MockSessions) Revoke(ctx context.Context, jti string, expires time.Time) error {
+	return m.Called(ctx, jti, expires).Error(0)
+}
+
+func (m *MockSessions) Revoked(ctx context.Context, jti string) (bool, error) {
+	args := m.Called(ctx, jti)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockAPIKeys struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeys) APIKeys(ctx context.Context) ([]core.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]core.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeys) APIKey(ctx context.Context, id string) (core.APIKey, string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(core.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeys) CreateAPIKey(ctx context.Context, key core.APIKey, hash string) error {
+	return m.Called(ctx, key, hash).Error(0)
+}
+
+func (m *MockAPIKeys) TouchAPIKey(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockAPIKeys) DeleteAPIKey(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
 type MockAuthorizer struct {
 	mock.Mock
 }
 
-func (m *MockAuthorizer) Login(name, password string) (string, error) {
-	args := m.Called(name, password)
-	return args.String(0), args.Error(1)
+func (m *MockAuthorizer) Login(ctx context.Context, name, password string) (auth.TokenPair, error) {
+	args := m.Called(ctx, name, password)
+	return args.Get(0).(auth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthorizer) LoginExternal(ctx context.Context, user core.User) (auth.TokenPair, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).(auth.TokenPair), args.Error(1)
 }
 
-func (m *MockAuthorizer) Validate(tokenString string) (*auth.CustomClaims, error) {
+func (m *MockAuthorizer) Refresh(ctx context.Context, refreshToken string) (auth.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(auth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthorizer) Logout(ctx context.Context, claims *auth.CustomClaims, refreshToken string) error {
+	return m.Called(ctx, claims, refreshToken).Error(0)
+}
+
+func (m *MockAuthorizer) Validate(ctx context.Context, tokenString string) (*auth.CustomClaims, error) {
 	args := m.Called(tokenString)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -111,7 +361,7 @@ func TestPingHandler(t *testing.T) {
 
 func TestUpdateHandler(t *testing.T) {
 	mockUpdater := new(MockUpdater)
-	mockUpdater.On("Update", mock.Anything).Return(nil).Once()
+	mockUpdater.On("Update", mock.Anything, core.UpdateOptions{Mode: core.UpdateModeNew}).Return("j1", nil).Once()
 
 	handler := rest.NewUpdateHandler(log, mockUpdater)
 
@@ -120,13 +370,15 @@ func TestUpdateHandler(t *testing.T) {
 
 	handler.ServeHTTP(rr, req)
 
-	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Equal(t, "/api/db/update/j1", rr.Header().Get("Location"))
+	assert.JSONEq(t, `{"job": "j1", "already_running": false}`, rr.Body.String())
 	mockUpdater.AssertExpectations(t)
 }
 
 func TestUpdateHandler_InProgress(t *testing.T) {
 	mockUpdater := new(MockUpdater)
-	mockUpdater.On("Update", mock.Anything).Return(errors.New("update in progress")).Once()
+	mockUpdater.On("Update", mock.Anything, core.UpdateOptions{Mode: core.UpdateModeNew}).Return("j0", core.ErrAlreadyExists).Once()
 
 	handler := rest.NewUpdateHandler(log, mockUpdater)
 
@@ -136,12 +388,13 @@ func TestUpdateHandler_InProgress(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.JSONEq(t, `{"job": "j0", "already_running": true}`, rr.Body.String())
 	mockUpdater.AssertExpectations(t)
 }
 
 func TestUpdateHandler_Error(t *testing.T) {
 	mockUpdater := new(MockUpdater)
-	mockUpdater.On("Update", mock.Anything).Return(errors.New("internal logic error")).Once()
+	mockUpdater.On("Update", mock.Anything, core.UpdateOptions{Mode: core.UpdateModeNew}).Return("", errors.New("internal logic error")).Once()
 
 	handler := rest.NewUpdateHandler(log, mockUpdater)
 
@@ -154,6 +407,241 @@ func TestUpdateHandler_Error(t *testing.T) {
 	mockUpdater.AssertExpectations(t)
 }
 
+func TestUpdateHandler_Mode(t *testing.T) {
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("Update", mock.Anything, core.UpdateOptions{Mode: core.UpdateModeFailed}).Return("j2", nil).Once()
+	mockUpdater.On("Update", mock.Anything, core.UpdateOptions{Mode: core.UpdateModeRefresh, FromID: 10, ToID: 20}).
+		Return("j3", nil).Once()
+	mockUpdater.On("Update", mock.Anything, core.UpdateOptions{Mode: core.UpdateModeRefresh, OlderThanDays: 30}).
+		Return("j4", nil).Once()
+
+	handler := rest.NewUpdateHandler(log, mockUpdater)
+
+	for url, code := range map[string]int{
+		"/update?mode=failed":                     http.StatusAccepted,
+		"/update?mode=refresh&from=10&to=20":      http.StatusAccepted,
+		"/update?mode=refresh&older_than_days=30": http.StatusAccepted,
+		"/update?mode=all":                        http.StatusBadRequest,
+		"/update?mode=refresh":                    http.StatusBadRequest,
+		"/update?mode=refresh&older_than_days=-1": http.StatusBadRequest,
+		"/update?mode=refresh&from=abc":           http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, url, nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, code, rr.Code, url)
+	}
+
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestReindexHandler(t *testing.T) {
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("Reindex", mock.Anything).Return("j5", nil).Once()
+	mockUpdater.On("Reindex", mock.Anything).Return("j1", core.ErrAlreadyExists).Once()
+	mockUpdater.On("Reindex", mock.Anything).Return("", errors.New("unavailable")).Once()
+
+	handler := rest.NewReindexHandler(log, mockUpdater)
+
+	for _, want := range []struct {
+		code    int
+		job     string
+		running bool
+	}{
+		{http.StatusAccepted, "j5", false},
+		{http.StatusAccepted, "j1", true},
+		{http.StatusInternalServerError, "", false},
+	} {
+		req, _ := http.NewRequest(http.MethodPost, "/api/db/reindex", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, want.code, rr.Code)
+		if want.job != "" {
+			var resp struct {
+				Job            string `json:"job"`
+				AlreadyRunning bool   `json:"already_running"`
+			}
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			assert.Equal(t, want.job, resp.Job)
+			assert.Equal(t, want.running, resp.AlreadyRunning)
+		}
+	}
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestUpdateRunsHandler(t *testing.T) {
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("Runs", mock.Anything, 5).Return(core.UpdateRuns{
+		Runs:   []core.UpdateRun{{Job: "j1", Trigger: "schedule", Mode: "new", State: core.JobDone, Fetched: 3, Failed: 1}},
+		Failed: []core.FailedComic{{Comic: 42, Job: "j1", Error: "timeout", Attempts: 1}},
+	}, nil).Once()
+
+	handler := rest.NewUpdateRunsHandler(log, mockUpdater)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/db/runs?limit=5", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var runs core.UpdateRuns
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &runs))
+	assert.Equal(t, "schedule", runs.Runs[0].Trigger)
+	assert.Equal(t, 42, runs.Failed[0].Comic)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/db/runs?limit=-1", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestUpdateProgressHandler(t *testing.T) {
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("Progress", mock.Anything, "j1").Return(core.UpdateProgress{
+		Job: "j1", State: core.JobRunning, Total: 10, Fetched: 4, Failed: 1, InFlight: 2, ETASeconds: 5,
+	}, nil).Once()
+	mockUpdater.On("Progress", mock.Anything, "nope").Return(core.UpdateProgress{}, core.ErrNotFound).Once()
+
+	handler := rest.NewUpdateProgressHandler(log, mockUpdater)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/db/update/j1", nil)
+	req.SetPathValue("job", "j1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var progress core.UpdateProgress
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &progress))
+	assert.Equal(t, core.JobRunning, progress.State)
+	assert.Equal(t, 4, progress.Fetched)
+	assert.Equal(t, int64(5), progress.ETASeconds)
+	assert.Nil(t, progress.FinishedAt)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/db/update/nope", nil)
+	req.SetPathValue("job", "nope")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestUpdateCancelHandler(t *testing.T) {
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("Cancel", mock.Anything, "j1").Return(nil).Once()
+	mockUpdater.On("Cancel", mock.Anything, "nope").Return(core.ErrNotFound).Once()
+
+	handler := rest.NewUpdateCancelHandler(log, mockUpdater)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api/db/update/j1", nil)
+	req.SetPathValue("job", "j1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/db/update/nope", nil)
+	req.SetPathValue("job", "nope")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestComicHandlers(t *testing.T) {
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("DeleteComic", mock.Anything, 1).Return(nil).Once()
+	mockUpdater.On("DeleteComic", mock.Anything, 2).Return(core.ErrNotFound).Once()
+	mockUpdater.On("HideComic", mock.Anything, 3, true).Return(nil).Once()
+	mockUpdater.On("HideComic", mock.Anything, 3, false).Return(nil).Once()
+	mockUpdater.On("RefetchComic", mock.Anything, 4).Return(errors.New("xkcd is down")).Once()
+
+	tests := []struct {
+		handler http.HandlerFunc
+		id      string
+		code    int
+	}{
+		{rest.NewDeleteComicHandler(log, mockUpdater), "1", http.StatusOK},
+		{rest.NewDeleteComicHandler(log, mockUpdater), "2", http.StatusNotFound},
+		{rest.NewDeleteComicHandler(log, mockUpdater), "abc", http.StatusBadRequest},
+		{rest.NewHideComicHandler(log, mockUpdater, true), "3", http.StatusOK},
+		{rest.NewHideComicHandler(log, mockUpdater, false), "3", http.StatusOK},
+		{rest.NewRefetchComicHandler(log, mockUpdater), "4", http.StatusInternalServerError},
+		{rest.NewRefetchComicHandler(log, mockUpdater), "0", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, "/api/comics/"+tt.id, nil)
+		req.SetPathValue("id", tt.id)
+		rr := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rr, req)
+		assert.Equal(t, tt.code, rr.Code, "id %s", tt.id)
+	}
+
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestComicImageHandler(t *testing.T) {
+	img := core.ComicImage{Data: []byte("\x89PNG\r\n\x1a\n"), Hash: "abc", Width: 1, Height: 1}
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("Image", mock.Anything, 1).Return(img, nil).Twice()
+	mockUpdater.On("Image", mock.Anything, 2).Return(core.ComicImage{}, core.ErrNotFound).Once()
+
+	handler := rest.NewComicImageHandler(log, mockUpdater)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/comics/1/image", nil)
+	req.SetPathValue("id", "1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `"abc"`, rr.Header().Get("ETag"))
+	assert.Contains(t, rr.Header().Get("Cache-Control"), "max-age=")
+	assert.Equal(t, img.Data, rr.Body.Bytes())
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/comics/1/image", nil)
+	req.SetPathValue("id", "1")
+	req.Header.Set("If-None-Match", `"abc"`)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.Bytes())
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/comics/2/image", nil)
+	req.SetPathValue("id", "2")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestUpdateEventsHandler(t *testing.T) {
+	events := make(chan core.UpdateEvent, 2)
+	events <- core.UpdateEvent{Type: "saved", Comic: 7, Progress: core.UpdateProgress{Job: "j1", Total: 2, Fetched: 1}}
+	events <- core.UpdateEvent{Type: "finished", Progress: core.UpdateProgress{Job: "j1", State: core.JobDone}}
+	close(events)
+
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("Watch", mock.Anything).Return((<-chan core.UpdateEvent)(events), nil).Once()
+
+	handler := rest.NewUpdateEventsHandler(log, mockUpdater)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/db/update/events", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+
+	body := rr.Body.String()
+	assert.Contains(t, body, "event: saved\ndata: {\"type\":\"saved\",\"comic\":7,")
+	assert.Contains(t, body, "event: finished\n")
+	assert.True(t, rr.Flushed)
+	mockUpdater.AssertExpectations(t)
+}
+
 func TestSearchHandler(t *testing.T) {
 	mockSearcher := new(MockSearcher)
 	expectedResult := core.SearchResult{
@@ -162,7 +650,7 @@ func TestSearchHandler(t *testing.T) {
 			{ID: 1, URL: "url"},
 		},
 	}
-	mockSearcher.On("Search", mock.Anything, "test", 10).Return(expectedResult, nil).Once()
+	mockSearcher.On("Search", mock.Anything, "test", core.SearchFilter{}, 10).Return(expectedResult, nil).Once()
 
 	handler := rest.NewSearchHandler(log, mockSearcher)
 
@@ -195,9 +683,32 @@ func TestSearchHandler_BagParams(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 }
 
+func TestSearchHandler_Filter(t *testing.T) {
+	mockSearcher := new(MockSearcher)
+	filter := core.SearchFilter{Tag: "security", Collection: "onboarding"}
+	mockSearcher.On("ISearch", mock.Anything, "test", filter, 10).Return(core.SearchResult{}, nil).Once()
+	mockSearcher.On("ISearch", mock.Anything, "test", core.SearchFilter{Tag: "?"}, 10).
+		Return(core.SearchResult{}, core.ErrBadArguments).Once()
+
+	handler := rest.NewISearchHandler(log, mockSearcher)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/isearch?phrase=test&tag=security&collection=onboarding", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/isearch?phrase=test&tag=%3F", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockSearcher.AssertExpectations(t)
+}
+
 func TestLoginHandler(t *testing.T) {
 	mockAuth := new(MockAuthorizer)
-	mockAuth.On("Login", "admin", "password").Return("token", nil).Once()
+	tokens := auth.TokenPair{AccessToken: "token", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 120}
+	mockAuth.On("Login", mock.Anything, "admin", "password").Return(tokens, nil).Once()
 
 	handler := rest.NewLoginHandler(log, mockAuth)
 
@@ -208,13 +719,15 @@ func TestLoginHandler(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
-	assert.Equal(t, "token", rr.Body.String())
+	var got auth.TokenPair
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, tokens, got)
 	mockAuth.AssertExpectations(t)
 }
 
 func TestLoginHandler_Fail(t *testing.T) {
 	mockAuth := new(MockAuthorizer)
-	mockAuth.On("Login", "admin", "wrong").Return("", errors.New("auth failed")).Once()
+	mockAuth.On("Login", mock.Anything, "admin", "wrong").Return(auth.TokenPair{}, errors.New("auth failed")).Once()
 
 	handler := rest.NewLoginHandler(log, mockAuth)
 
@@ -227,3 +740,651 @@ func TestLoginHandler_Fail(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, rr.Code)
 	mockAuth.AssertExpectations(t)
 }
+
+func TestRefreshHandler(t *testing.T) {
+	mockAuth := new(MockAuthorizer)
+	tokens := auth.TokenPair{AccessToken: "token2", RefreshToken: "refresh2", TokenType: "Bearer", ExpiresIn: 120}
+	mockAuth.On("Refresh", mock.Anything, "refresh").Return(tokens, nil).Once()
+	mockAuth.On("Refresh", mock.Anything, "used").Return(auth.TokenPair{}, errors.New("invalid refresh token")).Once()
+
+	handler := rest.NewRefreshHandler(log, mockAuth)
+	refresh := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/token/refresh", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := refresh(`{"refresh_token": "refresh"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var got auth.TokenPair
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, tokens, got)
+
+	assert.Equal(t, http.StatusUnauthorized, refresh(`{"refresh_token": "used"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, refresh(`{}`).Code)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestLogoutHandler(t *testing.T) {
+	mockAuth := new(MockAuthorizer)
+	claims := &auth.CustomClaims{User: "ed", Role: core.RoleEditor}
+	mockAuth.On("Validate", "token").Return(claims, nil)
+	mockAuth.On("Logout", mock.Anything, claims, "refresh").Return(nil).Once()
+	mockAuth.On("Logout", mock.Anything, claims, "").Return(nil).Once()
+
+	handler := rest.NewMiddleware(log, 10, auth.NewTokenAuthenticator(mockAuth)).AuthMiddleware(core.RoleViewer, rest.NewLogoutHandler(log, mockAuth))
+	logout := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/logout", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, logout(`{"refresh_token": "refresh"}`))
+	assert.Equal(t, http.StatusOK, logout(``))
+
+	rr := httptest.NewRecorder()
+	rest.NewLogoutHandler(log, mockAuth).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/logout", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "claims are set by the middleware")
+	mockAuth.AssertExpectations(t)
+}
+
+func TestVisuallySimilarHandler(t *testing.T) {
+	similar := []core.SimilarComic{{ID: 2, URL: "u2", Distance: 3}}
+	mockSearcher := new(MockSearcher)
+	mockSearcher.On("Similar", mock.Anything, int64(1), 5).Return(similar, nil).Once()
+	mockSearcher.On("Similar", mock.Anything, int64(9), 10).Return(nil, core.ErrNotFound).Once()
+
+	handler := rest.NewVisuallySimilarHandler(log, mockSearcher)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/comics/1/visually-similar?limit=5", nil)
+	req.SetPathValue("id", "1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp map[string][]core.SimilarComic
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, similar, resp["comics"])
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/comics/9/visually-similar", nil)
+	req.SetPathValue("id", "9")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/comics/x/visually-similar", nil)
+	req.SetPathValue("id", "x")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockSearcher.AssertExpectations(t)
+}
+
+func TestMatchImageHandler(t *testing.T) {
+	picture := []byte("picture")
+	matched := []core.SimilarComic{{ID: 7, URL: "u7"}}
+	mockSearcher := new(MockSearcher)
+	mockSearcher.On("Match", mock.Anything, picture, 10).Return(matched, nil).Twice()
+	mockSearcher.On("Match", mock.Anything, []byte("text"), 10).Return(nil, core.ErrBadArguments).Once()
+
+	handler := rest.NewMatchImageHandler(log, mockSearcher)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/comics/match", bytes.NewReader(picture))
+	req.Header.Set("Content-Type", "image/png")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":7`)
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, err := mw.CreateFormFile("image", "screenshot.png")
+	assert.NoError(t, err)
+	_, _ = part.Write(picture)
+	assert.NoError(t, mw.Close())
+	req, _ = http.NewRequest(http.MethodPost, "/api/comics/match", &form)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/comics/match", bytes.NewReader([]byte("text")))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/comics/match", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockSearcher.AssertExpectations(t)
+}
+
+func TestCoverageHandler(t *testing.T) {
+	coverage := core.Coverage{
+		Comics: []core.ComicCoverage{{Comic: 3, Title: "t", TitleTokens: 1}},
+		Total:  4,
+	}
+	mockUpdater := new(MockUpdater)
+	mockUpdater.On("Coverage", mock.Anything, 50).Return(coverage, nil).Once()
+	mockUpdater.On("Coverage", mock.Anything, 2).Return(core.Coverage{}, errors.New("db down")).Once()
+
+	handler := rest.NewCoverageHandler(log, mockUpdater)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/db/coverage", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var got core.Coverage
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, coverage, got)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/db/coverage?limit=2", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/db/coverage?limit=-1", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestAnnotationHandlers(t *testing.T) {
+	mockUpdater := new(MockUpdater)
+	note := core.Annotation{Comic: 5, Transcript: "cat", Keywords: []string{"pet"}}
+	mockUpdater.On("Annotation", mock.Anything, 5).Return(note, nil).Once()
+	mockUpdater.On("Annotation", mock.Anything, 6).Return(core.Annotation{}, core.ErrNotFound).Once()
+	mockUpdater.On("SetAnnotation", mock.Anything, note).Return(nil).Once()
+	mockUpdater.On("SetAnnotation", mock.Anything, core.Annotation{Comic: 6}).Return(core.ErrBadArguments).Once()
+	mockUpdater.On("DeleteAnnotation", mock.Anything, 6).Return(core.ErrNotFound).Once()
+
+	get := rest.NewAnnotationHandler(log, mockUpdater)
+	req := httptest.NewRequest(http.MethodGet, "/api/comics/5/annotation", nil)
+	req.SetPathValue("id", "5")
+	rr := httptest.NewRecorder()
+	get.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var got core.Annotation
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, note, got)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/comics/6/annotation", nil)
+	req.SetPathValue("id", "6")
+	rr = httptest.NewRecorder()
+	get.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	set := rest.NewSetAnnotationHandler(log, mockUpdater)
+	req = httptest.NewRequest(http.MethodPut, "/api/comics/5/annotation",
+		strings.NewReader(`{"transcript": "cat", "keywords": ["pet"]}`))
+	req.SetPathValue("id", "5")
+	rr = httptest.NewRecorder()
+	set.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/comics/6/annotation", strings.NewReader(`{}`))
+	req.SetPathValue("id", "6")
+	rr = httptest.NewRecorder()
+	set.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/comics/6/annotation", strings.NewReader(`not json`))
+	req.SetPathValue("id", "6")
+	rr = httptest.NewRecorder()
+	set.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	del := rest.NewDeleteAnnotationHandler(log, mockUpdater)
+	req = httptest.NewRequest(http.MethodDelete, "/api/comics/6/annotation", nil)
+	req.SetPathValue("id", "6")
+	rr = httptest.NewRecorder()
+	del.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockUpdater.AssertExpectations(t)
+}
+
+func TestTagHandlers(t *testing.T) {
+	mockCurator := new(MockCurator)
+	mockCurator.On("Tags", mock.Anything).Return([]core.TagCount{{Tag: "security", Comics: 2}}, nil).Once()
+	mockCurator.On("ComicTags", mock.Anything, int64(3)).Return([]string{"security"}, nil).Once()
+	mockCurator.On("AddTag", mock.Anything, int64(3), "security").Return(nil).Once()
+	mockCurator.On("AddTag", mock.Anything, int64(9), "security").Return(core.ErrNotFound).Once()
+	mockCurator.On("RemoveTag", mock.Anything, int64(3), "security").Return(nil).Once()
+
+	rr := httptest.NewRecorder()
+	rest.NewTagsHandler(log, mockCurator).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var tags map[string][]core.TagCount
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&tags))
+	assert.Equal(t, []core.TagCount{{Tag: "security", Comics: 2}}, tags["tags"])
+
+	req := httptest.NewRequest(http.MethodGet, "/api/comics/3/tags", nil)
+	req.SetPathValue("id", "3")
+	rr = httptest.NewRecorder()
+	rest.NewComicTagsHandler(log, mockCurator).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	tag := func(handler http.HandlerFunc, id string) int {
+		req := httptest.NewRequest(http.MethodPut, "/api/comics/"+id+"/tags/security", nil)
+		req.SetPathValue("id", id)
+		req.SetPathValue("tag", "security")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, tag(rest.NewTagHandler(log, mockCurator, false), "3"))
+	assert.Equal(t, http.StatusNotFound, tag(rest.NewTagHandler(log, mockCurator, false), "9"))
+	assert.Equal(t, http.StatusBadRequest, tag(rest.NewTagHandler(log, mockCurator, false), "x"))
+	assert.Equal(t, http.StatusOK, tag(rest.NewTagHandler(log, mockCurator, true), "3"))
+
+	mockCurator.AssertExpectations(t)
+}
+
+func TestCollectionHandlers(t *testing.T) {
+	mockCurator := new(MockCurator)
+	collection := core.Collection{Name: "security talks", Description: "d", Comics: []int64{3}}
+	mockCurator.On("Collections", mock.Anything).Return([]core.Collection{collection}, nil).Once()
+	mockCurator.On("Collection", mock.Anything, "security talks").Return(collection, nil).Once()
+	mockCurator.On("Collection", mock.Anything, "nope").Return(core.Collection{}, core.ErrNotFound).Once()
+	mockCurator.On("SaveCollection", mock.Anything, core.Collection{Name: "security talks", Description: "d"}).Return(nil).Once()
+	mockCurator.On("SaveCollection", mock.Anything, core.Collection{Name: " "}).Return(core.ErrBadArguments).Once()
+	mockCurator.On("DeleteCollection", mock.Anything, "security talks").Return(nil).Once()
+	mockCurator.On("AddToCollection", mock.Anything, "security talks", int64(3)).Return(nil).Once()
+	mockCurator.On("RemoveFromCollection", mock.Anything, "security talks", int64(3)).Return(core.ErrNotFound).Once()
+
+	rr := httptest.NewRecorder()
+	rest.NewCollectionsHandler(log, mockCurator).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/collections", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var list map[string][]core.Collection
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+	assert.Equal(t, []core.Collection{collection}, list["collections"])
+
+	get := func(name string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/collections/x", nil)
+		req.SetPathValue("name", name)
+		rr := httptest.NewRecorder()
+		rest.NewCollectionHandler(log, mockCurator).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, get("security talks"))
+	assert.Equal(t, http.StatusNotFound, get("nope"))
+
+	save := func(name, body string) int {
+		req := httptest.NewRequest(http.MethodPut, "/api/collections/x", strings.NewReader(body))
+		req.SetPathValue("name", name)
+		rr := httptest.NewRecorder()
+		rest.NewSaveCollectionHandler(log, mockCurator).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, save("security talks", `{"description": "d"}`))
+	assert.Equal(t, http.StatusBadRequest, save(" ", ``))
+	assert.Equal(t, http.StatusBadRequest, save("security talks", `{`))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/collections/x", nil)
+	req.SetPathValue("name", "security talks")
+	rr = httptest.NewRecorder()
+	rest.NewDeleteCollectionHandler(log, mockCurator).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	member := func(handler http.HandlerFunc) int {
+		req := httptest.NewRequest(http.MethodPut, "/api/collections/x/comics/3", nil)
+		req.SetPathValue("name", "security talks")
+		req.SetPathValue("id", "3")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, member(rest.NewCollectionComicHandler(log, mockCurator, false)))
+	assert.Equal(t, http.StatusNotFound, member(rest.NewCollectionComicHandler(log, mockCurator, true)))
+
+	mockCurator.AssertExpectations(t)
+}
+
+func TestUserHandlers(t *testing.T) {
+	mockUsers := new(MockUsers)
+	users := []core.User{{Name: "admin", Role: core.RoleAdmin}, {Name: "ed", Role: core.RoleEditor}}
+	mockUsers.On("Users", mock.Anything).Return(users, nil).Once()
+	mockUsers.On("CreateUser", mock.Anything, core.User{Name: "ed", Role: core.RoleEditor}, "password").Return(nil).Once()
+	mockUsers.On("CreateUser", mock.Anything, core.User{Name: "ed", Role: core.RoleEditor}, "password").Return(core.ErrAlreadyExists).Once()
+	mockUsers.On("CreateUser", mock.Anything, core.User{Name: "ed", Role: "root"}, "password").Return(core.ErrBadArguments).Once()
+	mockUsers.On("UpdateUser", mock.Anything, "ed", core.RoleViewer, "").Return(nil).Once()
+	mockUsers.On("UpdateUser", mock.Anything, "nope", core.Role(""), "password").Return(core.ErrNotFound).Once()
+	mockUsers.On("DeleteUser", mock.Anything, "admin").Return(core.ErrBadArguments).Once()
+
+	rr := httptest.NewRecorder()
+	rest.NewUsersHandler(log, mockUsers).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var list map[string][]core.User
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+	assert.Equal(t, users, list["users"])
+	assert.NotContains(t, rr.Body.String(), "password")
+
+	create := func(body string) int {
+		rr := httptest.NewRecorder()
+		rest.NewCreateUserHandler(log, mockUsers).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(body)))
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusCreated, create(`{"name": "ed", "password": "password", "role": "editor"}`))
+	assert.Equal(t, http.StatusConflict, create(`{"name": "ed", "password": "password", "role": "editor"}`))
+	assert.Equal(t, http.StatusBadRequest, create(`{"name": "ed", "password": "password", "role": "root"}`))
+	assert.Equal(t, http.StatusBadRequest, create(`{`))
+
+	update := func(name, body string) int {
+		req := httptest.NewRequest(http.MethodPatch, "/api/users/x", strings.NewReader(body))
+		req.SetPathValue("name", name)
+		rr := httptest.NewRecorder()
+		rest.NewUpdateUserHandler(log, mockUsers).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, update("ed", `{"role": "viewer"}`))
+	assert.Equal(t, http.StatusNotFound, update("nope", `{"password": "password"}`))
+	assert.Equal(t, http.StatusBadRequest, update("ed", `{}`))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/users/x", nil)
+	req.SetPathValue("name", "admin")
+	rr = httptest.NewRecorder()
+	rest.NewDeleteUserHandler(log, mockUsers).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockUsers.AssertExpectations(t)
+}
+
+func writeKey(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func TestJWKSHandler(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockUsers.On("Verify", mock.Anything, "ed", "password").Return(core.User{Name: "ed", Role: core.RoleEditor}, nil)
+	mockSessions := new(MockSessions)
+	mockSessions.On("SaveRefresh", mock.Anything, mock.Anything, "ed", mock.Anything).Return(nil)
+	mockSessions.On("Revoked", mock.Anything, mock.Anything).Return(false, nil)
+
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(oldKey)
+	require.NoError(t, err)
+	oldFile := writeKey(t, "old.pem", "PRIVATE KEY", der)
+	oldKeys, err := auth.LoadKeys(auth.KeyConfig{KeyID: "old", KeyFile: oldFile})
+	require.NoError(t, err)
+	oldTokens, err := auth.New(mockUsers, mockSessions, oldKeys, time.Minute, time.Hour).Login(context.Background(), "ed", "password")
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newFile := writeKey(t, "new.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+	der, err = x509.MarshalPKIXPublicKey(oldKey.Public())
+	require.NoError(t, err)
+	oldPublic := writeKey(t, "old.pub", "PUBLIC KEY", der)
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte(strings.Repeat("s", 32)), 0o600))
+
+	keys, err := auth.LoadKeys(auth.KeyConfig{
+		KeyID:      "new",
+		KeyFile:    newFile,
+		VerifyKeys: map[string]string{"old": oldPublic, "hmac": secretFile},
+	})
+	require.NoError(t, err)
+	assert.False(t, keys.Generated())
+	authorizer := auth.New(mockUsers, mockSessions, keys, time.Minute, time.Hour)
+
+	// tokens of the rotated out key stay valid
+	claims, err := authorizer.Validate(context.Background(), oldTokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "ed", claims.User)
+	assert.Equal(t, core.RoleEditor, claims.Role)
+
+	tokens, err := authorizer.Login(context.Background(), "ed", "password")
+	require.NoError(t, err)
+	_, err = authorizer.Validate(context.Background(), tokens.AccessToken)
+	assert.NoError(t, err)
+	_, err = auth.New(mockUsers, mockSessions, oldKeys, time.Minute, time.Hour).Validate(context.Background(), tokens.AccessToken)
+	assert.Error(t, err, "old keys do not know the new one")
+
+	rr := httptest.NewRecorder()
+	rest.NewJWKSHandler(log, authorizer).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var set auth.JWKS
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&set))
+	require.Len(t, set.Keys, 2, "secrets are not published")
+	assert.Equal(t, "new", set.Keys[0].KeyID)
+	assert.Equal(t, "RS256", set.Keys[0].Algorithm)
+	assert.Equal(t, "RSA", set.Keys[0].KeyType)
+	assert.Equal(t, "old", set.Keys[1].KeyID)
+	assert.Equal(t, "EdDSA", set.Keys[1].Algorithm)
+	assert.Equal(t, "Ed25519", set.Keys[1].Curve)
+
+	_, err = auth.LoadKeys(auth.KeyConfig{Secret: "short"})
+	assert.Error(t, err)
+}
+
+func TestAuthSessions(t *testing.T) {
+	keys, err := auth.LoadKeys(auth.KeyConfig{})
+	require.NoError(t, err)
+	assert.True(t, keys.Generated())
+
+	ed := core.User{Name: "ed", Role: core.RoleEditor}
+	mockUsers := new(MockUsers)
+	mockUsers.On("Verify", mock.Anything, "ed", "password").Return(ed, nil).Once()
+	mockSessions := new(MockSessions)
+	var refreshHash string
+	mockSessions.On("SaveRefresh", mock.Anything, mock.Anything, "ed", mock.Anything).
+		Run(func(args mock.Arguments) { refreshHash = args.String(1) }).Return(nil)
+	authorizer := auth.New(mockUsers, mockSessions, keys, time.Minute, time.Hour)
+
+	tokens, err := authorizer.Login(context.Background(), "ed", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, int64(60), tokens.ExpiresIn)
+	assert.NotContains(t, refreshHash, tokens.RefreshToken, "refresh tokens are stored hashed")
+
+	// refresh tokens are used up, a role change shows in the next token
+	mockSessions.On("TakeRefresh", mock.Anything, refreshHash).Return(core.User{Name: "ed", Role: core.RoleViewer}, nil).Once()
+	mockSessions.On("TakeRefresh", mock.Anything, refreshHash).Return(core.User{}, core.ErrNotFound).Once()
+	refreshed, err := authorizer.Refresh(context.Background(), tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+	_, err = authorizer.Refresh(context.Background(), tokens.RefreshToken)
+	assert.Error(t, err)
+
+	mockSessions.On("Revoked", mock.Anything, mock.Anything).Return(false, nil).Once()
+	claims, err := authorizer.Validate(context.Background(), refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, core.RoleViewer, claims.Role)
+
+	mockSessions.On("Revoke", mock.Anything, claims.ID, claims.ExpiresAt.Time).Return(nil).Once()
+	mockSessions.On("DeleteRefresh", mock.Anything, mock.Anything, "ed").Return(nil).Once()
+	require.NoError(t, authorizer.Logout(context.Background(), claims, refreshed.RefreshToken))
+
+	mockSessions.On("Revoked", mock.Anything, claims.ID).Return(true, nil).Once()
+	_, err = authorizer.Validate(context.Background(), refreshed.AccessToken)
+	assert.Error(t, err, "revoked tokens are denied")
+
+	mockUsers.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestAPIKeyHandlers(t *testing.T) {
+	mockKeys := new(MockAPIKeys)
+	keys := []core.APIKey{{ID: "ab", Name: "bot", Role: core.RoleEditor, CreatedBy: "admin"}}
+	mockKeys.On("APIKeys", mock.Anything).Return(keys, nil).Once()
+	mockKeys.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(k core.APIKey) bool {
+		return k.Name == "bot" && k.Role == core.RoleEditor && k.Rate == 5 && k.CreatedBy == "admin"
+	}), mock.Anything).Return(nil).Once()
+	mockKeys.On("DeleteAPIKey", mock.Anything, "ab").Return(nil).Once()
+	mockKeys.On("DeleteAPIKey", mock.Anything, "nope").Return(core.ErrNotFound).Once()
+
+	rr := httptest.NewRecorder()
+	rest.NewAPIKeysHandler(log, mockKeys).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/keys", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "expires_at", "keys without expiry omit it")
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/keys", strings.NewReader(body))
+		req = req.WithContext(auth.NewContext(req.Context(), &auth.CustomClaims{User: "admin", Role: core.RoleAdmin}))
+		rr := httptest.NewRecorder()
+		rest.NewCreateAPIKeyHandler(log, auth.NewAPIKeyAuth(mockKeys)).ServeHTTP(rr, req)
+		return rr
+	}
+	rr = create(`{"name": "bot", "role": "editor", "rate": 5}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var created struct {
+		Key string `json:"key"`
+		ID  string `json:"id"`
+	}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.True(t, strings.HasPrefix(created.Key, created.ID+"."))
+	assert.Equal(t, http.StatusBadRequest, create(`{"name": "bot", "role": "root"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, create(`{"name": " ", "role": "viewer"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, create(`{"name": "bot", "role": "viewer", "expires_at": "2000-01-01T00:00:00Z"}`).Code)
+
+	del := func(id string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/api/keys/x", nil)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		rest.NewDeleteAPIKeyHandler(log, mockKeys).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, del("ab"))
+	assert.Equal(t, http.StatusNotFound, del("nope"))
+
+	mockKeys.AssertExpectations(t)
+}
+
+// mockOIDC is a local OIDC provider issuing ID tokens with the claims set,
+// it checks the PKCE verifier against the challenge of the last login.
+type mockOIDC struct {
+	*httptest.Server
+	key       ed25519.PrivateKey
+	claims    map[string]any
+	challenge string
+	nonce     string
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p := &mockOIDC{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "OKP", "crv": "Ed25519", "kid": "k1", "alg": "EdDSA",
+			"x": base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if r.PostFormValue("code") != "code" || base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   p.URL,
+			"aud":   "search",
+			"sub":   "42",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": p.nonce,
+		}
+		maps.Copy(claims, p.claims)
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func TestOIDCLogin(t *testing.T) {
+	provider := newMockOIDC(t)
+	rp, err := oidc.New(log, oidc.Config{
+		Issuer:      provider.URL,
+		ClientID:    "search",
+		RedirectURL: "http://localhost/api/oidc/callback",
+		Scopes:      []string{"openid"},
+		RoleClaim:   "groups",
+		Roles:       map[string]core.Role{"comics": core.RoleEditor, "ops": core.RoleAdmin},
+	}, time.Second)
+	require.NoError(t, err)
+	mockAuthorizer := new(MockAuthorizer)
+
+	// login runs the flow against the provider, the browser keeps the cookie
+	login := func(tamper func(q url.Values)) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		rest.NewOIDCLoginHandler(log, rp).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/oidc/login", nil))
+		require.Equal(t, http.StatusFound, rr.Code)
+		target, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, provider.URL+"/authorize", target.Scheme+"://"+target.Host+target.Path)
+		assert.Equal(t, "S256", target.Query().Get("code_challenge_method"))
+		provider.challenge = target.Query().Get("code_challenge")
+		provider.nonce = target.Query().Get("nonce")
+
+		q := url.Values{"code": {"code"}, "state": {target.Query().Get("state")}}
+		if tamper != nil {
+			tamper(q)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/oidc/callback?"+q.Encode(), nil)
+		for _, c := range rr.Result().Cookies() {
+			req.AddCookie(c)
+		}
+		rr = httptest.NewRecorder()
+		rest.NewOIDCCallbackHandler(log, rp, mockAuthorizer, "/login").ServeHTTP(rr, req)
+		return rr
+	}
+
+	mockAuthorizer.On("LoginExternal", mock.Anything,
+		core.User{Name: "42", Role: core.RoleAdmin, Provider: provider.URL}).
+		Return(auth.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 60}, nil).Once()
+	provider.claims = map[string]any{"preferred_username": "ann", "groups": []string{"comics", "ops"}}
+	rr := login(nil)
+	require.Equal(t, http.StatusFound, rr.Code)
+	target, err := url.Parse(rr.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/login", target.Path)
+	fragment, err := url.ParseQuery(target.Fragment)
+	require.NoError(t, err)
+	assert.Equal(t, "access", fragment.Get("access_token"))
+	assert.Equal(t, "refresh", fragment.Get("refresh_token"))
+
+	// users without a mapped group are refused
+	provider.claims = map[string]any{"preferred_username": "bob", "groups": "sales"}
+	assert.Equal(t, http.StatusForbidden, login(nil).Code)
+
+	provider.claims = map[string]any{"preferred_username": "ann", "groups": "ops"}
+	assert.Equal(t, http.StatusBadRequest, login(func(q url.Values) { q.Set("state", "forged") }).Code)
+	assert.Equal(t, http.StatusUnauthorized, login(func(q url.Values) { q.Set("code", "stolen") }).Code)
+	provider.claims["nonce"] = "replayed"
+	assert.Equal(t, http.StatusUnauthorized, login(nil).Code)
+	delete(provider.claims, "nonce")
+	assert.Equal(t, http.StatusUnauthorized, login(func(q url.Values) { q.Set("error", "access_denied") }).Code)
+
+	// a password user of the same name is not taken over
+	mockAuthorizer.On("LoginExternal", mock.Anything, mock.Anything).Return(auth.TokenPair{}, core.ErrAlreadyExists).Once()
+	assert.Equal(t, http.StatusConflict, login(nil).Code)
+
+	mockAuthorizer.AssertExpectations(t)
+}
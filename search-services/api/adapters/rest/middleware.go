@@ -1,27 +1,42 @@
 package rest
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 
 	"golang.org/x/time/rate"
 	"yadro.com/course/api/adapters/auth"
+	"yadro.com/course/api/core"
 )
 
 type Middleware struct {
-	log  *slog.Logger
-	auth auth.Authorizer
+	log            *slog.Logger
+	authenticators []auth.Authenticator
+	// keyRate is the requests per second of API keys without own rate
+	keyRate int
+
+	mu          sync.Mutex
+	keyLimiters map[string]*rate.Limiter
 }
 
-func NewMiddleware(log *slog.Logger, auth auth.Authorizer) *Middleware {
+// NewMiddleware makes a middleware asking authenticators in turn
+// to authenticate requests.
+func NewMiddleware(log *slog.Logger, keyRate int, authenticators ...auth.Authenticator) *Middleware {
 	return &Middleware{
-		log:  log,
-		auth: auth,
+		log:            log,
+		authenticators: authenticators,
+		keyRate:        keyRate,
+		keyLimiters:    make(map[string]*rate.Limiter),
 	}
 }
 
-func (m *Middleware) AuthMiddleware(next http.Handler) http.Handler {
+// AuthMiddleware lets through requests with valid credentials of a user
+// or an API key having the role or a higher one. API keys are rate limited.
+func (m *Middleware) AuthMiddleware(role core.Role, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -30,28 +45,64 @@ func (m *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			if len(parts) != 2 || parts[0] != "Token" {
-				m.log.Warn("invalid authorization header format", "header", authHeader)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
+		scheme, credentials, ok := strings.Cut(authHeader, " ")
+		if !ok || credentials == "" || strings.Contains(credentials, " ") {
+			m.log.Warn("invalid authorization header format", "scheme", scheme)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
 		}
 
-		tokenString := parts[1]
-		claims, err := m.auth.Validate(tokenString)
+		claims, err := m.authenticate(r.Context(), scheme, credentials)
 		if err != nil {
-			m.log.Warn("invalid token", "error", err)
+			m.log.Warn("invalid credentials", "scheme", scheme, "error", err)
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		m.log.Debug("request authorized", "user", claims.User)
-		next.ServeHTTP(w, r)
+		if claims.APIKey != "" && !m.keyLimiter(claims).Allow() {
+			m.log.Warn("api key rate limit reached", "key", claims.APIKey)
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+
+		if !claims.Role.Allows(role) {
+			m.log.Warn("insufficient role", "user", claims.User, "role", claims.Role, "required", role)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		m.log.Debug("request authorized", "user", claims.User, "role", claims.Role)
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), claims)))
 	})
 }
 
+func (m *Middleware) authenticate(ctx context.Context, scheme, credentials string) (*auth.CustomClaims, error) {
+	for _, a := range m.authenticators {
+		claims, err := a.Authenticate(ctx, scheme, credentials)
+		if errors.Is(err, auth.ErrUnsupportedScheme) {
+			continue
+		}
+		return claims, err
+	}
+	return nil, auth.ErrUnsupportedScheme
+}
+
+// keyLimiter returns the limiter of an API key, made on first use.
+func (m *Middleware) keyLimiter(claims *auth.CustomClaims) *rate.Limiter {
+	rps := claims.Rate
+	if rps <= 0 {
+		rps = m.keyRate
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	limiter, ok := m.keyLimiters[claims.APIKey]
+	if !ok || limiter.Burst() != rps {
+		limiter = rate.NewLimiter(rate.Limit(rps), rps)
+		m.keyLimiters[claims.APIKey] = limiter
+	}
+	return limiter
+}
+
 func (m *Middleware) ConcurrencyLimitMiddleware(limit int, next http.Handler) http.Handler {
 	sem := make(chan struct{}, limit)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1,6 +1,7 @@
 package rest_test
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -12,18 +13,33 @@ import (
 	"github.com/stretchr/testify/mock"
 	"yadro.com/course/api/adapters/auth"
 	"yadro.com/course/api/adapters/rest"
+	"yadro.com/course/api/core"
 )
 
 type MockAuthMiddlewareHelper struct {
 	mock.Mock
 }
 
-func (m *MockAuthMiddlewareHelper) Login(name, password string) (string, error) {
-	args := m.Called(name, password)
-	return args.String(0), args.Error(1)
+func (m *MockAuthMiddlewareHelper) Login(ctx context.Context, name, password string) (auth.TokenPair, error) {
+	args := m.Called(ctx, name, password)
+	return args.Get(0).(auth.TokenPair), args.Error(1)
 }
 
-func (m *MockAuthMiddlewareHelper) Validate(token string) (*auth.CustomClaims, error) {
+func (m *MockAuthMiddlewareHelper) LoginExternal(ctx context.Context, user core.User) (auth.TokenPair, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).(auth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthMiddlewareHelper) Refresh(ctx context.Context, refreshToken string) (auth.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(auth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthMiddlewareHelper) Logout(ctx context.Context, claims *auth.CustomClaims, refreshToken string) error {
+	return m.Called(ctx, claims, refreshToken).Error(0)
+}
+
+func (m *MockAuthMiddlewareHelper) Validate(ctx context.Context, token string) (*auth.CustomClaims, error) {
 	args := m.Called(token)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -33,13 +49,13 @@ func (m *MockAuthMiddlewareHelper) Validate(token string) (*auth.CustomClaims, e
 
 func TestAuthMiddleware(t *testing.T) {
 	mockAuth := new(MockAuthMiddlewareHelper)
-	mw := rest.NewMiddleware(log, mockAuth)
+	mw := rest.NewMiddleware(log, 10, auth.NewTokenAuthenticator(mockAuth))
 
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	handler := mw.AuthMiddleware(nextHandler)
+	handler := mw.AuthMiddleware(core.RoleViewer, nextHandler)
 
 	req, _ := http.NewRequest("GET", "/", nil)
 	rr := httptest.NewRecorder()
@@ -59,7 +75,7 @@ func TestAuthMiddleware(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 	assert.Equal(t, http.StatusUnauthorized, rr.Code)
 
-	mockAuth.On("Validate", "good").Return(&auth.CustomClaims{User: "user"}, nil).Once()
+	mockAuth.On("Validate", "good").Return(&auth.CustomClaims{User: "user", Role: core.RoleViewer}, nil).Once()
 	req, _ = http.NewRequest("GET", "/", nil)
 	req.Header.Set("Authorization", "Bearer good")
 	rr = httptest.NewRecorder()
@@ -67,8 +83,86 @@ func TestAuthMiddleware(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
 
+func TestAuthMiddleware_Roles(t *testing.T) {
+	mockAuth := new(MockAuthMiddlewareHelper)
+	mockAuth.On("Validate", "viewer").Return(&auth.CustomClaims{User: "v", Role: core.RoleViewer}, nil)
+	mockAuth.On("Validate", "editor").Return(&auth.CustomClaims{User: "e", Role: core.RoleEditor}, nil)
+	mockAuth.On("Validate", "admin").Return(&auth.CustomClaims{User: "a", Role: core.RoleAdmin}, nil)
+	mockAuth.On("Validate", "none").Return(&auth.CustomClaims{User: "n"}, nil)
+	mw := rest.NewMiddleware(log, 10, auth.NewTokenAuthenticator(mockAuth))
+
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		required core.Role
+		token    string
+		code     int
+	}{
+		{core.RoleViewer, "viewer", http.StatusOK},
+		{core.RoleViewer, "admin", http.StatusOK},
+		{core.RoleViewer, "none", http.StatusForbidden},
+		{core.RoleEditor, "viewer", http.StatusForbidden},
+		{core.RoleEditor, "editor", http.StatusOK},
+		{core.RoleAdmin, "editor", http.StatusForbidden},
+		{core.RoleAdmin, "admin", http.StatusOK},
+	}
+	for _, tt := range tests {
+		handler := mw.AuthMiddleware(tt.required, nextHandler)
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Token "+tt.token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, tt.code, rr.Code, "%s token on %s route", tt.token, tt.required)
+	}
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	mockKeys := new(MockAPIKeys)
+	hashes := make(map[string]string)
+	mockKeys.On("CreateAPIKey", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { hashes[args.Get(1).(core.APIKey).ID] = args.String(2) }).Return(nil)
+	apiKeys := auth.NewAPIKeyAuth(mockKeys)
+
+	secret, key, err := apiKeys.Issue(context.Background(), core.APIKey{Name: "bot", Role: core.RoleEditor, Rate: 2})
+	assert.NoError(t, err)
+	expiredSecret, expired, err := apiKeys.Issue(context.Background(), core.APIKey{Name: "old", Role: core.RoleAdmin})
+	assert.NoError(t, err)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.NotContains(t, secret, hashes[key.ID], "secrets are stored hashed")
+	mockKeys.On("APIKey", mock.Anything, key.ID).Return(key, hashes[key.ID], nil)
+	mockKeys.On("APIKey", mock.Anything, expired.ID).Return(expired, hashes[expired.ID], nil)
+	mockKeys.On("TouchAPIKey", mock.Anything, key.ID).Return(nil)
+
+	mockAuth := new(MockAuthMiddlewareHelper)
+	mw := rest.NewMiddleware(log, 10, auth.NewTokenAuthenticator(mockAuth), apiKeys)
+	var user string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := auth.FromContext(r.Context())
+		user = claims.User
+		w.WriteHeader(http.StatusOK)
+	})
+
+	call := func(role core.Role, header string) int {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", header)
+		rr := httptest.NewRecorder()
+		mw.AuthMiddleware(role, next).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, call(core.RoleEditor, "ApiKey "+secret))
+	assert.Equal(t, "apikey:bot", user)
+	assert.Equal(t, http.StatusForbidden, call(core.RoleAdmin, "ApiKey "+secret))
+	assert.Equal(t, http.StatusTooManyRequests, call(core.RoleEditor, "ApiKey "+secret), "the key has its own rate of 2")
+	assert.Equal(t, http.StatusUnauthorized, call(core.RoleViewer, "ApiKey "+key.ID+".wrong"))
+	assert.Equal(t, http.StatusUnauthorized, call(core.RoleViewer, "ApiKey "+expiredSecret))
+	assert.Equal(t, http.StatusUnauthorized, call(core.RoleViewer, "Basic YWRtaW46cGFzc3dvcmQ="))
+	mockAuth.AssertNotCalled(t, "Validate", mock.Anything)
+}
+
 func TestConcurrencyMiddleware(t *testing.T) {
-	mw := rest.NewMiddleware(log, nil)
+	mw := rest.NewMiddleware(log, 0)
 	limit := 1
 
 	blocker := make(chan struct{})
@@ -106,7 +200,7 @@ func TestConcurrencyMiddleware(t *testing.T) {
 }
 
 func TestRateLimitMiddleware(t *testing.T) {
-	mw := rest.NewMiddleware(log, nil)
+	mw := rest.NewMiddleware(log, 0)
 	rps := 1
 
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -6,7 +6,9 @@ import (
 	"log/slog"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"yadro.com/course/api/core"
 	searchpb "yadro.com/course/proto/search"
@@ -28,15 +30,17 @@ func NewClient(address string, log *slog.Logger) (*Client, error) {
 	}, nil
 }
 
-func (c *Client) Search(ctx context.Context, phrase string, limit int) (core.SearchResult, error) {
+func (c *Client) Search(ctx context.Context, phrase string, filter core.SearchFilter, limit int) (core.SearchResult, error) {
 	req := &searchpb.SearchRequest{
-		Phrase: phrase,
-		Limit:  int32(limit),
+		Phrase:     phrase,
+		Limit:      int32(limit),
+		Tag:        filter.Tag,
+		Collection: filter.Collection,
 	}
 	resp, err := c.client.Search(ctx, req)
 	if err != nil {
 		c.log.Error("gRPC Search call failed", "error", err)
-		return core.SearchResult{}, err
+		return core.SearchResult{}, serviceError(err)
 	}
 
 	var comics []core.Comic
@@ -52,15 +56,17 @@ func (c *Client) Search(ctx context.Context, phrase string, limit int) (core.Sea
 	}, nil
 }
 
-func (c *Client) ISearch(ctx context.Context, phrase string, limit int) (core.SearchResult, error) {
+func (c *Client) ISearch(ctx context.Context, phrase string, filter core.SearchFilter, limit int) (core.SearchResult, error) {
 	req := &searchpb.SearchRequest{
-		Phrase: phrase,
-		Limit:  int32(limit),
+		Phrase:     phrase,
+		Limit:      int32(limit),
+		Tag:        filter.Tag,
+		Collection: filter.Collection,
 	}
 	resp, err := c.client.ISearch(ctx, req)
 	if err != nil {
 		c.log.Error("gRPC ISearch call failed", "error", err)
-		return core.SearchResult{}, err
+		return core.SearchResult{}, serviceError(err)
 	}
 
 	var comics []core.Comic
@@ -80,3 +86,124 @@ func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.client.Ping(ctx, &emptypb.Empty{})
 	return err
 }
+
+func (c *Client) Similar(ctx context.Context, id int64, limit int) ([]core.SimilarComic, error) {
+	resp, err := c.client.Similar(ctx, &searchpb.SimilarRequest{Id: id, Limit: int32(limit)})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return similarComics(resp), nil
+}
+
+func (c *Client) Match(ctx context.Context, image []byte, limit int) ([]core.SimilarComic, error) {
+	resp, err := c.client.Match(ctx, &searchpb.MatchRequest{Image: image, Limit: int32(limit)})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return similarComics(resp), nil
+}
+
+func similarComics(resp *searchpb.SimilarResponse) []core.SimilarComic {
+	comics := make([]core.SimilarComic, 0, len(resp.Comics))
+	for _, c := range resp.Comics {
+		comics = append(comics, core.SimilarComic{
+			ID:       c.Id,
+			URL:      c.Url,
+			Distance: int(c.Distance),
+		})
+	}
+	return comics
+}
+
+func (c *Client) Tags(ctx context.Context) ([]core.TagCount, error) {
+	resp, err := c.client.Tags(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	tags := make([]core.TagCount, 0, len(resp.Tags))
+	for _, t := range resp.Tags {
+		tags = append(tags, core.TagCount{Tag: t.Tag, Comics: int(t.Comics)})
+	}
+	return tags, nil
+}
+
+func (c *Client) ComicTags(ctx context.Context, id int64) ([]string, error) {
+	resp, err := c.client.ComicTags(ctx, &searchpb.ComicRequest{Id: id})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return resp.Tags, nil
+}
+
+func (c *Client) AddTag(ctx context.Context, id int64, tag string) error {
+	_, err := c.client.AddTag(ctx, &searchpb.TagRequest{Id: id, Tag: tag})
+	return serviceError(err)
+}
+
+func (c *Client) RemoveTag(ctx context.Context, id int64, tag string) error {
+	_, err := c.client.RemoveTag(ctx, &searchpb.TagRequest{Id: id, Tag: tag})
+	return serviceError(err)
+}
+
+func (c *Client) Collections(ctx context.Context) ([]core.Collection, error) {
+	resp, err := c.client.Collections(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	collections := make([]core.Collection, 0, len(resp.Collections))
+	for _, col := range resp.Collections {
+		collections = append(collections, collection(col))
+	}
+	return collections, nil
+}
+
+func (c *Client) Collection(ctx context.Context, name string) (core.Collection, error) {
+	resp, err := c.client.GetCollection(ctx, &searchpb.CollectionRequest{Name: name})
+	if err != nil {
+		return core.Collection{}, serviceError(err)
+	}
+	return collection(resp), nil
+}
+
+func collection(c *searchpb.Collection) core.Collection {
+	comics := c.Comics
+	if comics == nil {
+		comics = []int64{}
+	}
+	return core.Collection{
+		Name:        c.Name,
+		Description: c.Description,
+		Comics:      comics,
+		CreatedAt:   c.CreatedAt.AsTime(),
+	}
+}
+
+func (c *Client) SaveCollection(ctx context.Context, col core.Collection) error {
+	_, err := c.client.SaveCollection(ctx, &searchpb.Collection{Name: col.Name, Description: col.Description})
+	return serviceError(err)
+}
+
+func (c *Client) DeleteCollection(ctx context.Context, name string) error {
+	_, err := c.client.DeleteCollection(ctx, &searchpb.CollectionRequest{Name: name})
+	return serviceError(err)
+}
+
+func (c *Client) AddToCollection(ctx context.Context, name string, id int64) error {
+	_, err := c.client.AddToCollection(ctx, &searchpb.CollectionComicRequest{Name: name, Id: id})
+	return serviceError(err)
+}
+
+func (c *Client) RemoveFromCollection(ctx context.Context, name string, id int64) error {
+	_, err := c.client.RemoveFromCollection(ctx, &searchpb.CollectionComicRequest{Name: name, Id: id})
+	return serviceError(err)
+}
+
+func serviceError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return core.ErrNotFound
+	case codes.InvalidArgument:
+		return core.ErrBadArguments
+	}
+	return err
+}
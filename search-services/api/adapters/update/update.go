@@ -2,11 +2,18 @@ package update
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"yadro.com/course/api/core"
 	updatepb "yadro.com/course/proto/update"
@@ -18,7 +25,11 @@ type Client struct {
 }
 
 func NewClient(address string, log *slog.Logger) (*Client, error) {
-	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// comic images come in a single message, up to the 16 MB fetched from XKCD
+	conn, err := grpc.NewClient(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(20<<20)),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -56,19 +67,279 @@ func (c Client) Stats(ctx context.Context) (core.UpdateStats, error) {
 	}
 
 	return core.UpdateStats{
-		WordsTotal:    int(resp.WordsTotal),
-		WordsUnique:   int(resp.WordsUnique),
-		ComicsFetched: int(resp.ComicsFetched),
-		ComicsTotal:   int(resp.ComicsTotal),
+		WordsTotal:      int(resp.WordsTotal),
+		WordsUnique:     int(resp.WordsUnique),
+		ComicsFetched:   int(resp.ComicsFetched),
+		ComicsTotal:     int(resp.ComicsTotal),
+		LatestCheckedAt: resp.GetLatestCheckedAt().AsTime(),
+		NoTitle:         int(resp.ComicsNoTitle),
+		NoAlt:           int(resp.ComicsNoAlt),
+		NoTranscript:    int(resp.ComicsNoTranscript),
 	}, nil
 }
 
-func (c Client) Update(ctx context.Context) error {
-	_, err := c.client.Update(ctx, &emptypb.Empty{})
+func (c Client) Update(ctx context.Context, opts core.UpdateOptions) (string, error) {
+	req := &updatepb.UpdateRequest{Mode: updatepb.UpdateMode_UPDATE_MODE_NEW}
+	switch opts.Mode {
+	case core.UpdateModeFailed:
+		req.Mode = updatepb.UpdateMode_UPDATE_MODE_FAILED
+	case core.UpdateModeRefresh:
+		req.Mode = updatepb.UpdateMode_UPDATE_MODE_REFRESH
+		req.FromId = int64(opts.FromID)
+		req.ToId = int64(opts.ToID)
+		if opts.OlderThanDays > 0 {
+			req.OlderThan = durationpb.New(time.Duration(opts.OlderThanDays) * 24 * time.Hour)
+		}
+	}
+
+	resp, err := c.client.Update(ctx, req)
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return "", core.ErrBadArguments
+		}
+		return "", err
+	}
+	if resp.AlreadyRunning {
+		return resp.JobId, core.ErrAlreadyExists
+	}
+	return resp.JobId, nil
+}
+
+func (c Client) Reindex(ctx context.Context) (string, error) {
+	resp, err := c.client.Reindex(ctx, &emptypb.Empty{})
+	if err != nil {
+		return "", err
+	}
+	if resp.AlreadyRunning {
+		return resp.JobId, core.ErrAlreadyExists
+	}
+	return resp.JobId, nil
+}
+
+func (c Client) Progress(ctx context.Context, job string) (core.UpdateProgress, error) {
+	resp, err := c.client.Progress(ctx, &updatepb.JobRequest{JobId: job})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return core.UpdateProgress{}, core.ErrNotFound
+		}
+		return core.UpdateProgress{}, err
+	}
+
+	return progress(resp), nil
+}
+
+func (c Client) Cancel(ctx context.Context, job string) error {
+	_, err := c.client.Cancel(ctx, &updatepb.JobRequest{JobId: job})
+	if status.Code(err) == codes.NotFound {
+		return core.ErrNotFound
+	}
 	return err
 }
 
+func (c Client) Watch(ctx context.Context) (<-chan core.UpdateEvent, error) {
+	stream, err := c.client.WatchUpdate(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan core.UpdateEvent)
+	go func() {
+		defer close(events)
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) && status.Code(err) != codes.Canceled {
+					c.log.Error("update events stream broken", "error", err)
+				}
+				return
+			}
+
+			event := core.UpdateEvent{
+				Type:     strings.ToLower(strings.TrimPrefix(resp.Type.String(), "EVENT_TYPE_")),
+				Comic:    int(resp.ComicId),
+				Error:    resp.Error,
+				Progress: progress(resp.Progress),
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func progress(resp *updatepb.ProgressReply) core.UpdateProgress {
+	p := core.UpdateProgress{
+		Job:        resp.GetJobId(),
+		State:      jobState(resp.GetState()),
+		Total:      int(resp.GetTotal()),
+		Fetched:    int(resp.GetFetched()),
+		Failed:     int(resp.GetFailed()),
+		InFlight:   int(resp.GetInFlight()),
+		ETASeconds: int64(resp.GetEta().AsDuration().Seconds()),
+		Error:      resp.GetError(),
+	}
+	if resp.GetStartedAt() != nil {
+		p.StartedAt = resp.GetStartedAt().AsTime()
+	}
+	if resp.GetFinishedAt() != nil {
+		finishedAt := resp.GetFinishedAt().AsTime()
+		p.FinishedAt = &finishedAt
+	}
+	return p
+}
+
+func jobState(state updatepb.JobState) core.JobState {
+	switch state {
+	case updatepb.JobState_JOB_STATE_RUNNING:
+		return core.JobRunning
+	case updatepb.JobState_JOB_STATE_DONE:
+		return core.JobDone
+	case updatepb.JobState_JOB_STATE_FAILED:
+		return core.JobFailed
+	case updatepb.JobState_JOB_STATE_CANCELED:
+		return core.JobCanceled
+	default:
+		return core.JobUnknown
+	}
+}
+
+func (c Client) Runs(ctx context.Context, limit int) (core.UpdateRuns, error) {
+	resp, err := c.client.Runs(ctx, &updatepb.RunsRequest{Limit: int64(limit)})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return core.UpdateRuns{}, core.ErrBadArguments
+		}
+		return core.UpdateRuns{}, err
+	}
+
+	runs := core.UpdateRuns{
+		Runs:   make([]core.UpdateRun, 0, len(resp.Runs)),
+		Failed: make([]core.FailedComic, 0, len(resp.Failed)),
+	}
+	for _, r := range resp.Runs {
+		run := core.UpdateRun{
+			Job:       r.JobId,
+			Trigger:   strings.ToLower(strings.TrimPrefix(r.Trigger.String(), "TRIGGER_")),
+			Mode:      strings.ToLower(strings.TrimPrefix(r.Mode.String(), "UPDATE_MODE_")),
+			State:     jobState(r.State),
+			StartedAt: r.StartedAt.AsTime(),
+			Total:     int(r.Total),
+			Fetched:   int(r.Fetched),
+			Failed:    int(r.Failed),
+			Error:     r.Error,
+		}
+		if r.FinishedAt != nil {
+			finishedAt := r.FinishedAt.AsTime()
+			run.FinishedAt = &finishedAt
+		}
+		runs.Runs = append(runs.Runs, run)
+	}
+	for _, f := range resp.Failed {
+		runs.Failed = append(runs.Failed, core.FailedComic{
+			Comic:    int(f.ComicId),
+			Job:      f.JobId,
+			Error:    f.Error,
+			Attempts: int(f.Attempts),
+			FailedAt: f.FailedAt.AsTime(),
+		})
+	}
+	return runs, nil
+}
+
+func (c Client) Coverage(ctx context.Context, limit int) (core.Coverage, error) {
+	resp, err := c.client.Coverage(ctx, &updatepb.CoverageRequest{Limit: int64(limit)})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return core.Coverage{}, core.ErrBadArguments
+		}
+		return core.Coverage{}, err
+	}
+
+	coverage := core.Coverage{
+		Comics: make([]core.ComicCoverage, 0, len(resp.Comics)),
+		Total:  int(resp.Total),
+	}
+	for _, c := range resp.Comics {
+		coverage.Comics = append(coverage.Comics, core.ComicCoverage{
+			Comic:            int(c.Id),
+			Title:            c.Title,
+			TitleTokens:      int(c.TitleTokens),
+			AltTokens:        int(c.AltTokens),
+			TranscriptTokens: int(c.TranscriptTokens),
+		})
+	}
+	return coverage, nil
+}
+
 func (c Client) Drop(ctx context.Context) error {
 	_, err := c.client.Drop(ctx, &emptypb.Empty{})
 	return err
 }
+
+func (c Client) DeleteComic(ctx context.Context, id int) error {
+	_, err := c.client.DeleteComic(ctx, &updatepb.ComicRequest{Id: int64(id)})
+	return comicError(err)
+}
+
+func (c Client) HideComic(ctx context.Context, id int, hidden bool) error {
+	_, err := c.client.HideComic(ctx, &updatepb.HideRequest{Id: int64(id), Hidden: hidden})
+	return comicError(err)
+}
+
+func (c Client) RefetchComic(ctx context.Context, id int) error {
+	_, err := c.client.RefetchComic(ctx, &updatepb.ComicRequest{Id: int64(id)})
+	return comicError(err)
+}
+
+func (c Client) Image(ctx context.Context, id int) (core.ComicImage, error) {
+	resp, err := c.client.Image(ctx, &updatepb.ComicRequest{Id: int64(id)})
+	if err != nil {
+		return core.ComicImage{}, comicError(err)
+	}
+	return core.ComicImage{
+		Data:   resp.GetData(),
+		Hash:   resp.GetHash(),
+		Width:  int(resp.GetWidth()),
+		Height: int(resp.GetHeight()),
+	}, nil
+}
+
+func (c Client) Annotation(ctx context.Context, id int) (core.Annotation, error) {
+	resp, err := c.client.GetAnnotation(ctx, &updatepb.ComicRequest{Id: int64(id)})
+	if err != nil {
+		return core.Annotation{}, comicError(err)
+	}
+	return core.Annotation{
+		Comic:      int(resp.GetId()),
+		Transcript: resp.GetTranscript(),
+		Keywords:   resp.GetKeywords(),
+		UpdatedAt:  resp.GetUpdatedAt().AsTime(),
+	}, nil
+}
+
+func (c Client) SetAnnotation(ctx context.Context, note core.Annotation) error {
+	_, err := c.client.SetAnnotation(ctx, &updatepb.Annotation{
+		Id:         int64(note.Comic),
+		Transcript: note.Transcript,
+		Keywords:   note.Keywords,
+	})
+	return comicError(err)
+}
+
+func (c Client) DeleteAnnotation(ctx context.Context, id int) error {
+	_, err := c.client.DeleteAnnotation(ctx, &updatepb.ComicRequest{Id: int64(id)})
+	return comicError(err)
+}
+
+func comicError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return core.ErrNotFound
+	case codes.InvalidArgument:
+		return core.ErrBadArguments
+	}
+	return err
+}
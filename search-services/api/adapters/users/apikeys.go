@@ -0,0 +1,103 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"yadro.com/course/api/core"
+)
+
+// touchPeriod limits how often last use of a key is written.
+const touchPeriod = time.Minute
+
+type apiKey struct {
+	ID         string       `db:"id"`
+	Name       string       `db:"name"`
+	KeyHash    string       `db:"key_hash"`
+	Role       core.Role    `db:"role"`
+	Rate       int          `db:"rate"`
+	CreatedBy  string       `db:"created_by"`
+	CreatedAt  time.Time    `db:"created_at"`
+	ExpiresAt  sql.NullTime `db:"expires_at"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+}
+
+func (k apiKey) key() core.APIKey {
+	return core.APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Role:       k.Role,
+		Rate:       k.Rate,
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt.Time,
+		LastUsedAt: k.LastUsedAt.Time,
+	}
+}
+
+const apiKeyColumns = `ID, NAME, KEY_HASH, ROLE, RATE, CREATED_BY, CREATED_AT, EXPIRES_AT, LAST_USED_AT`
+
+func (db *DB) APIKeys(ctx context.Context) ([]core.APIKey, error) {
+	var rows []apiKey
+	if err := db.conn.SelectContext(ctx, &rows, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY CREATED_AT`); err != nil {
+		db.log.Error("failed to list api keys", "error", err)
+		return nil, err
+	}
+	keys := make([]core.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.key())
+	}
+	return keys, nil
+}
+
+func (db *DB) APIKey(ctx context.Context, id string) (core.APIKey, string, error) {
+	var row apiKey
+	err := db.conn.GetContext(ctx, &row, `SELECT `+apiKeyColumns+` FROM api_keys WHERE ID = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.APIKey{}, "", core.ErrNotFound
+	}
+	if err != nil {
+		db.log.Error("failed to get api key", "error", err, "key", id)
+		return core.APIKey{}, "", err
+	}
+	return row.key(), row.KeyHash, nil
+}
+
+func (db *DB) CreateAPIKey(ctx context.Context, key core.APIKey, hash string) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO api_keys (ID, NAME, KEY_HASH, ROLE, RATE, CREATED_BY, EXPIRES_AT)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		key.ID, key.Name, hash, key.Role, key.Rate, key.CreatedBy,
+		sql.NullTime{Time: key.ExpiresAt, Valid: !key.ExpiresAt.IsZero()})
+	if err != nil {
+		db.log.Error("failed to create api key", "error", err, "key", key.ID)
+	}
+	return err
+}
+
+func (db *DB) TouchAPIKey(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE api_keys SET LAST_USED_AT = NOW()
+		WHERE ID = $1 AND (LAST_USED_AT IS NULL OR LAST_USED_AT < NOW() - make_interval(secs => $2))`,
+		id, touchPeriod.Seconds())
+	if err != nil {
+		db.log.Error("failed to touch api key", "error", err, "key", id)
+	}
+	return err
+}
+
+func (db *DB) DeleteAPIKey(ctx context.Context, id string) error {
+	res, err := db.conn.ExecContext(ctx, `DELETE FROM api_keys WHERE ID = $1`, id)
+	if err != nil {
+		db.log.Error("failed to delete api key", "error", err, "key", id)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
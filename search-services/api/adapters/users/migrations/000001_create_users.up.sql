@@ -0,0 +1,6 @@
+CREATE TABLE users (
+    NAME TEXT PRIMARY KEY,
+    PASSWORD_HASH TEXT NOT NULL,
+    ROLE TEXT NOT NULL,
+    CREATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
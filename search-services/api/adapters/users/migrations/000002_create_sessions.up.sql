@@ -0,0 +1,15 @@
+-- refresh tokens are kept hashed, a leaked table does not log anyone in
+CREATE TABLE refresh_tokens (
+    TOKEN_HASH TEXT PRIMARY KEY,
+    USER_NAME TEXT NOT NULL REFERENCES users (NAME) ON DELETE CASCADE,
+    EXPIRES_AT TIMESTAMPTZ NOT NULL,
+    CREATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX refresh_tokens_user_idx ON refresh_tokens (USER_NAME);
+
+-- access tokens revoked before they expire, by JWT ID
+CREATE TABLE revoked_tokens (
+    JTI TEXT PRIMARY KEY,
+    EXPIRES_AT TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,12 @@
+-- no foreign key to users: keys outlive the admin who issued them
+CREATE TABLE api_keys (
+    ID TEXT PRIMARY KEY,
+    NAME TEXT NOT NULL,
+    KEY_HASH TEXT NOT NULL,
+    ROLE TEXT NOT NULL,
+    RATE INTEGER NOT NULL DEFAULT 0,
+    CREATED_BY TEXT NOT NULL,
+    CREATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    EXPIRES_AT TIMESTAMPTZ,
+    LAST_USED_AT TIMESTAMPTZ
+);
@@ -0,0 +1,2 @@
+-- issuer of users signed in by an OIDC provider, empty for local users
+ALTER TABLE users ADD COLUMN PROVIDER TEXT NOT NULL DEFAULT ''
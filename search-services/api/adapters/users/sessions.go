@@ -0,0 +1,81 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"yadro.com/course/api/core"
+)
+
+func (db *DB) SaveRefresh(ctx context.Context, hash, user string, expires time.Time) error {
+	if _, err := db.conn.ExecContext(ctx,
+		`DELETE FROM refresh_tokens WHERE USER_NAME = $1 AND EXPIRES_AT < NOW()`, user); err != nil {
+		db.log.Error("failed to prune refresh tokens", "error", err, "user", user)
+		return err
+	}
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (TOKEN_HASH, USER_NAME, EXPIRES_AT) VALUES ($1, $2, $3)`, hash, user, expires)
+	if err != nil {
+		db.log.Error("failed to save refresh token", "error", err, "user", user)
+		return err
+	}
+	return nil
+}
+
+func (db *DB) TakeRefresh(ctx context.Context, hash string) (core.User, error) {
+	var row struct {
+		Name      string    `db:"name"`
+		Role      core.Role `db:"role"`
+		CreatedAt time.Time `db:"created_at"`
+		ExpiresAt time.Time `db:"expires_at"`
+	}
+	err := db.conn.GetContext(ctx, &row, `
+		DELETE FROM refresh_tokens t USING users u
+		WHERE t.TOKEN_HASH = $1 AND u.NAME = t.USER_NAME
+		RETURNING u.NAME, u.ROLE, u.CREATED_AT, t.EXPIRES_AT`, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.User{}, core.ErrNotFound
+	}
+	if err != nil {
+		db.log.Error("failed to take refresh token", "error", err)
+		return core.User{}, err
+	}
+	if row.ExpiresAt.Before(time.Now()) {
+		return core.User{}, core.ErrNotFound
+	}
+	return core.User{Name: row.Name, Role: row.Role, CreatedAt: row.CreatedAt}, nil
+}
+
+func (db *DB) DeleteRefresh(ctx context.Context, hash, user string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`DELETE FROM refresh_tokens WHERE TOKEN_HASH = $1 AND USER_NAME = $2`, hash, user)
+	if err != nil {
+		db.log.Error("failed to delete refresh token", "error", err, "user", user)
+	}
+	return err
+}
+
+func (db *DB) Revoke(ctx context.Context, jti string, expires time.Time) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE EXPIRES_AT < NOW()`); err != nil {
+		db.log.Error("failed to prune revoked tokens", "error", err)
+		return err
+	}
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (JTI, EXPIRES_AT) VALUES ($1, $2)
+		ON CONFLICT (JTI) DO NOTHING`, jti, expires)
+	if err != nil {
+		db.log.Error("failed to revoke token", "error", err)
+	}
+	return err
+}
+
+func (db *DB) Revoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := db.conn.GetContext(ctx, &revoked, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE JTI = $1)`, jti)
+	if err != nil {
+		db.log.Error("failed to check revoked token", "error", err)
+	}
+	return revoked, err
+}
@@ -0,0 +1,311 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
+	"yadro.com/course/api/core"
+)
+
+const (
+	minPassword = 8
+	// bcrypt ignores anything longer
+	maxPassword = 72
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9._@-]{1,64}$`)
+
+// subjectPattern admits provisioned users named by OIDC subjects, which
+// are up to 255 printable ASCII characters, like "auth0|5f7c8ec7".
+var subjectPattern = regexp.MustCompile(`^[!-~]{1,255}$`)
+
+// dummyHash is compared against for unknown users, so a login takes as long
+// whether the user exists or not.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+type DB struct {
+	log  *slog.Logger
+	conn *sqlx.DB
+}
+
+func New(log *slog.Logger, address string) (*DB, error) {
+	db, err := sqlx.Connect("pgx", address)
+	if err != nil {
+		log.Error("connection problem", "address", address, "error", err)
+		return nil, err
+	}
+	return &DB{
+		log:  log,
+		conn: db,
+	}, nil
+}
+
+// EnsureAdmin creates an admin unless a user with the name exists,
+// an existing user keeps its password and role.
+func (db *DB) EnsureAdmin(ctx context.Context, name, password string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: malformed user name", core.ErrBadArguments)
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO users (NAME, PASSWORD_HASH, ROLE) VALUES ($1, $2, $3)
+		ON CONFLICT (NAME) DO NOTHING`, name, hash, core.RoleAdmin)
+	if err != nil {
+		db.log.Error("failed to create admin", "error", err, "user", name)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		db.log.Info("admin created", "user", name)
+	}
+	return nil
+}
+
+func (db *DB) ProvisionUser(ctx context.Context, u core.User) error {
+	if !subjectPattern.MatchString(u.Name) {
+		return fmt.Errorf("%w: malformed user name", core.ErrBadArguments)
+	}
+	if !u.Role.Valid() || u.Provider == "" {
+		return fmt.Errorf("%w: provisioned users need a role and a provider", core.ErrBadArguments)
+	}
+
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		db.log.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := lockUser(ctx, tx, u.Name)
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO users (NAME, PASSWORD_HASH, ROLE, PROVIDER) VALUES ($1, '', $2, $3)
+			ON CONFLICT (NAME) DO NOTHING`, u.Name, u.Role, u.Provider)
+		if err != nil {
+			db.log.Error("failed to provision user", "error", err, "user", u.Name)
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return core.ErrAlreadyExists
+		}
+		return tx.Commit()
+	case err != nil:
+		return err
+	}
+
+	// local users and users of other providers are never taken over
+	var provider string
+	if err := tx.GetContext(ctx, &provider, `SELECT PROVIDER FROM users WHERE NAME = $1`, u.Name); err != nil {
+		return err
+	}
+	if provider != u.Provider {
+		return core.ErrAlreadyExists
+	}
+	if current == core.RoleAdmin && u.Role != core.RoleAdmin {
+		if err := keepAdmin(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET ROLE = $2 WHERE NAME = $1`, u.Name, u.Role); err != nil {
+		db.log.Error("failed to provision user", "error", err, "user", u.Name)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (db *DB) Users(ctx context.Context) ([]core.User, error) {
+	var rows []user
+	err := db.conn.SelectContext(ctx, &rows, `SELECT NAME, ROLE, PROVIDER, CREATED_AT FROM users ORDER BY NAME`)
+	if err != nil {
+		db.log.Error("failed to list users", "error", err)
+		return nil, err
+	}
+	users := make([]core.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, row.user())
+	}
+	return users, nil
+}
+
+func (db *DB) Verify(ctx context.Context, name, password string) (core.User, error) {
+	var row user
+	err := db.conn.GetContext(ctx, &row,
+		`SELECT NAME, PASSWORD_HASH, ROLE, PROVIDER, CREATED_AT FROM users WHERE NAME = $1 AND PROVIDER = ''`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return core.User{}, core.ErrNotFound
+	}
+	if err != nil {
+		db.log.Error("failed to get user", "error", err, "user", name)
+		return core.User{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(row.PasswordHash), []byte(password)); err != nil {
+		return core.User{}, core.ErrNotFound
+	}
+	return row.user(), nil
+}
+
+func (db *DB) CreateUser(ctx context.Context, u core.User, password string) error {
+	if !namePattern.MatchString(u.Name) {
+		return fmt.Errorf("%w: malformed user name", core.ErrBadArguments)
+	}
+	if !u.Role.Valid() {
+		return fmt.Errorf("%w: unknown role %q", core.ErrBadArguments, u.Role)
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO users (NAME, PASSWORD_HASH, ROLE) VALUES ($1, $2, $3)
+		ON CONFLICT (NAME) DO NOTHING`, u.Name, hash, u.Role)
+	if err != nil {
+		db.log.Error("failed to create user", "error", err, "user", u.Name)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return core.ErrAlreadyExists
+	}
+	return nil
+}
+
+func (db *DB) UpdateUser(ctx context.Context, name string, role core.Role, password string) error {
+	if role != "" && !role.Valid() {
+		return fmt.Errorf("%w: unknown role %q", core.ErrBadArguments, role)
+	}
+	var hash string
+	if password != "" {
+		var err error
+		if hash, err = hashPassword(password); err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		db.log.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := lockUser(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+	if current == core.RoleAdmin && role != "" && role != core.RoleAdmin {
+		if err := keepAdmin(ctx, tx); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET
+			ROLE = COALESCE(NULLIF($2, ''), ROLE),
+			PASSWORD_HASH = COALESCE(NULLIF($3, ''), PASSWORD_HASH)
+		WHERE NAME = $1`, name, role, hash)
+	if err != nil {
+		db.log.Error("failed to update user", "error", err, "user", name)
+		return err
+	}
+	// a new password logs the user out everywhere
+	if hash != "" {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE USER_NAME = $1`, name); err != nil {
+			db.log.Error("failed to delete refresh tokens", "error", err, "user", name)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (db *DB) DeleteUser(ctx context.Context, name string) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		db.log.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := lockUser(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+	if current == core.RoleAdmin {
+		if err := keepAdmin(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE NAME = $1`, name); err != nil {
+		db.log.Error("failed to delete user", "error", err, "user", name)
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockUser returns the role of a user, locking the admins against
+// concurrent demotions.
+func lockUser(ctx context.Context, tx *sqlx.Tx, name string) (core.Role, error) {
+	var roles []struct {
+		Name string    `db:"name"`
+		Role core.Role `db:"role"`
+	}
+	err := tx.SelectContext(ctx, &roles,
+		`SELECT NAME, ROLE FROM users WHERE NAME = $1 OR ROLE = $2 FOR UPDATE`, name, core.RoleAdmin)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range roles {
+		if r.Name == name {
+			return r.Role, nil
+		}
+	}
+	return "", core.ErrNotFound
+}
+
+// keepAdmin fails if the admin being removed is the last one.
+func keepAdmin(ctx context.Context, tx *sqlx.Tx) error {
+	var admins int
+	if err := tx.GetContext(ctx, &admins, `SELECT COUNT(*) FROM users WHERE ROLE = $1`, core.RoleAdmin); err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return fmt.Errorf("%w: the last admin cannot be removed", core.ErrBadArguments)
+	}
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPassword || len(password) > maxPassword {
+		return "", fmt.Errorf("%w: password must be %d to %d bytes long", core.ErrBadArguments, minPassword, maxPassword)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+type user struct {
+	Name         string    `db:"name"`
+	PasswordHash string    `db:"password_hash"`
+	Role         core.Role `db:"role"`
+	Provider     string    `db:"provider"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+func (u user) user() core.User {
+	return core.User{Name: u.Name, Role: u.Role, Provider: u.Provider, CreatedAt: u.CreatedAt}
+}
@@ -13,17 +13,48 @@ type HTTPConfig struct {
 	Timeout time.Duration `yaml:"timeout" env:"API_TIMEOUT" env-default:"5s"`
 }
 
+// JWTConfig selects token keys, see auth.KeyConfig. VerifyKeys are given
+// in env as "kid:file,kid:file".
+type JWTConfig struct {
+	KeyID      string            `yaml:"key_id" env:"JWT_KEY_ID"`
+	Secret     string            `yaml:"secret" env:"JWT_SECRET"`
+	KeyFile    string            `yaml:"key_file" env:"JWT_KEY_FILE"`
+	VerifyKeys map[string]string `yaml:"verify_keys" env:"JWT_VERIFY_KEYS"`
+}
+
+// OIDCConfig enables SSO login if Issuer is set, see oidc.Config. Roles
+// map role claim values to roles, in env as "value:role,value:role".
+type OIDCConfig struct {
+	Issuer        string            `yaml:"issuer" env:"OIDC_ISSUER"`
+	ClientID      string            `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret  string            `yaml:"client_secret" env:"OIDC_CLIENT_SECRET"`
+	RedirectURL   string            `yaml:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	Scopes        []string          `yaml:"scopes" env:"OIDC_SCOPES" env-default:"openid,profile,email"`
+	UsernameClaim string            `yaml:"username_claim" env:"OIDC_USERNAME_CLAIM"`
+	RoleClaim     string            `yaml:"role_claim" env:"OIDC_ROLE_CLAIM" env-default:"groups"`
+	Roles         map[string]string `yaml:"roles" env:"OIDC_ROLES"`
+	DefaultRole   string            `yaml:"default_role" env:"OIDC_DEFAULT_ROLE"`
+	// PostLoginURL gets the tokens in its fragment after login
+	PostLoginURL string        `yaml:"post_login_url" env:"OIDC_POST_LOGIN_URL" env-default:"/login"`
+	Timeout      time.Duration `yaml:"timeout" env:"OIDC_TIMEOUT" env-default:"10s"`
+}
+
 type Config struct {
 	LogLevel          string        `yaml:"log_level" env:"LOG_LEVEL" env-default:"DEBUG"`
 	HTTPConfig        HTTPConfig    `yaml:"api_server"`
 	WordsAddress      string        `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"words:81"`
 	UpdateAddress     string        `yaml:"update_address" env:"UPDATE_ADDRESS" env-default:"update:82"`
 	SearchAddress     string        `yaml:"search_address" env:"SEARCH_ADDRESS" env-default:"search:83"`
-	AdminUser         string        `yaml:"admin_user" env:"ADMIN_USER" env-required:"true"`
-	AdminPassword     string        `yaml:"admin_password" env:"ADMIN_PASSWORD" env-required:"true"`
+	DBAddress         string        `yaml:"db_address" env:"DB_ADDRESS" env-required:"true"`
+	AdminUser         string        `yaml:"admin_user" env:"ADMIN_USER"`
+	AdminPassword     string        `yaml:"admin_password" env:"ADMIN_PASSWORD"`
 	TokenTTL          time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" env-default:"2m"`
+	RefreshTTL        time.Duration `yaml:"refresh_ttl" env:"REFRESH_TTL" env-default:"168h"`
+	JWT               JWTConfig     `yaml:"jwt"`
+	OIDC              OIDCConfig    `yaml:"oidc"`
 	SearchConcurrency int           `yaml:"search_concurrency" env:"SEARCH_CONCURRENCY" env-default:"10"`
 	SearchRate        int           `yaml:"search_rate" env:"SEARCH_RATE" env-default:"100"`
+	APIKeyRate        int           `yaml:"api_key_rate" env:"API_KEY_RATE" env-default:"10"`
 }
 
 func MustLoad(configPath string) Config {
@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 type UpdateStatus string
 
 const (
@@ -8,11 +10,123 @@ const (
 	StatusUpdateRunning UpdateStatus = "running"
 )
 
+type UpdateMode string
+
+const (
+	UpdateModeNew     UpdateMode = "new"
+	UpdateModeFailed  UpdateMode = "failed"
+	UpdateModeRefresh UpdateMode = "refresh"
+	UpdateModeReindex UpdateMode = "reindex"
+)
+
+// UpdateOptions of refresh mode select stored comics by an inclusive ID
+// range and/or by age, zero values do not restrict.
+type UpdateOptions struct {
+	Mode          UpdateMode
+	FromID        int
+	ToID          int
+	OlderThanDays int
+}
+
+type JobState string
+
+const (
+	JobUnknown  JobState = "unknown"
+	JobRunning  JobState = "running"
+	JobDone     JobState = "done"
+	JobFailed   JobState = "failed"
+	JobCanceled JobState = "canceled"
+)
+
+type UpdateProgress struct {
+	Job        string     `json:"job"`
+	State      JobState   `json:"state"`
+	Total      int        `json:"total"`
+	Fetched    int        `json:"fetched"`
+	Failed     int        `json:"failed"`
+	InFlight   int        `json:"in_flight"`
+	ETASeconds int64      `json:"eta_seconds"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// UpdateEvent is a step of an update job, see update service for types.
+type UpdateEvent struct {
+	Type     string         `json:"type"`
+	Comic    int            `json:"comic,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Progress UpdateProgress `json:"progress"`
+}
+
+type UpdateRun struct {
+	Job        string     `json:"job"`
+	Trigger    string     `json:"trigger"`
+	Mode       string     `json:"mode"`
+	State      JobState   `json:"state"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Total      int        `json:"total"`
+	Fetched    int        `json:"fetched"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type FailedComic struct {
+	Comic    int       `json:"comic"`
+	Job      string    `json:"job"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+type UpdateRuns struct {
+	Runs   []UpdateRun   `json:"runs"`
+	Failed []FailedComic `json:"failed"`
+}
+
+// ComicCoverage counts tokens in each text field of a comic.
+type ComicCoverage struct {
+	Comic            int    `json:"comic"`
+	Title            string `json:"title"`
+	TitleTokens      int    `json:"title_tokens"`
+	AltTokens        int    `json:"alt_tokens"`
+	TranscriptTokens int    `json:"transcript_tokens"`
+}
+
+// Coverage lists poorly indexed comics; Total is how many there are.
+type Coverage struct {
+	Comics []ComicCoverage `json:"comics"`
+	Total  int             `json:"total"`
+}
+
+// Annotation is a custom transcript and extra keywords of a comic,
+// searchable along with its fetched text.
+type Annotation struct {
+	Comic      int       `json:"comic"`
+	Transcript string    `json:"transcript"`
+	Keywords   []string  `json:"keywords"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ComicImage is a comic image from the local store; Hash is its sha256.
+type ComicImage struct {
+	Data   []byte
+	Hash   string
+	Width  int
+	Height int
+}
+
 type UpdateStats struct {
-	WordsTotal    int
-	WordsUnique   int
-	ComicsFetched int
-	ComicsTotal   int
+	WordsTotal      int
+	WordsUnique     int
+	ComicsFetched   int
+	ComicsTotal     int
+	LatestCheckedAt time.Time
+	// comics with an empty field
+	NoTitle      int
+	NoAlt        int
+	NoTranscript int
 }
 
 type Comics struct {
@@ -30,3 +144,80 @@ type SearchResult struct {
 	Comics []Comic `json:"comics"`
 	Total  int64   `json:"total"`
 }
+
+// SearchFilter narrows search to comics with a tag or in a collection,
+// empty fields do not restrict.
+type SearchFilter struct {
+	Tag        string
+	Collection string
+}
+
+type TagCount struct {
+	Tag    string `json:"tag"`
+	Comics int    `json:"comics"`
+}
+
+// Collection is a named group of comics, e.g. "onboarding".
+type Collection struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Comics      []int64   `json:"comics"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SimilarComic is a comic found by image; Distance counts differing
+// perceptual hash bits, 0 for the same picture.
+type SimilarComic struct {
+	ID       int64  `json:"id"`
+	URL      string `json:"url"`
+	Distance int    `json:"distance"`
+}
+
+// Role grants access to API routes, each role includes the ones below it:
+// viewers read service data, editors change comics and run updates,
+// admins drop the database and manage users.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows tells if the role grants access to routes requiring role required.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+type User struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+	// Provider is the OIDC issuer of users signed in with SSO,
+	// empty for users with a password
+	Provider  string    `json:"provider,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKey lets a machine client in with a role. Zero ExpiresAt never
+// expires, zero Rate takes the default rate limit.
+type APIKey struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Role       Role      `json:"role"`
+	Rate       int       `json:"rate,omitempty"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at,omitzero"`
+	LastUsedAt time.Time `json:"last_used_at,omitzero"`
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"time"
 )
 
 type Normalizer interface {
@@ -13,15 +14,62 @@ type Pinger interface {
 }
 
 type Updater interface {
-	Update(context.Context) error
+	// Update starts an update job and returns its ID. If an update is
+	// already running, its ID is returned with ErrAlreadyExists.
+	Update(ctx context.Context, opts UpdateOptions) (string, error)
+	// Reindex starts a job normalizing stored comics again, same as Update.
+	Reindex(context.Context) (string, error)
+	Progress(ctx context.Context, job string) (UpdateProgress, error)
+	Cancel(ctx context.Context, job string) error
+	// Watch streams update events until ctx is done or the stream breaks.
+	Watch(context.Context) (<-chan UpdateEvent, error)
 	Stats(context.Context) (UpdateStats, error)
+	Runs(ctx context.Context, limit int) (UpdateRuns, error)
+	// Coverage lists up to limit comics without a transcript.
+	Coverage(ctx context.Context, limit int) (Coverage, error)
 	Status(context.Context) (UpdateStatus, error)
 	Drop(context.Context) error
+	// DeleteComic, HideComic and RefetchComic return ErrNotFound for
+	// unknown or already deleted comics.
+	DeleteComic(ctx context.Context, id int) error
+	HideComic(ctx context.Context, id int, hidden bool) error
+	RefetchComic(ctx context.Context, id int) error
+	// Image returns a stored comic image or ErrNotFound.
+	Image(ctx context.Context, id int) (ComicImage, error)
+	// Annotation returns ErrNotFound if the comic is not annotated.
+	Annotation(ctx context.Context, id int) (Annotation, error)
+	SetAnnotation(ctx context.Context, note Annotation) error
+	DeleteAnnotation(ctx context.Context, id int) error
 }
 
 type Searcher interface {
-	Search(ctx context.Context, phrase string, limit int) (SearchResult, error)
-	ISearch(ctx context.Context, phrase string, limit int) (SearchResult, error)
+	// Search and ISearch return ErrBadArguments for malformed filters.
+	Search(ctx context.Context, phrase string, filter SearchFilter, limit int) (SearchResult, error)
+	ISearch(ctx context.Context, phrase string, filter SearchFilter, limit int) (SearchResult, error)
+	// Similar returns comics with images looking like the image of comic id,
+	// ErrNotFound if it has none.
+	Similar(ctx context.Context, id int64, limit int) ([]SimilarComic, error)
+	// Match returns comics with images looking like the picture,
+	// ErrBadArguments if it is not an image.
+	Match(ctx context.Context, image []byte, limit int) ([]SimilarComic, error)
+}
+
+// Curator manages tags and collections of comics. Methods return
+// ErrNotFound for unknown comics, tags or collections and ErrBadArguments
+// for malformed names.
+type Curator interface {
+	Tags(ctx context.Context) ([]TagCount, error)
+	ComicTags(ctx context.Context, id int64) ([]string, error)
+	AddTag(ctx context.Context, id int64, tag string) error
+	RemoveTag(ctx context.Context, id int64, tag string) error
+
+	Collections(ctx context.Context) ([]Collection, error)
+	Collection(ctx context.Context, name string) (Collection, error)
+	// SaveCollection creates a collection or changes its description.
+	SaveCollection(ctx context.Context, c Collection) error
+	DeleteCollection(ctx context.Context, name string) error
+	AddToCollection(ctx context.Context, name string, id int64) error
+	RemoveFromCollection(ctx context.Context, name string, id int64) error
 }
 
 type DBStats struct {
@@ -36,3 +84,44 @@ type ComicsStorage interface {
 	GetSavedComicIDs(context.Context) ([]int, error)
 	DropAll(context.Context) error
 }
+
+// Users keeps accounts with hashed passwords. Methods return ErrNotFound
+// for unknown users and ErrBadArguments for malformed names, passwords and
+// roles or for changes leaving no admin.
+type Users interface {
+	Users(ctx context.Context) ([]User, error)
+	// Verify returns the user if the password matches, ErrNotFound otherwise.
+	Verify(ctx context.Context, name, password string) (User, error)
+	// CreateUser returns ErrAlreadyExists if the name is taken.
+	CreateUser(ctx context.Context, user User, password string) error
+	// UpdateUser changes the role and the password, empty ones are kept.
+	UpdateUser(ctx context.Context, name string, role Role, password string) error
+	DeleteUser(ctx context.Context, name string) error
+	// ProvisionUser creates a user signed in by a provider or updates
+	// its role, ErrAlreadyExists if the name is of another user.
+	ProvisionUser(ctx context.Context, user User) error
+}
+
+// Sessions keeps refresh tokens by hash and the denylist of revoked
+// access tokens by JWT ID.
+type Sessions interface {
+	SaveRefresh(ctx context.Context, hash, user string, expires time.Time) error
+	// TakeRefresh deletes a refresh token and returns its user,
+	// ErrNotFound if the token is unknown or expired.
+	TakeRefresh(ctx context.Context, hash string) (User, error)
+	DeleteRefresh(ctx context.Context, hash, user string) error
+	// Revoke denies an access token until it expires.
+	Revoke(ctx context.Context, jti string, expires time.Time) error
+	Revoked(ctx context.Context, jti string) (bool, error)
+}
+
+// APIKeys keeps API keys with hashed secrets.
+type APIKeys interface {
+	APIKeys(ctx context.Context) ([]APIKey, error)
+	// APIKey returns a key with its secret hash, ErrNotFound if unknown.
+	APIKey(ctx context.Context, id string) (APIKey, string, error)
+	CreateAPIKey(ctx context.Context, key APIKey, hash string) error
+	// TouchAPIKey records the key was used now.
+	TouchAPIKey(ctx context.Context, id string) error
+	DeleteAPIKey(ctx context.Context, id string) error
+}
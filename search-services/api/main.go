@@ -5,14 +5,17 @@ import (
 	"errors"
 	"flag"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 
 	"yadro.com/course/api/adapters/auth"
+	"yadro.com/course/api/adapters/oidc"
 	"yadro.com/course/api/adapters/rest"
 	"yadro.com/course/api/adapters/search"
 	"yadro.com/course/api/adapters/update"
+	"yadro.com/course/api/adapters/users"
 	"yadro.com/course/api/adapters/words"
 	"yadro.com/course/api/config"
 	"yadro.com/course/api/core"
@@ -47,9 +50,43 @@ func main() {
 		os.Exit(1)
 	}
 
-	authAdapter := auth.New(cfg.AdminUser, cfg.AdminPassword, cfg.TokenTTL)
+	userStore, err := users.New(log, cfg.DBAddress)
+	if err != nil {
+		log.Error("cannot init users adapter", "error", err)
+		os.Exit(1)
+	}
+	if err := userStore.Migrate(); err != nil {
+		log.Error("cannot migrate users", "error", err)
+		os.Exit(1)
+	}
+	// the configured admin is only created once, further users and
+	// password changes are managed via the API
+	if cfg.AdminUser != "" {
+		if err := userStore.EnsureAdmin(context.Background(), cfg.AdminUser, cfg.AdminPassword); err != nil {
+			log.Error("cannot create admin", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	keys, err := auth.LoadKeys(auth.KeyConfig{
+		KeyID:      cfg.JWT.KeyID,
+		Secret:     cfg.JWT.Secret,
+		KeyFile:    cfg.JWT.KeyFile,
+		VerifyKeys: cfg.JWT.VerifyKeys,
+	})
+	if err != nil {
+		log.Error("cannot load token keys", "error", err)
+		os.Exit(1)
+	}
+	if keys.Generated() {
+		log.Warn("no token signing key configured, tokens are invalidated on restart")
+	}
+
+	authAdapter := auth.New(userStore, userStore, keys, cfg.TokenTTL, cfg.RefreshTTL)
 
-	mw := rest.NewMiddleware(log, authAdapter)
+	apiKeys := auth.NewAPIKeyAuth(userStore)
+
+	mw := rest.NewMiddleware(log, cfg.APIKeyRate, auth.NewTokenAuthenticator(authAdapter), apiKeys)
 
 	pingers := map[string]core.Pinger{
 		"update": updateClient,
@@ -60,27 +97,74 @@ func main() {
 	mux := http.NewServeMux()
 	mux.Handle("GET /api/ping", rest.NewPingHandler(log, pingers))
 
-	mux.Handle("POST /api/db/update", mw.AuthMiddleware(rest.NewUpdateHandler(log, updateClient)))
-	mux.Handle("DELETE /api/db", mw.AuthMiddleware(rest.NewDropHandler(log, updateClient)))
+	mux.Handle("POST /api/db/update", mw.AuthMiddleware(core.RoleEditor, rest.NewUpdateHandler(log, updateClient)))
+	mux.Handle("POST /api/db/reindex", mw.AuthMiddleware(core.RoleEditor, rest.NewReindexHandler(log, updateClient)))
+	mux.Handle("GET /api/db/update/events", rest.NewUpdateEventsHandler(log, updateClient))
+	mux.Handle("GET /api/db/update/{job}", rest.NewUpdateProgressHandler(log, updateClient))
+	mux.Handle("DELETE /api/db/update/{job}", mw.AuthMiddleware(core.RoleEditor, rest.NewUpdateCancelHandler(log, updateClient)))
+	mux.Handle("DELETE /api/db", mw.AuthMiddleware(core.RoleAdmin, rest.NewDropHandler(log, updateClient)))
+	mux.Handle("DELETE /api/comics/{id}", mw.AuthMiddleware(core.RoleEditor, rest.NewDeleteComicHandler(log, updateClient)))
+	mux.Handle("POST /api/comics/{id}/hide", mw.AuthMiddleware(core.RoleEditor, rest.NewHideComicHandler(log, updateClient, true)))
+	mux.Handle("POST /api/comics/{id}/unhide", mw.AuthMiddleware(core.RoleEditor, rest.NewHideComicHandler(log, updateClient, false)))
+	mux.Handle("POST /api/comics/{id}/refetch", mw.AuthMiddleware(core.RoleEditor, rest.NewRefetchComicHandler(log, updateClient)))
+	mux.Handle("GET /api/comics/{id}/image", rest.NewComicImageHandler(log, updateClient))
+	mux.Handle("GET /api/comics/{id}/annotation", mw.AuthMiddleware(core.RoleViewer, rest.NewAnnotationHandler(log, updateClient)))
+	mux.Handle("PUT /api/comics/{id}/annotation", mw.AuthMiddleware(core.RoleEditor, rest.NewSetAnnotationHandler(log, updateClient)))
+	mux.Handle("DELETE /api/comics/{id}/annotation", mw.AuthMiddleware(core.RoleEditor, rest.NewDeleteAnnotationHandler(log, updateClient)))
 
 	mux.Handle("GET /api/search", mw.ConcurrencyLimitMiddleware(cfg.SearchConcurrency, rest.NewSearchHandler(log, searchClient)))
 
+	mux.Handle("GET /api/comics/{id}/visually-similar", rest.NewVisuallySimilarHandler(log, searchClient))
+	mux.Handle("POST /api/comics/match", mw.ConcurrencyLimitMiddleware(cfg.SearchConcurrency, rest.NewMatchImageHandler(log, searchClient)))
 	mux.Handle("GET /api/isearch", mw.RateLimitMiddleware(cfg.SearchRate, rest.NewISearchHandler(log, searchClient)))
+	mux.Handle("GET /api/tags", rest.NewTagsHandler(log, searchClient))
+	mux.Handle("GET /api/comics/{id}/tags", rest.NewComicTagsHandler(log, searchClient))
+	mux.Handle("PUT /api/comics/{id}/tags/{tag}", mw.AuthMiddleware(core.RoleEditor, rest.NewTagHandler(log, searchClient, false)))
+	mux.Handle("DELETE /api/comics/{id}/tags/{tag}", mw.AuthMiddleware(core.RoleEditor, rest.NewTagHandler(log, searchClient, true)))
+	mux.Handle("GET /api/collections", rest.NewCollectionsHandler(log, searchClient))
+	mux.Handle("GET /api/collections/{name}", rest.NewCollectionHandler(log, searchClient))
+	mux.Handle("PUT /api/collections/{name}", mw.AuthMiddleware(core.RoleEditor, rest.NewSaveCollectionHandler(log, searchClient)))
+	mux.Handle("DELETE /api/collections/{name}", mw.AuthMiddleware(core.RoleEditor, rest.NewDeleteCollectionHandler(log, searchClient)))
+	mux.Handle("PUT /api/collections/{name}/comics/{id}", mw.AuthMiddleware(core.RoleEditor, rest.NewCollectionComicHandler(log, searchClient, false)))
+	mux.Handle("DELETE /api/collections/{name}/comics/{id}", mw.AuthMiddleware(core.RoleEditor, rest.NewCollectionComicHandler(log, searchClient, true)))
 
 	mux.Handle("POST /api/login", rest.NewLoginHandler(log, authAdapter))
-
+	mux.Handle("POST /api/token/refresh", rest.NewRefreshHandler(log, authAdapter))
+	mux.Handle("POST /api/logout", mw.AuthMiddleware(core.RoleViewer, rest.NewLogoutHandler(log, authAdapter)))
+	mux.Handle("GET /api/keys", mw.AuthMiddleware(core.RoleAdmin, rest.NewAPIKeysHandler(log, userStore)))
+	mux.Handle("POST /api/keys", mw.AuthMiddleware(core.RoleAdmin, rest.NewCreateAPIKeyHandler(log, apiKeys)))
+	mux.Handle("DELETE /api/keys/{id}", mw.AuthMiddleware(core.RoleAdmin, rest.NewDeleteAPIKeyHandler(log, userStore)))
+	if cfg.OIDC.Issuer != "" {
+		rp, err := newOIDC(cfg.OIDC, log)
+		if err != nil {
+			log.Error("cannot init oidc adapter", "error", err)
+			os.Exit(1)
+		}
+		mux.Handle("GET /api/oidc/login", rest.NewOIDCLoginHandler(log, rp))
+		mux.Handle("GET /api/oidc/callback", rest.NewOIDCCallbackHandler(log, rp, authAdapter, cfg.OIDC.PostLoginURL))
+	}
+	mux.Handle("GET /.well-known/jwks.json", rest.NewJWKSHandler(log, authAdapter))
+	mux.Handle("GET /api/users", mw.AuthMiddleware(core.RoleAdmin, rest.NewUsersHandler(log, userStore)))
+	mux.Handle("POST /api/users", mw.AuthMiddleware(core.RoleAdmin, rest.NewCreateUserHandler(log, userStore)))
+	mux.Handle("PATCH /api/users/{name}", mw.AuthMiddleware(core.RoleAdmin, rest.NewUpdateUserHandler(log, userStore)))
+	mux.Handle("DELETE /api/users/{name}", mw.AuthMiddleware(core.RoleAdmin, rest.NewDeleteUserHandler(log, userStore)))
+
+	mux.Handle("GET /api/db/runs", mw.AuthMiddleware(core.RoleViewer, rest.NewUpdateRunsHandler(log, updateClient)))
+	mux.Handle("GET /api/db/coverage", mw.AuthMiddleware(core.RoleViewer, rest.NewCoverageHandler(log, updateClient)))
 	mux.Handle("GET /api/db/stats", rest.NewUpdateStatsHandler(log, updateClient))
 	mux.Handle("GET /api/db/status", rest.NewUpdateStatusHandler(log, updateClient))
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	server := http.Server{
 		Addr:        cfg.HTTPConfig.Address,
 		ReadTimeout: cfg.HTTPConfig.Timeout,
 		Handler:     mux,
+		// event streams never go idle, so they are ended with the server
+		BaseContext: func(net.Listener) context.Context { return ctx },
 	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer stop()
-
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
@@ -98,6 +182,24 @@ func main() {
 	}
 }
 
+func newOIDC(cfg config.OIDCConfig, log *slog.Logger) (*oidc.Provider, error) {
+	roles := make(map[string]core.Role, len(cfg.Roles))
+	for value, role := range cfg.Roles {
+		roles[value] = core.Role(role)
+	}
+	return oidc.New(log, oidc.Config{
+		Issuer:        cfg.Issuer,
+		ClientID:      cfg.ClientID,
+		ClientSecret:  cfg.ClientSecret,
+		RedirectURL:   cfg.RedirectURL,
+		Scopes:        cfg.Scopes,
+		UsernameClaim: cfg.UsernameClaim,
+		RoleClaim:     cfg.RoleClaim,
+		Roles:         roles,
+		DefaultRole:   core.Role(cfg.DefaultRole),
+	}, cfg.Timeout)
+}
+
 func mustMakeLogger(logLevel string) *slog.Logger {
 	var level slog.Level
 	switch logLevel {
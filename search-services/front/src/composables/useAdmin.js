@@ -10,31 +10,63 @@ export function useAdmin(showToast) {
         isAdmin.value = !!getToken()
     }
 
-    const apiCall = async (url, options = {}) => {
+    // refresh swaps the refresh token for new tokens, false if it is gone
+    const refresh = async () => {
+        const refreshToken = localStorage.getItem('refresh_token')
+        if (!refreshToken) return false
+        const res = await fetch('/api/token/refresh', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ refresh_token: refreshToken })
+        })
+        if (!res.ok) return false
+        const tokens = await res.json()
+        localStorage.setItem('token', tokens.access_token)
+        localStorage.setItem('refresh_token', tokens.refresh_token)
+        return true
+    }
+
+    const apiCall = async (url, options = {}, retry = true) => {
         const token = getToken()
         const headers = { ...options.headers }
         if (token) headers['Authorization'] = `Bearer ${token}`
 
         const res = await fetch(url, { ...options, headers })
         if (res.status === 401) {
-            logout()
+            if (retry && await refresh()) return apiCall(url, options, false)
+            clearTokens()
             return null
         }
         return res
     }
 
-    const logout = () => {
+    const clearTokens = () => {
         localStorage.removeItem('token')
+        localStorage.removeItem('refresh_token')
         isAdmin.value = false
+    }
+
+    const logout = async () => {
+        const refreshToken = localStorage.getItem('refresh_token')
+        try {
+            await apiCall('/api/logout', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ refresh_token: refreshToken || '' })
+            })
+        } catch (e) {
+            // tokens are dropped anyway
+        }
+        clearTokens()
         if (showToast) showToast("Logged out successfully", "info")
     }
 
-    const updateDB = async (callback) => {
+    const updateDB = async (callback, mode = 'new') => {
         adminLoading.value = true
         try {
-            const res = await apiCall('/api/db/update', { method: 'POST' })
+            const res = await apiCall(`/api/db/update?mode=${mode}`, { method: 'POST' })
             if (res && res.ok) {
-                if (showToast) showToast("Database Update Triggered", "success")
+                if (showToast) showToast(mode === 'failed' ? "Retry of Failed Comics Triggered" : "Database Update Triggered", "success")
                 if (callback) callback()
             } else if (res) {
                 throw new Error("Update failed")
@@ -46,6 +78,24 @@ export function useAdmin(showToast) {
         }
     }
 
+    const retryFailed = (callback) => updateDB(callback, 'failed')
+
+    const reindexDB = async () => {
+        adminLoading.value = true
+        try {
+            const res = await apiCall('/api/db/reindex', { method: 'POST' })
+            if (res && res.ok) {
+                if (showToast) showToast("Reindex Triggered", "success")
+            } else if (res) {
+                throw new Error("Reindex failed")
+            }
+        } catch (e) {
+            if (showToast) showToast(`Error: ${e.message}`, "error")
+        } finally {
+            adminLoading.value = false
+        }
+    }
+
     const dropDB = async (callback) => {
         if (!confirm("Are you sure? This will delete all data.")) return
         adminLoading.value = true
@@ -68,5 +118,5 @@ export function useAdmin(showToast) {
         checkAuth()
     })
 
-    return { isAdmin, adminLoading, checkAuth, logout, updateDB, dropDB }
+    return { isAdmin, adminLoading, checkAuth, logout, updateDB, retryFailed, reindexDB, dropDB }
 }
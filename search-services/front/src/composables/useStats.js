@@ -1,22 +1,27 @@
-import { ref, onMounted, onUnmounted } from 'vue'
+import { ref, computed, watch, onMounted, onUnmounted } from 'vue'
+import { useUpdateEvents } from './useUpdateEvents'
 
 export function useStats() {
     const stats = ref(null)
-    const status = ref('unknown')
+    const { progress } = useUpdateEvents()
+    const status = computed(() => {
+        if (!progress.value) return 'unknown'
+        return progress.value.state === 'running' ? 'running' : 'idle'
+    })
     let intervalId = null
 
     const fetchStats = async () => {
         try {
-            const res1 = await fetch('/api/db/stats')
-            if (res1.ok) stats.value = await res1.json()
-            const res2 = await fetch('/api/db/status')
-            if (res2.ok) {
-                const d = await res2.json()
-                status.value = d.status
-            }
+            const res = await fetch('/api/db/stats')
+            if (res.ok) stats.value = await res.json()
         } catch (_) { }
     }
 
+    // refresh counters as soon as an update is over
+    watch(() => progress.value?.finished_at, (finished) => {
+        if (finished) fetchStats()
+    })
+
     onMounted(() => {
         fetchStats()
         intervalId = setInterval(fetchStats, 5000)
@@ -0,0 +1,37 @@
+import { ref, onMounted, onUnmounted } from 'vue'
+
+// One EventSource is shared by all components using it.
+const progress = ref(null)
+const lastEvent = ref(null)
+let source = null
+let users = 0
+
+const eventTypes = ['progress', 'started', 'fetched', 'failed', 'saved', 'skipped', 'finished']
+
+const connect = () => {
+    source = new EventSource('/api/db/update/events')
+    for (const type of eventTypes) {
+        source.addEventListener(type, (e) => {
+            try {
+                const event = JSON.parse(e.data)
+                progress.value = event.progress
+                lastEvent.value = event
+            } catch (_) { }
+        })
+    }
+}
+
+export function useUpdateEvents() {
+    onMounted(() => {
+        if (users++ === 0) connect()
+    })
+
+    onUnmounted(() => {
+        if (--users === 0 && source) {
+            source.close()
+            source = null
+        }
+    })
+
+    return { progress, lastEvent }
+}
@@ -0,0 +1,66 @@
+// Package imagehash computes perceptual image hashes, so that the same
+// picture scaled, recompressed or slightly edited gets a close hash.
+package imagehash
+
+import (
+	"image"
+	"math/bits"
+)
+
+// dHash compares each cell of a 9x8 grayscale thumbnail with its right
+// neighbour, giving 64 bits.
+const (
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+// DHash returns the difference hash of img.
+func DHash(img image.Image) uint64 {
+	gray := thumbnail(img)
+
+	var hash uint64
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth-1; x++ {
+			hash <<= 1
+			if gray[y][x] < gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// Distance is the number of differing bits of two hashes, 0 for the
+// same picture and up to 64.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// thumbnail scales img down to hashWidth x hashHeight averaging the
+// luminance of every pixel falling into a cell.
+func thumbnail(img image.Image) [hashHeight][hashWidth]float64 {
+	var sum [hashHeight][hashWidth]float64
+	var count [hashHeight][hashWidth]int
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		cy := (y - b.Min.Y) * hashHeight / h
+		for x := b.Min.X; x < b.Max.X; x++ {
+			cx := (x - b.Min.X) * hashWidth / w
+			r, g, bl, _ := img.At(x, y).RGBA()
+			sum[cy][cx] += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+			count[cy][cx]++
+		}
+	}
+
+	// cells of images smaller than the thumbnail may stay empty
+	for y := range sum {
+		for x := range sum[y] {
+			if count[y][x] > 0 {
+				sum[y][x] /= float64(count[y][x])
+			}
+		}
+	}
+	return sum
+}
@@ -0,0 +1,51 @@
+package imagehash_test
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"yadro.com/course/imagehash"
+)
+
+// picture draws the same scene at any size.
+func picture(w, h int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			fx, fy := float64(x)/float64(w), float64(y)/float64(h)
+			v := 127 + 120*math.Sin(7*fx+3*fy)*math.Cos(5*fy-2*fx)
+			img.SetGray(x, y, color.Gray{Y: uint8(v)})
+		}
+	}
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	hash := imagehash.DHash(picture(90, 80))
+	assert.Equal(t, hash, imagehash.DHash(picture(90, 80)))
+
+	scaled := imagehash.DHash(picture(450, 400))
+	assert.LessOrEqual(t, imagehash.Distance(hash, scaled), 4)
+
+	flipped := image.NewGray(image.Rect(0, 0, 90, 80))
+	src := picture(90, 80)
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 90; x++ {
+			flipped.Set(89-x, y, src.At(x, y))
+		}
+	}
+	assert.Greater(t, imagehash.Distance(hash, imagehash.DHash(flipped)), 16)
+}
+
+func TestDHash_Tiny(t *testing.T) {
+	assert.NotPanics(t, func() { imagehash.DHash(picture(1, 1)) })
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, imagehash.Distance(0xff, 0xff))
+	assert.Equal(t, 64, imagehash.Distance(0, ^uint64(0)))
+	assert.Equal(t, 2, imagehash.Distance(0b101, 0b000))
+}
@@ -10,6 +10,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -23,9 +24,12 @@ const (
 )
 
 type SearchRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
-	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Phrase string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	Limit  int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// filters, empty ones do not restrict
+	Tag           string `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	Collection    string `protobuf:"bytes,4,opt,name=collection,proto3" json:"collection,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,6 +78,20 @@ func (x *SearchRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *SearchRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
 type SearchResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Comics        []*Comic               `protobuf:"bytes,1,rep,name=comics,proto3" json:"comics,omitempty"`
@@ -178,24 +196,732 @@ func (x *Comic) GetUrl() string {
 	return ""
 }
 
+type SimilarRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimilarRequest) Reset() {
+	*x = SimilarRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimilarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilarRequest) ProtoMessage() {}
+
+func (x *SimilarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimilarRequest.ProtoReflect.Descriptor instead.
+func (*SimilarRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SimilarRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SimilarRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type MatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Image         []byte                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchRequest) Reset() {
+	*x = MatchRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchRequest) ProtoMessage() {}
+
+func (x *MatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchRequest.ProtoReflect.Descriptor instead.
+func (*MatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MatchRequest) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *MatchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SimilarComic struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Distance      int32                  `protobuf:"varint,3,opt,name=distance,proto3" json:"distance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimilarComic) Reset() {
+	*x = SimilarComic{}
+	mi := &file_proto_search_search_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimilarComic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilarComic) ProtoMessage() {}
+
+func (x *SimilarComic) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimilarComic.ProtoReflect.Descriptor instead.
+func (*SimilarComic) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SimilarComic) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SimilarComic) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *SimilarComic) GetDistance() int32 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+type SimilarResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Comics        []*SimilarComic        `protobuf:"bytes,1,rep,name=comics,proto3" json:"comics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimilarResponse) Reset() {
+	*x = SimilarResponse{}
+	mi := &file_proto_search_search_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimilarResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilarResponse) ProtoMessage() {}
+
+func (x *SimilarResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimilarResponse.ProtoReflect.Descriptor instead.
+func (*SimilarResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SimilarResponse) GetComics() []*SimilarComic {
+	if x != nil {
+		return x.Comics
+	}
+	return nil
+}
+
+type ComicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComicRequest) Reset() {
+	*x = ComicRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicRequest) ProtoMessage() {}
+
+func (x *ComicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicRequest.ProtoReflect.Descriptor instead.
+func (*ComicRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ComicRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type TagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagRequest) Reset() {
+	*x = TagRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagRequest) ProtoMessage() {}
+
+func (x *TagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagRequest.ProtoReflect.Descriptor instead.
+func (*TagRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TagRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TagRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type TagCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Comics        int64                  `protobuf:"varint,2,opt,name=comics,proto3" json:"comics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagCount) Reset() {
+	*x = TagCount{}
+	mi := &file_proto_search_search_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagCount) ProtoMessage() {}
+
+func (x *TagCount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagCount.ProtoReflect.Descriptor instead.
+func (*TagCount) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TagCount) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *TagCount) GetComics() int64 {
+	if x != nil {
+		return x.Comics
+	}
+	return 0
+}
+
+type TagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []*TagCount            `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagsResponse) Reset() {
+	*x = TagsResponse{}
+	mi := &file_proto_search_search_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagsResponse) ProtoMessage() {}
+
+func (x *TagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagsResponse.ProtoReflect.Descriptor instead.
+func (*TagsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TagsResponse) GetTags() []*TagCount {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ComicTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComicTagsResponse) Reset() {
+	*x = ComicTagsResponse{}
+	mi := &file_proto_search_search_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicTagsResponse) ProtoMessage() {}
+
+func (x *ComicTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicTagsResponse.ProtoReflect.Descriptor instead.
+func (*ComicTagsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ComicTagsResponse) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type Collection struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// set by the service
+	Comics        []int64                `protobuf:"varint,3,rep,packed,name=comics,proto3" json:"comics,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Collection) Reset() {
+	*x = Collection{}
+	mi := &file_proto_search_search_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Collection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Collection) ProtoMessage() {}
+
+func (x *Collection) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Collection.ProtoReflect.Descriptor instead.
+func (*Collection) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Collection) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Collection) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Collection) GetComics() []int64 {
+	if x != nil {
+		return x.Comics
+	}
+	return nil
+}
+
+func (x *Collection) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CollectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectionRequest) Reset() {
+	*x = CollectionRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionRequest) ProtoMessage() {}
+
+func (x *CollectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionRequest.ProtoReflect.Descriptor instead.
+func (*CollectionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CollectionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CollectionComicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectionComicRequest) Reset() {
+	*x = CollectionComicRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionComicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionComicRequest) ProtoMessage() {}
+
+func (x *CollectionComicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionComicRequest.ProtoReflect.Descriptor instead.
+func (*CollectionComicRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CollectionComicRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CollectionComicRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CollectionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collections   []*Collection          `protobuf:"bytes,1,rep,name=collections,proto3" json:"collections,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectionsResponse) Reset() {
+	*x = CollectionsResponse{}
+	mi := &file_proto_search_search_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionsResponse) ProtoMessage() {}
+
+func (x *CollectionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionsResponse.ProtoReflect.Descriptor instead.
+func (*CollectionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CollectionsResponse) GetCollections() []*Collection {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
 var File_proto_search_search_proto protoreflect.FileDescriptor
 
 const file_proto_search_search_proto_rawDesc = "" +
 	"\n" +
-	"\x19proto/search/search.proto\x12\x06search\x1a\x1bgoogle/protobuf/empty.proto\"=\n" +
+	"\x19proto/search/search.proto\x12\x06search\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"o\n" +
 	"\rSearchRequest\x12\x16\n" +
 	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\x05R\x05limit\"M\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x04 \x01(\tR\n" +
+	"collection\"M\n" +
 	"\x0eSearchResponse\x12%\n" +
 	"\x06comics\x18\x01 \x03(\v2\r.search.ComicR\x06comics\x12\x14\n" +
 	"\x05total\x18\x02 \x01(\x03R\x05total\")\n" +
 	"\x05Comic\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x10\n" +
-	"\x03url\x18\x02 \x01(\tR\x03url2\xb3\x01\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\"6\n" +
+	"\x0eSimilarRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\":\n" +
+	"\fMatchRequest\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"L\n" +
+	"\fSimilarComic\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x1a\n" +
+	"\bdistance\x18\x03 \x01(\x05R\bdistance\"?\n" +
+	"\x0fSimilarResponse\x12,\n" +
+	"\x06comics\x18\x01 \x03(\v2\x14.search.SimilarComicR\x06comics\"\x1e\n" +
+	"\fComicRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\".\n" +
+	"\n" +
+	"TagRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\"4\n" +
+	"\bTagCount\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x16\n" +
+	"\x06comics\x18\x02 \x01(\x03R\x06comics\"4\n" +
+	"\fTagsResponse\x12$\n" +
+	"\x04tags\x18\x01 \x03(\v2\x10.search.TagCountR\x04tags\"'\n" +
+	"\x11ComicTagsResponse\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\"\x95\x01\n" +
+	"\n" +
+	"Collection\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06comics\x18\x03 \x03(\x03R\x06comics\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"'\n" +
+	"\x11CollectionRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"<\n" +
+	"\x16CollectionComicRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"K\n" +
+	"\x13CollectionsResponse\x124\n" +
+	"\vcollections\x18\x01 \x03(\v2\x12.search.CollectionR\vcollections2\xae\a\n" +
 	"\x06Search\x127\n" +
 	"\x06Search\x12\x15.search.SearchRequest\x1a\x16.search.SearchResponse\x128\n" +
 	"\aISearch\x12\x15.search.SearchRequest\x1a\x16.search.SearchResponse\x126\n" +
-	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.EmptyB\x1fZ\x1dyadro.com/course/proto/searchb\x06proto3"
+	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x12:\n" +
+	"\aSimilar\x12\x16.search.SimilarRequest\x1a\x17.search.SimilarResponse\x126\n" +
+	"\x05Match\x12\x14.search.MatchRequest\x1a\x17.search.SimilarResponse\x124\n" +
+	"\x04Tags\x12\x16.google.protobuf.Empty\x1a\x14.search.TagsResponse\x12<\n" +
+	"\tComicTags\x12\x14.search.ComicRequest\x1a\x19.search.ComicTagsResponse\x124\n" +
+	"\x06AddTag\x12\x12.search.TagRequest\x1a\x16.google.protobuf.Empty\x127\n" +
+	"\tRemoveTag\x12\x12.search.TagRequest\x1a\x16.google.protobuf.Empty\x12B\n" +
+	"\vCollections\x12\x16.google.protobuf.Empty\x1a\x1b.search.CollectionsResponse\x12>\n" +
+	"\rGetCollection\x12\x19.search.CollectionRequest\x1a\x12.search.Collection\x12<\n" +
+	"\x0eSaveCollection\x12\x12.search.Collection\x1a\x16.google.protobuf.Empty\x12E\n" +
+	"\x10DeleteCollection\x12\x19.search.CollectionRequest\x1a\x16.google.protobuf.Empty\x12I\n" +
+	"\x0fAddToCollection\x12\x1e.search.CollectionComicRequest\x1a\x16.google.protobuf.Empty\x12N\n" +
+	"\x14RemoveFromCollection\x12\x1e.search.CollectionComicRequest\x1a\x16.google.protobuf.EmptyB\x1fZ\x1dyadro.com/course/proto/searchb\x06proto3"
 
 var (
 	file_proto_search_search_proto_rawDescOnce sync.Once
@@ -209,26 +935,68 @@ func file_proto_search_search_proto_rawDescGZIP() []byte {
 	return file_proto_search_search_proto_rawDescData
 }
 
-var file_proto_search_search_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_search_search_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_proto_search_search_proto_goTypes = []any{
-	(*SearchRequest)(nil),  // 0: search.SearchRequest
-	(*SearchResponse)(nil), // 1: search.SearchResponse
-	(*Comic)(nil),          // 2: search.Comic
-	(*emptypb.Empty)(nil),  // 3: google.protobuf.Empty
+	(*SearchRequest)(nil),          // 0: search.SearchRequest
+	(*SearchResponse)(nil),         // 1: search.SearchResponse
+	(*Comic)(nil),                  // 2: search.Comic
+	(*SimilarRequest)(nil),         // 3: search.SimilarRequest
+	(*MatchRequest)(nil),           // 4: search.MatchRequest
+	(*SimilarComic)(nil),           // 5: search.SimilarComic
+	(*SimilarResponse)(nil),        // 6: search.SimilarResponse
+	(*ComicRequest)(nil),           // 7: search.ComicRequest
+	(*TagRequest)(nil),             // 8: search.TagRequest
+	(*TagCount)(nil),               // 9: search.TagCount
+	(*TagsResponse)(nil),           // 10: search.TagsResponse
+	(*ComicTagsResponse)(nil),      // 11: search.ComicTagsResponse
+	(*Collection)(nil),             // 12: search.Collection
+	(*CollectionRequest)(nil),      // 13: search.CollectionRequest
+	(*CollectionComicRequest)(nil), // 14: search.CollectionComicRequest
+	(*CollectionsResponse)(nil),    // 15: search.CollectionsResponse
+	(*timestamppb.Timestamp)(nil),  // 16: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),          // 17: google.protobuf.Empty
 }
 var file_proto_search_search_proto_depIdxs = []int32{
-	2, // 0: search.SearchResponse.comics:type_name -> search.Comic
-	0, // 1: search.Search.Search:input_type -> search.SearchRequest
-	0, // 2: search.Search.ISearch:input_type -> search.SearchRequest
-	3, // 3: search.Search.Ping:input_type -> google.protobuf.Empty
-	1, // 4: search.Search.Search:output_type -> search.SearchResponse
-	1, // 5: search.Search.ISearch:output_type -> search.SearchResponse
-	3, // 6: search.Search.Ping:output_type -> google.protobuf.Empty
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2,  // 0: search.SearchResponse.comics:type_name -> search.Comic
+	5,  // 1: search.SimilarResponse.comics:type_name -> search.SimilarComic
+	9,  // 2: search.TagsResponse.tags:type_name -> search.TagCount
+	16, // 3: search.Collection.created_at:type_name -> google.protobuf.Timestamp
+	12, // 4: search.CollectionsResponse.collections:type_name -> search.Collection
+	0,  // 5: search.Search.Search:input_type -> search.SearchRequest
+	0,  // 6: search.Search.ISearch:input_type -> search.SearchRequest
+	17, // 7: search.Search.Ping:input_type -> google.protobuf.Empty
+	3,  // 8: search.Search.Similar:input_type -> search.SimilarRequest
+	4,  // 9: search.Search.Match:input_type -> search.MatchRequest
+	17, // 10: search.Search.Tags:input_type -> google.protobuf.Empty
+	7,  // 11: search.Search.ComicTags:input_type -> search.ComicRequest
+	8,  // 12: search.Search.AddTag:input_type -> search.TagRequest
+	8,  // 13: search.Search.RemoveTag:input_type -> search.TagRequest
+	17, // 14: search.Search.Collections:input_type -> google.protobuf.Empty
+	13, // 15: search.Search.GetCollection:input_type -> search.CollectionRequest
+	12, // 16: search.Search.SaveCollection:input_type -> search.Collection
+	13, // 17: search.Search.DeleteCollection:input_type -> search.CollectionRequest
+	14, // 18: search.Search.AddToCollection:input_type -> search.CollectionComicRequest
+	14, // 19: search.Search.RemoveFromCollection:input_type -> search.CollectionComicRequest
+	1,  // 20: search.Search.Search:output_type -> search.SearchResponse
+	1,  // 21: search.Search.ISearch:output_type -> search.SearchResponse
+	17, // 22: search.Search.Ping:output_type -> google.protobuf.Empty
+	6,  // 23: search.Search.Similar:output_type -> search.SimilarResponse
+	6,  // 24: search.Search.Match:output_type -> search.SimilarResponse
+	10, // 25: search.Search.Tags:output_type -> search.TagsResponse
+	11, // 26: search.Search.ComicTags:output_type -> search.ComicTagsResponse
+	17, // 27: search.Search.AddTag:output_type -> google.protobuf.Empty
+	17, // 28: search.Search.RemoveTag:output_type -> google.protobuf.Empty
+	15, // 29: search.Search.Collections:output_type -> search.CollectionsResponse
+	12, // 30: search.Search.GetCollection:output_type -> search.Collection
+	17, // 31: search.Search.SaveCollection:output_type -> google.protobuf.Empty
+	17, // 32: search.Search.DeleteCollection:output_type -> google.protobuf.Empty
+	17, // 33: search.Search.AddToCollection:output_type -> google.protobuf.Empty
+	17, // 34: search.Search.RemoveFromCollection:output_type -> google.protobuf.Empty
+	20, // [20:35] is the sub-list for method output_type
+	5,  // [5:20] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_proto_search_search_proto_init() }
@@ -242,7 +1010,7 @@ func file_proto_search_search_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_search_search_proto_rawDesc), len(file_proto_search_search_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
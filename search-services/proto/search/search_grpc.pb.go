@@ -20,9 +20,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Search_Search_FullMethodName  = "/search.Search/Search"
-	Search_ISearch_FullMethodName = "/search.Search/ISearch"
-	Search_Ping_FullMethodName    = "/search.Search/Ping"
+	Search_Search_FullMethodName               = "/search.Search/Search"
+	Search_ISearch_FullMethodName              = "/search.Search/ISearch"
+	Search_Ping_FullMethodName                 = "/search.Search/Ping"
+	Search_Similar_FullMethodName              = "/search.Search/Similar"
+	Search_Match_FullMethodName                = "/search.Search/Match"
+	Search_Tags_FullMethodName                 = "/search.Search/Tags"
+	Search_ComicTags_FullMethodName            = "/search.Search/ComicTags"
+	Search_AddTag_FullMethodName               = "/search.Search/AddTag"
+	Search_RemoveTag_FullMethodName            = "/search.Search/RemoveTag"
+	Search_Collections_FullMethodName          = "/search.Search/Collections"
+	Search_GetCollection_FullMethodName        = "/search.Search/GetCollection"
+	Search_SaveCollection_FullMethodName       = "/search.Search/SaveCollection"
+	Search_DeleteCollection_FullMethodName     = "/search.Search/DeleteCollection"
+	Search_AddToCollection_FullMethodName      = "/search.Search/AddToCollection"
+	Search_RemoveFromCollection_FullMethodName = "/search.Search/RemoveFromCollection"
 )
 
 // SearchClient is the client API for Search service.
@@ -32,6 +44,22 @@ type SearchClient interface {
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	ISearch(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Finds comics with images looking like the image of a comic
+	Similar(ctx context.Context, in *SimilarRequest, opts ...grpc.CallOption) (*SimilarResponse, error)
+	// Finds comics with images looking like an uploaded picture
+	Match(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*SimilarResponse, error)
+	// Lists all tags, most used first
+	Tags(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TagsResponse, error)
+	ComicTags(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*ComicTagsResponse, error)
+	AddTag(ctx context.Context, in *TagRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RemoveTag(ctx context.Context, in *TagRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Collections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CollectionsResponse, error)
+	GetCollection(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*Collection, error)
+	// Creates a collection or changes its description
+	SaveCollection(ctx context.Context, in *Collection, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteCollection(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	AddToCollection(ctx context.Context, in *CollectionComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RemoveFromCollection(ctx context.Context, in *CollectionComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type searchClient struct {
@@ -72,6 +100,126 @@ func (c *searchClient) Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc
 	return out, nil
 }
 
+func (c *searchClient) Similar(ctx context.Context, in *SimilarRequest, opts ...grpc.CallOption) (*SimilarResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimilarResponse)
+	err := c.cc.Invoke(ctx, Search_Similar_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) Match(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*SimilarResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimilarResponse)
+	err := c.cc.Invoke(ctx, Search_Match_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) Tags(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TagsResponse)
+	err := c.cc.Invoke(ctx, Search_Tags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) ComicTags(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*ComicTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ComicTagsResponse)
+	err := c.cc.Invoke(ctx, Search_ComicTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) AddTag(ctx context.Context, in *TagRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Search_AddTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) RemoveTag(ctx context.Context, in *TagRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Search_RemoveTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) Collections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CollectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectionsResponse)
+	err := c.cc.Invoke(ctx, Search_Collections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) GetCollection(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*Collection, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Collection)
+	err := c.cc.Invoke(ctx, Search_GetCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) SaveCollection(ctx context.Context, in *Collection, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Search_SaveCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) DeleteCollection(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Search_DeleteCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) AddToCollection(ctx context.Context, in *CollectionComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Search_AddToCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) RemoveFromCollection(ctx context.Context, in *CollectionComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Search_RemoveFromCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SearchServer is the server API for Search service.
 // All implementations must embed UnimplementedSearchServer
 // for forward compatibility.
@@ -79,6 +227,22 @@ type SearchServer interface {
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	ISearch(context.Context, *SearchRequest) (*SearchResponse, error)
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Finds comics with images looking like the image of a comic
+	Similar(context.Context, *SimilarRequest) (*SimilarResponse, error)
+	// Finds comics with images looking like an uploaded picture
+	Match(context.Context, *MatchRequest) (*SimilarResponse, error)
+	// Lists all tags, most used first
+	Tags(context.Context, *emptypb.Empty) (*TagsResponse, error)
+	ComicTags(context.Context, *ComicRequest) (*ComicTagsResponse, error)
+	AddTag(context.Context, *TagRequest) (*emptypb.Empty, error)
+	RemoveTag(context.Context, *TagRequest) (*emptypb.Empty, error)
+	Collections(context.Context, *emptypb.Empty) (*CollectionsResponse, error)
+	GetCollection(context.Context, *CollectionRequest) (*Collection, error)
+	// Creates a collection or changes its description
+	SaveCollection(context.Context, *Collection) (*emptypb.Empty, error)
+	DeleteCollection(context.Context, *CollectionRequest) (*emptypb.Empty, error)
+	AddToCollection(context.Context, *CollectionComicRequest) (*emptypb.Empty, error)
+	RemoveFromCollection(context.Context, *CollectionComicRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedSearchServer()
 }
 
@@ -98,6 +262,42 @@ func (UnimplementedSearchServer) ISearch(context.Context, *SearchRequest) (*Sear
 func (UnimplementedSearchServer) Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method Ping not implemented")
 }
+func (UnimplementedSearchServer) Similar(context.Context, *SimilarRequest) (*SimilarResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Similar not implemented")
+}
+func (UnimplementedSearchServer) Match(context.Context, *MatchRequest) (*SimilarResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Match not implemented")
+}
+func (UnimplementedSearchServer) Tags(context.Context, *emptypb.Empty) (*TagsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Tags not implemented")
+}
+func (UnimplementedSearchServer) ComicTags(context.Context, *ComicRequest) (*ComicTagsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ComicTags not implemented")
+}
+func (UnimplementedSearchServer) AddTag(context.Context, *TagRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddTag not implemented")
+}
+func (UnimplementedSearchServer) RemoveTag(context.Context, *TagRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveTag not implemented")
+}
+func (UnimplementedSearchServer) Collections(context.Context, *emptypb.Empty) (*CollectionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Collections not implemented")
+}
+func (UnimplementedSearchServer) GetCollection(context.Context, *CollectionRequest) (*Collection, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCollection not implemented")
+}
+func (UnimplementedSearchServer) SaveCollection(context.Context, *Collection) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method SaveCollection not implemented")
+}
+func (UnimplementedSearchServer) DeleteCollection(context.Context, *CollectionRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteCollection not implemented")
+}
+func (UnimplementedSearchServer) AddToCollection(context.Context, *CollectionComicRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddToCollection not implemented")
+}
+func (UnimplementedSearchServer) RemoveFromCollection(context.Context, *CollectionComicRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveFromCollection not implemented")
+}
 func (UnimplementedSearchServer) mustEmbedUnimplementedSearchServer() {}
 func (UnimplementedSearchServer) testEmbeddedByValue()                {}
 
@@ -173,6 +373,222 @@ func _Search_Ping_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Search_Similar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimilarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Similar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Similar_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Similar(ctx, req.(*SimilarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_Match_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Match(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Match_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Match(ctx, req.(*MatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_Tags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Tags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Tags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Tags(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_ComicTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).ComicTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_ComicTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).ComicTags(ctx, req.(*ComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_AddTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).AddTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_AddTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).AddTag(ctx, req.(*TagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_RemoveTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).RemoveTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_RemoveTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).RemoveTag(ctx, req.(*TagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_Collections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Collections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Collections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Collections(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_GetCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).GetCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_GetCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).GetCollection(ctx, req.(*CollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_SaveCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Collection)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).SaveCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_SaveCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).SaveCollection(ctx, req.(*Collection))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_DeleteCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).DeleteCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_DeleteCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).DeleteCollection(ctx, req.(*CollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_AddToCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).AddToCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_AddToCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).AddToCollection(ctx, req.(*CollectionComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_RemoveFromCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).RemoveFromCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_RemoveFromCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).RemoveFromCollection(ctx, req.(*CollectionComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Search_ServiceDesc is the grpc.ServiceDesc for Search service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -192,6 +608,54 @@ var Search_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Ping",
 			Handler:    _Search_Ping_Handler,
 		},
+		{
+			MethodName: "Similar",
+			Handler:    _Search_Similar_Handler,
+		},
+		{
+			MethodName: "Match",
+			Handler:    _Search_Match_Handler,
+		},
+		{
+			MethodName: "Tags",
+			Handler:    _Search_Tags_Handler,
+		},
+		{
+			MethodName: "ComicTags",
+			Handler:    _Search_ComicTags_Handler,
+		},
+		{
+			MethodName: "AddTag",
+			Handler:    _Search_AddTag_Handler,
+		},
+		{
+			MethodName: "RemoveTag",
+			Handler:    _Search_RemoveTag_Handler,
+		},
+		{
+			MethodName: "Collections",
+			Handler:    _Search_Collections_Handler,
+		},
+		{
+			MethodName: "GetCollection",
+			Handler:    _Search_GetCollection_Handler,
+		},
+		{
+			MethodName: "SaveCollection",
+			Handler:    _Search_SaveCollection_Handler,
+		},
+		{
+			MethodName: "DeleteCollection",
+			Handler:    _Search_DeleteCollection_Handler,
+		},
+		{
+			MethodName: "AddToCollection",
+			Handler:    _Search_AddToCollection_Handler,
+		},
+		{
+			MethodName: "RemoveFromCollection",
+			Handler:    _Search_RemoveFromCollection_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/search/search.proto",
@@ -9,7 +9,9 @@ package update
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -71,14 +73,248 @@ func (Status) EnumDescriptor() ([]byte, []int) {
 	return file_proto_update_update_proto_rawDescGZIP(), []int{0}
 }
 
+type UpdateMode int32
+
+const (
+	UpdateMode_UPDATE_MODE_UNSPECIFIED UpdateMode = 0
+	// fetch comics missing in the database
+	UpdateMode_UPDATE_MODE_NEW UpdateMode = 1
+	// fetch only comics the previous updates gave up on
+	UpdateMode_UPDATE_MODE_FAILED UpdateMode = 2
+	// refetch stored comics, renormalize those that changed
+	UpdateMode_UPDATE_MODE_REFRESH UpdateMode = 3
+	// normalize stored comics again, set by Reindex
+	UpdateMode_UPDATE_MODE_REINDEX UpdateMode = 4
+)
+
+// Enum value maps for UpdateMode.
+var (
+	UpdateMode_name = map[int32]string{
+		0: "UPDATE_MODE_UNSPECIFIED",
+		1: "UPDATE_MODE_NEW",
+		2: "UPDATE_MODE_FAILED",
+		3: "UPDATE_MODE_REFRESH",
+		4: "UPDATE_MODE_REINDEX",
+	}
+	UpdateMode_value = map[string]int32{
+		"UPDATE_MODE_UNSPECIFIED": 0,
+		"UPDATE_MODE_NEW":         1,
+		"UPDATE_MODE_FAILED":      2,
+		"UPDATE_MODE_REFRESH":     3,
+		"UPDATE_MODE_REINDEX":     4,
+	}
+)
+
+func (x UpdateMode) Enum() *UpdateMode {
+	p := new(UpdateMode)
+	*p = x
+	return p
+}
+
+func (x UpdateMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UpdateMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_update_update_proto_enumTypes[1].Descriptor()
+}
+
+func (UpdateMode) Type() protoreflect.EnumType {
+	return &file_proto_update_update_proto_enumTypes[1]
+}
+
+func (x UpdateMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UpdateMode.Descriptor instead.
+func (UpdateMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{1}
+}
+
+type JobState int32
+
+const (
+	JobState_JOB_STATE_UNSPECIFIED JobState = 0
+	JobState_JOB_STATE_RUNNING     JobState = 1
+	JobState_JOB_STATE_DONE        JobState = 2
+	JobState_JOB_STATE_FAILED      JobState = 3
+	JobState_JOB_STATE_CANCELED    JobState = 4
+)
+
+// Enum value maps for JobState.
+var (
+	JobState_name = map[int32]string{
+		0: "JOB_STATE_UNSPECIFIED",
+		1: "JOB_STATE_RUNNING",
+		2: "JOB_STATE_DONE",
+		3: "JOB_STATE_FAILED",
+		4: "JOB_STATE_CANCELED",
+	}
+	JobState_value = map[string]int32{
+		"JOB_STATE_UNSPECIFIED": 0,
+		"JOB_STATE_RUNNING":     1,
+		"JOB_STATE_DONE":        2,
+		"JOB_STATE_FAILED":      3,
+		"JOB_STATE_CANCELED":    4,
+	}
+)
+
+func (x JobState) Enum() *JobState {
+	p := new(JobState)
+	*p = x
+	return p
+}
+
+func (x JobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_update_update_proto_enumTypes[2].Descriptor()
+}
+
+func (JobState) Type() protoreflect.EnumType {
+	return &file_proto_update_update_proto_enumTypes[2]
+}
+
+func (x JobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobState.Descriptor instead.
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{2}
+}
+
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED EventType = 0
+	// snapshot of the latest job sent first on every watch
+	EventType_EVENT_TYPE_PROGRESS EventType = 1
+	EventType_EVENT_TYPE_STARTED  EventType = 2
+	EventType_EVENT_TYPE_FETCHED  EventType = 3
+	EventType_EVENT_TYPE_FAILED   EventType = 4
+	EventType_EVENT_TYPE_SAVED    EventType = 5
+	EventType_EVENT_TYPE_FINISHED EventType = 6
+	// refetched comic did not change
+	EventType_EVENT_TYPE_SKIPPED EventType = 7
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_PROGRESS",
+		2: "EVENT_TYPE_STARTED",
+		3: "EVENT_TYPE_FETCHED",
+		4: "EVENT_TYPE_FAILED",
+		5: "EVENT_TYPE_SAVED",
+		6: "EVENT_TYPE_FINISHED",
+		7: "EVENT_TYPE_SKIPPED",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED": 0,
+		"EVENT_TYPE_PROGRESS":    1,
+		"EVENT_TYPE_STARTED":     2,
+		"EVENT_TYPE_FETCHED":     3,
+		"EVENT_TYPE_FAILED":      4,
+		"EVENT_TYPE_SAVED":       5,
+		"EVENT_TYPE_FINISHED":    6,
+		"EVENT_TYPE_SKIPPED":     7,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_update_update_proto_enumTypes[3].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_proto_update_update_proto_enumTypes[3]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{3}
+}
+
+type Trigger int32
+
+const (
+	Trigger_TRIGGER_UNSPECIFIED Trigger = 0
+	Trigger_TRIGGER_MANUAL      Trigger = 1
+	Trigger_TRIGGER_SCHEDULE    Trigger = 2
+)
+
+// Enum value maps for Trigger.
+var (
+	Trigger_name = map[int32]string{
+		0: "TRIGGER_UNSPECIFIED",
+		1: "TRIGGER_MANUAL",
+		2: "TRIGGER_SCHEDULE",
+	}
+	Trigger_value = map[string]int32{
+		"TRIGGER_UNSPECIFIED": 0,
+		"TRIGGER_MANUAL":      1,
+		"TRIGGER_SCHEDULE":    2,
+	}
+)
+
+func (x Trigger) Enum() *Trigger {
+	p := new(Trigger)
+	*p = x
+	return p
+}
+
+func (x Trigger) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Trigger) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_update_update_proto_enumTypes[4].Descriptor()
+}
+
+func (Trigger) Type() protoreflect.EnumType {
+	return &file_proto_update_update_proto_enumTypes[4]
+}
+
+func (x Trigger) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Trigger.Descriptor instead.
+func (Trigger) EnumDescriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{4}
+}
+
 type StatsReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	WordsTotal    int64                  `protobuf:"varint,1,opt,name=words_total,json=wordsTotal,proto3" json:"words_total,omitempty"`
-	WordsUnique   int64                  `protobuf:"varint,2,opt,name=words_unique,json=wordsUnique,proto3" json:"words_unique,omitempty"`
-	ComicsTotal   int64                  `protobuf:"varint,3,opt,name=comics_total,json=comicsTotal,proto3" json:"comics_total,omitempty"`
-	ComicsFetched int64                  `protobuf:"varint,4,opt,name=comics_fetched,json=comicsFetched,proto3" json:"comics_fetched,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	WordsTotal      int64                  `protobuf:"varint,1,opt,name=words_total,json=wordsTotal,proto3" json:"words_total,omitempty"`
+	WordsUnique     int64                  `protobuf:"varint,2,opt,name=words_unique,json=wordsUnique,proto3" json:"words_unique,omitempty"`
+	ComicsTotal     int64                  `protobuf:"varint,3,opt,name=comics_total,json=comicsTotal,proto3" json:"comics_total,omitempty"`
+	ComicsFetched   int64                  `protobuf:"varint,4,opt,name=comics_fetched,json=comicsFetched,proto3" json:"comics_fetched,omitempty"`
+	LatestCheckedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=latest_checked_at,json=latestCheckedAt,proto3" json:"latest_checked_at,omitempty"`
+	// comics with an empty field
+	ComicsNoTitle      int64 `protobuf:"varint,6,opt,name=comics_no_title,json=comicsNoTitle,proto3" json:"comics_no_title,omitempty"`
+	ComicsNoAlt        int64 `protobuf:"varint,7,opt,name=comics_no_alt,json=comicsNoAlt,proto3" json:"comics_no_alt,omitempty"`
+	ComicsNoTranscript int64 `protobuf:"varint,8,opt,name=comics_no_transcript,json=comicsNoTranscript,proto3" json:"comics_no_transcript,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *StatsReply) Reset() {
@@ -139,9 +375,39 @@ func (x *StatsReply) GetComicsFetched() int64 {
 	return 0
 }
 
+func (x *StatsReply) GetLatestCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LatestCheckedAt
+	}
+	return nil
+}
+
+func (x *StatsReply) GetComicsNoTitle() int64 {
+	if x != nil {
+		return x.ComicsNoTitle
+	}
+	return 0
+}
+
+func (x *StatsReply) GetComicsNoAlt() int64 {
+	if x != nil {
+		return x.ComicsNoAlt
+	}
+	return 0
+}
+
+func (x *StatsReply) GetComicsNoTranscript() int64 {
+	if x != nil {
+		return x.ComicsNoTranscript
+	}
+	return 0
+}
+
 type StatusReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        Status                 `protobuf:"varint,1,opt,name=status,proto3,enum=update.Status" json:"status,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Status Status                 `protobuf:"varint,1,opt,name=status,proto3,enum=update.Status" json:"status,omitempty"`
+	// next scheduled update, unset when scheduling is off or an update is running
+	NextRun       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -183,30 +449,1216 @@ func (x *StatusReply) GetStatus() Status {
 	return Status_STATUS_UNSPECIFIED
 }
 
-var File_proto_update_update_proto protoreflect.FileDescriptor
+func (x *StatusReply) GetNextRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRun
+	}
+	return nil
+}
 
-const file_proto_update_update_proto_rawDesc = "" +
-	"\n" +
-	"\x19proto/update/update.proto\x12\x06update\x1a\x1bgoogle/protobuf/empty.proto\"\x9a\x01\n" +
-	"\n" +
-	"StatsReply\x12\x1f\n" +
-	"\vwords_total\x18\x01 \x01(\x03R\n" +
-	"wordsTotal\x12!\n" +
-	"\fwords_unique\x18\x02 \x01(\x03R\vwordsUnique\x12!\n" +
-	"\fcomics_total\x18\x03 \x01(\x03R\vcomicsTotal\x12%\n" +
-	"\x0ecomics_fetched\x18\x04 \x01(\x03R\rcomicsFetched\"5\n" +
-	"\vStatusReply\x12&\n" +
-	"\x06status\x18\x01 \x01(\x0e2\x0e.update.StatusR\x06status*E\n" +
-	"\x06Status\x12\x16\n" +
-	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x0f\n" +
-	"\vSTATUS_IDLE\x10\x01\x12\x12\n" +
-	"\x0eSTATUS_RUNNING\x10\x022\xa8\x02\n" +
+type UpdateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Mode  UpdateMode             `protobuf:"varint,1,opt,name=mode,proto3,enum=update.UpdateMode" json:"mode,omitempty"`
+	// refresh filter, zero values do not restrict
+	FromId        int64                `protobuf:"varint,2,opt,name=from_id,json=fromId,proto3" json:"from_id,omitempty"`
+	ToId          int64                `protobuf:"varint,3,opt,name=to_id,json=toId,proto3" json:"to_id,omitempty"`
+	OlderThan     *durationpb.Duration `protobuf:"bytes,4,opt,name=older_than,json=olderThan,proto3" json:"older_than,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_proto_update_update_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UpdateRequest) GetMode() UpdateMode {
+	if x != nil {
+		return x.Mode
+	}
+	return UpdateMode_UPDATE_MODE_UNSPECIFIED
+}
+
+func (x *UpdateRequest) GetFromId() int64 {
+	if x != nil {
+		return x.FromId
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetToId() int64 {
+	if x != nil {
+		return x.ToId
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetOlderThan() *durationpb.Duration {
+	if x != nil {
+		return x.OlderThan
+	}
+	return nil
+}
+
+type UpdateReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// job_id is of the update that was already running
+	AlreadyRunning bool `protobuf:"varint,2,opt,name=already_running,json=alreadyRunning,proto3" json:"already_running,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateReply) Reset() {
+	*x = UpdateReply{}
+	mi := &file_proto_update_update_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReply) ProtoMessage() {}
+
+func (x *UpdateReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReply.ProtoReflect.Descriptor instead.
+func (*UpdateReply) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdateReply) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *UpdateReply) GetAlreadyRunning() bool {
+	if x != nil {
+		return x.AlreadyRunning
+	}
+	return false
+}
+
+type JobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobRequest) Reset() {
+	*x = JobRequest{}
+	mi := &file_proto_update_update_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobRequest) ProtoMessage() {}
+
+func (x *JobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobRequest.ProtoReflect.Descriptor instead.
+func (*JobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *JobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type ProgressReply struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	JobId    string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	State    JobState               `protobuf:"varint,2,opt,name=state,proto3,enum=update.JobState" json:"state,omitempty"`
+	Total    int64                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Fetched  int64                  `protobuf:"varint,4,opt,name=fetched,proto3" json:"fetched,omitempty"`
+	Failed   int64                  `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	InFlight int64                  `protobuf:"varint,6,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	// unset until the first comic is done
+	Eta           *durationpb.Duration   `protobuf:"bytes,7,opt,name=eta,proto3" json:"eta,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProgressReply) Reset() {
+	*x = ProgressReply{}
+	mi := &file_proto_update_update_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProgressReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressReply) ProtoMessage() {}
+
+func (x *ProgressReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressReply.ProtoReflect.Descriptor instead.
+func (*ProgressReply) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProgressReply) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *ProgressReply) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *ProgressReply) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ProgressReply) GetFetched() int64 {
+	if x != nil {
+		return x.Fetched
+	}
+	return 0
+}
+
+func (x *ProgressReply) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ProgressReply) GetInFlight() int64 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+func (x *ProgressReply) GetEta() *durationpb.Duration {
+	if x != nil {
+		return x.Eta
+	}
+	return nil
+}
+
+func (x *ProgressReply) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ProgressReply) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *ProgressReply) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          EventType              `protobuf:"varint,1,opt,name=type,proto3,enum=update.EventType" json:"type,omitempty"`
+	ComicId       int64                  `protobuf:"varint,2,opt,name=comic_id,json=comicId,proto3" json:"comic_id,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Progress      *ProgressReply         `protobuf:"bytes,4,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEvent) Reset() {
+	*x = UpdateEvent{}
+	mi := &file_proto_update_update_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEvent) ProtoMessage() {}
+
+func (x *UpdateEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEvent.ProtoReflect.Descriptor instead.
+func (*UpdateEvent) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateEvent) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *UpdateEvent) GetComicId() int64 {
+	if x != nil {
+		return x.ComicId
+	}
+	return 0
+}
+
+func (x *UpdateEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *UpdateEvent) GetProgress() *ProgressReply {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+type Run struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Trigger       Trigger                `protobuf:"varint,2,opt,name=trigger,proto3,enum=update.Trigger" json:"trigger,omitempty"`
+	Mode          UpdateMode             `protobuf:"varint,3,opt,name=mode,proto3,enum=update.UpdateMode" json:"mode,omitempty"`
+	State         JobState               `protobuf:"varint,4,opt,name=state,proto3,enum=update.JobState" json:"state,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Total         int64                  `protobuf:"varint,7,opt,name=total,proto3" json:"total,omitempty"`
+	Fetched       int64                  `protobuf:"varint,8,opt,name=fetched,proto3" json:"fetched,omitempty"`
+	Failed        int64                  `protobuf:"varint,9,opt,name=failed,proto3" json:"failed,omitempty"`
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Run) Reset() {
+	*x = Run{}
+	mi := &file_proto_update_update_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Run) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Run) ProtoMessage() {}
+
+func (x *Run) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Run.ProtoReflect.Descriptor instead.
+func (*Run) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Run) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *Run) GetTrigger() Trigger {
+	if x != nil {
+		return x.Trigger
+	}
+	return Trigger_TRIGGER_UNSPECIFIED
+}
+
+func (x *Run) GetMode() UpdateMode {
+	if x != nil {
+		return x.Mode
+	}
+	return UpdateMode_UPDATE_MODE_UNSPECIFIED
+}
+
+func (x *Run) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *Run) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Run) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *Run) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Run) GetFetched() int64 {
+	if x != nil {
+		return x.Fetched
+	}
+	return 0
+}
+
+func (x *Run) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *Run) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type FailedComic struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	ComicId int64                  `protobuf:"varint,1,opt,name=comic_id,json=comicId,proto3" json:"comic_id,omitempty"`
+	// job_id of the last run that failed the comic
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Attempts      int64                  `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	FailedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=failed_at,json=failedAt,proto3" json:"failed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailedComic) Reset() {
+	*x = FailedComic{}
+	mi := &file_proto_update_update_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailedComic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailedComic) ProtoMessage() {}
+
+func (x *FailedComic) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailedComic.ProtoReflect.Descriptor instead.
+func (*FailedComic) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FailedComic) GetComicId() int64 {
+	if x != nil {
+		return x.ComicId
+	}
+	return 0
+}
+
+func (x *FailedComic) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *FailedComic) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *FailedComic) GetAttempts() int64 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *FailedComic) GetFailedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FailedAt
+	}
+	return nil
+}
+
+type RunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunsRequest) Reset() {
+	*x = RunsRequest{}
+	mi := &file_proto_update_update_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunsRequest) ProtoMessage() {}
+
+func (x *RunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunsRequest.ProtoReflect.Descriptor instead.
+func (*RunsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RunsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type RunsReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// latest first
+	Runs          []*Run         `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+	Failed        []*FailedComic `protobuf:"bytes,2,rep,name=failed,proto3" json:"failed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunsReply) Reset() {
+	*x = RunsReply{}
+	mi := &file_proto_update_update_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunsReply) ProtoMessage() {}
+
+func (x *RunsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunsReply.ProtoReflect.Descriptor instead.
+func (*RunsReply) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RunsReply) GetRuns() []*Run {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+func (x *RunsReply) GetFailed() []*FailedComic {
+	if x != nil {
+		return x.Failed
+	}
+	return nil
+}
+
+type ComicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComicRequest) Reset() {
+	*x = ComicRequest{}
+	mi := &file_proto_update_update_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicRequest) ProtoMessage() {}
+
+func (x *ComicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicRequest.ProtoReflect.Descriptor instead.
+func (*ComicRequest) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ComicRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type HideRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Hidden        bool                   `protobuf:"varint,2,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HideRequest) Reset() {
+	*x = HideRequest{}
+	mi := &file_proto_update_update_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HideRequest) ProtoMessage() {}
+
+func (x *HideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HideRequest.ProtoReflect.Descriptor instead.
+func (*HideRequest) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *HideRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *HideRequest) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+type CoverageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoverageRequest) Reset() {
+	*x = CoverageRequest{}
+	mi := &file_proto_update_update_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoverageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoverageRequest) ProtoMessage() {}
+
+func (x *CoverageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoverageRequest.ProtoReflect.Descriptor instead.
+func (*CoverageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CoverageRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ComicCoverage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title            string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	TitleTokens      int64                  `protobuf:"varint,3,opt,name=title_tokens,json=titleTokens,proto3" json:"title_tokens,omitempty"`
+	AltTokens        int64                  `protobuf:"varint,4,opt,name=alt_tokens,json=altTokens,proto3" json:"alt_tokens,omitempty"`
+	TranscriptTokens int64                  `protobuf:"varint,5,opt,name=transcript_tokens,json=transcriptTokens,proto3" json:"transcript_tokens,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ComicCoverage) Reset() {
+	*x = ComicCoverage{}
+	mi := &file_proto_update_update_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicCoverage) ProtoMessage() {}
+
+func (x *ComicCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicCoverage.ProtoReflect.Descriptor instead.
+func (*ComicCoverage) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ComicCoverage) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ComicCoverage) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ComicCoverage) GetTitleTokens() int64 {
+	if x != nil {
+		return x.TitleTokens
+	}
+	return 0
+}
+
+func (x *ComicCoverage) GetAltTokens() int64 {
+	if x != nil {
+		return x.AltTokens
+	}
+	return 0
+}
+
+func (x *ComicCoverage) GetTranscriptTokens() int64 {
+	if x != nil {
+		return x.TranscriptTokens
+	}
+	return 0
+}
+
+type CoverageReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Comics        []*ComicCoverage       `protobuf:"bytes,1,rep,name=comics,proto3" json:"comics,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoverageReply) Reset() {
+	*x = CoverageReply{}
+	mi := &file_proto_update_update_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoverageReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoverageReply) ProtoMessage() {}
+
+func (x *CoverageReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoverageReply.ProtoReflect.Descriptor instead.
+func (*CoverageReply) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CoverageReply) GetComics() []*ComicCoverage {
+	if x != nil {
+		return x.Comics
+	}
+	return nil
+}
+
+func (x *CoverageReply) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type ImageReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Hash          string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageReply) Reset() {
+	*x = ImageReply{}
+	mi := &file_proto_update_update_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageReply) ProtoMessage() {}
+
+func (x *ImageReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageReply.ProtoReflect.Descriptor instead.
+func (*ImageReply) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ImageReply) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ImageReply) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *ImageReply) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ImageReply) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+// curated text merged into the comic keywords
+type Annotation struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Transcript string                 `protobuf:"bytes,2,opt,name=transcript,proto3" json:"transcript,omitempty"`
+	Keywords   []string               `protobuf:"bytes,3,rep,name=keywords,proto3" json:"keywords,omitempty"`
+	// set by the service
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Annotation) Reset() {
+	*x = Annotation{}
+	mi := &file_proto_update_update_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Annotation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Annotation) ProtoMessage() {}
+
+func (x *Annotation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Annotation.ProtoReflect.Descriptor instead.
+func (*Annotation) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Annotation) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Annotation) GetTranscript() string {
+	if x != nil {
+		return x.Transcript
+	}
+	return ""
+}
+
+func (x *Annotation) GetKeywords() []string {
+	if x != nil {
+		return x.Keywords
+	}
+	return nil
+}
+
+func (x *Annotation) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_proto_update_update_proto protoreflect.FileDescriptor
+
+const file_proto_update_update_proto_rawDesc = "" +
+	"\n" +
+	"\x19proto/update/update.proto\x12\x06update\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe0\x02\n" +
+	"\n" +
+	"StatsReply\x12\x1f\n" +
+	"\vwords_total\x18\x01 \x01(\x03R\n" +
+	"wordsTotal\x12!\n" +
+	"\fwords_unique\x18\x02 \x01(\x03R\vwordsUnique\x12!\n" +
+	"\fcomics_total\x18\x03 \x01(\x03R\vcomicsTotal\x12%\n" +
+	"\x0ecomics_fetched\x18\x04 \x01(\x03R\rcomicsFetched\x12F\n" +
+	"\x11latest_checked_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0flatestCheckedAt\x12&\n" +
+	"\x0fcomics_no_title\x18\x06 \x01(\x03R\rcomicsNoTitle\x12\"\n" +
+	"\rcomics_no_alt\x18\a \x01(\x03R\vcomicsNoAlt\x120\n" +
+	"\x14comics_no_transcript\x18\b \x01(\x03R\x12comicsNoTranscript\"l\n" +
+	"\vStatusReply\x12&\n" +
+	"\x06status\x18\x01 \x01(\x0e2\x0e.update.StatusR\x06status\x125\n" +
+	"\bnext_run\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\anextRun\"\x9f\x01\n" +
+	"\rUpdateRequest\x12&\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x12.update.UpdateModeR\x04mode\x12\x17\n" +
+	"\afrom_id\x18\x02 \x01(\x03R\x06fromId\x12\x13\n" +
+	"\x05to_id\x18\x03 \x01(\x03R\x04toId\x128\n" +
+	"\n" +
+	"older_than\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\tolderThan\"M\n" +
+	"\vUpdateReply\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12'\n" +
+	"\x0falready_running\x18\x02 \x01(\bR\x0ealreadyRunning\"#\n" +
+	"\n" +
+	"JobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xee\x02\n" +
+	"\rProgressReply\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12&\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x10.update.JobStateR\x05state\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x03R\x05total\x12\x18\n" +
+	"\afetched\x18\x04 \x01(\x03R\afetched\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\x03R\x06failed\x12\x1b\n" +
+	"\tin_flight\x18\x06 \x01(\x03R\binFlight\x12+\n" +
+	"\x03eta\x18\a \x01(\v2\x19.google.protobuf.DurationR\x03eta\x129\n" +
+	"\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\"\x98\x01\n" +
+	"\vUpdateEvent\x12%\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x11.update.EventTypeR\x04type\x12\x19\n" +
+	"\bcomic_id\x18\x02 \x01(\x03R\acomicId\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x121\n" +
+	"\bprogress\x18\x04 \x01(\v2\x15.update.ProgressReplyR\bprogress\"\xed\x02\n" +
+	"\x03Run\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12)\n" +
+	"\atrigger\x18\x02 \x01(\x0e2\x0f.update.TriggerR\atrigger\x12&\n" +
+	"\x04mode\x18\x03 \x01(\x0e2\x12.update.UpdateModeR\x04mode\x12&\n" +
+	"\x05state\x18\x04 \x01(\x0e2\x10.update.JobStateR\x05state\x129\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12\x14\n" +
+	"\x05total\x18\a \x01(\x03R\x05total\x12\x18\n" +
+	"\afetched\x18\b \x01(\x03R\afetched\x12\x16\n" +
+	"\x06failed\x18\t \x01(\x03R\x06failed\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\"\xaa\x01\n" +
+	"\vFailedComic\x12\x19\n" +
+	"\bcomic_id\x18\x01 \x01(\x03R\acomicId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\x03R\battempts\x127\n" +
+	"\tfailed_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bfailedAt\"#\n" +
+	"\vRunsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit\"Y\n" +
+	"\tRunsReply\x12\x1f\n" +
+	"\x04runs\x18\x01 \x03(\v2\v.update.RunR\x04runs\x12+\n" +
+	"\x06failed\x18\x02 \x03(\v2\x13.update.FailedComicR\x06failed\"\x1e\n" +
+	"\fComicRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"5\n" +
+	"\vHideRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
+	"\x06hidden\x18\x02 \x01(\bR\x06hidden\"'\n" +
+	"\x0fCoverageRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit\"\xa4\x01\n" +
+	"\rComicCoverage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12!\n" +
+	"\ftitle_tokens\x18\x03 \x01(\x03R\vtitleTokens\x12\x1d\n" +
+	"\n" +
+	"alt_tokens\x18\x04 \x01(\x03R\taltTokens\x12+\n" +
+	"\x11transcript_tokens\x18\x05 \x01(\x03R\x10transcriptTokens\"T\n" +
+	"\rCoverageReply\x12-\n" +
+	"\x06comics\x18\x01 \x03(\v2\x15.update.ComicCoverageR\x06comics\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"b\n" +
+	"\n" +
+	"ImageReply\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\"\x93\x01\n" +
+	"\n" +
+	"Annotation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1e\n" +
+	"\n" +
+	"transcript\x18\x02 \x01(\tR\n" +
+	"transcript\x12\x1a\n" +
+	"\bkeywords\x18\x03 \x03(\tR\bkeywords\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt*E\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vSTATUS_IDLE\x10\x01\x12\x12\n" +
+	"\x0eSTATUS_RUNNING\x10\x02*\x88\x01\n" +
+	"\n" +
+	"UpdateMode\x12\x1b\n" +
+	"\x17UPDATE_MODE_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fUPDATE_MODE_NEW\x10\x01\x12\x16\n" +
+	"\x12UPDATE_MODE_FAILED\x10\x02\x12\x17\n" +
+	"\x13UPDATE_MODE_REFRESH\x10\x03\x12\x17\n" +
+	"\x13UPDATE_MODE_REINDEX\x10\x04*~\n" +
+	"\bJobState\x12\x19\n" +
+	"\x15JOB_STATE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11JOB_STATE_RUNNING\x10\x01\x12\x12\n" +
+	"\x0eJOB_STATE_DONE\x10\x02\x12\x14\n" +
+	"\x10JOB_STATE_FAILED\x10\x03\x12\x16\n" +
+	"\x12JOB_STATE_CANCELED\x10\x04*\xce\x01\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13EVENT_TYPE_PROGRESS\x10\x01\x12\x16\n" +
+	"\x12EVENT_TYPE_STARTED\x10\x02\x12\x16\n" +
+	"\x12EVENT_TYPE_FETCHED\x10\x03\x12\x15\n" +
+	"\x11EVENT_TYPE_FAILED\x10\x04\x12\x14\n" +
+	"\x10EVENT_TYPE_SAVED\x10\x05\x12\x17\n" +
+	"\x13EVENT_TYPE_FINISHED\x10\x06\x12\x16\n" +
+	"\x12EVENT_TYPE_SKIPPED\x10\a*L\n" +
+	"\aTrigger\x12\x17\n" +
+	"\x13TRIGGER_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eTRIGGER_MANUAL\x10\x01\x12\x14\n" +
+	"\x10TRIGGER_SCHEDULE\x10\x022\xaf\b\n" +
 	"\x06Update\x128\n" +
 	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x127\n" +
-	"\x06Status\x12\x16.google.protobuf.Empty\x1a\x13.update.StatusReply\"\x00\x12:\n" +
-	"\x06Update\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x125\n" +
-	"\x05Stats\x12\x16.google.protobuf.Empty\x1a\x12.update.StatsReply\"\x00\x128\n" +
-	"\x04Drop\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00B\x1fZ\x1dyadro.com/course/proto/updateb\x06proto3"
+	"\x06Status\x12\x16.google.protobuf.Empty\x1a\x13.update.StatusReply\"\x00\x126\n" +
+	"\x06Update\x12\x15.update.UpdateRequest\x1a\x13.update.UpdateReply\"\x00\x128\n" +
+	"\aReindex\x12\x16.google.protobuf.Empty\x1a\x13.update.UpdateReply\"\x00\x127\n" +
+	"\bProgress\x12\x12.update.JobRequest\x1a\x15.update.ProgressReply\"\x00\x126\n" +
+	"\x06Cancel\x12\x12.update.JobRequest\x1a\x16.google.protobuf.Empty\"\x00\x12>\n" +
+	"\vWatchUpdate\x12\x16.google.protobuf.Empty\x1a\x13.update.UpdateEvent\"\x000\x01\x125\n" +
+	"\x05Stats\x12\x16.google.protobuf.Empty\x1a\x12.update.StatsReply\"\x00\x120\n" +
+	"\x04Runs\x12\x13.update.RunsRequest\x1a\x11.update.RunsReply\"\x00\x12<\n" +
+	"\bCoverage\x12\x17.update.CoverageRequest\x1a\x15.update.CoverageReply\"\x00\x128\n" +
+	"\x04Drop\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12=\n" +
+	"\vDeleteComic\x12\x14.update.ComicRequest\x1a\x16.google.protobuf.Empty\"\x00\x12:\n" +
+	"\tHideComic\x12\x13.update.HideRequest\x1a\x16.google.protobuf.Empty\"\x00\x12>\n" +
+	"\fRefetchComic\x12\x14.update.ComicRequest\x1a\x16.google.protobuf.Empty\"\x00\x123\n" +
+	"\x05Image\x12\x14.update.ComicRequest\x1a\x12.update.ImageReply\"\x00\x12;\n" +
+	"\rGetAnnotation\x12\x14.update.ComicRequest\x1a\x12.update.Annotation\"\x00\x12=\n" +
+	"\rSetAnnotation\x12\x12.update.Annotation\x1a\x16.google.protobuf.Empty\"\x00\x12B\n" +
+	"\x10DeleteAnnotation\x12\x14.update.ComicRequest\x1a\x16.google.protobuf.Empty\"\x00B\x1fZ\x1dyadro.com/course/proto/updateb\x06proto3"
 
 var (
 	file_proto_update_update_proto_rawDescOnce sync.Once
@@ -220,31 +1672,99 @@ func file_proto_update_update_proto_rawDescGZIP() []byte {
 	return file_proto_update_update_proto_rawDescData
 }
 
-var file_proto_update_update_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_update_update_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_update_update_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_proto_update_update_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_proto_update_update_proto_goTypes = []any{
-	(Status)(0),           // 0: update.Status
-	(*StatsReply)(nil),    // 1: update.StatsReply
-	(*StatusReply)(nil),   // 2: update.StatusReply
-	(*emptypb.Empty)(nil), // 3: google.protobuf.Empty
+	(Status)(0),                   // 0: update.Status
+	(UpdateMode)(0),               // 1: update.UpdateMode
+	(JobState)(0),                 // 2: update.JobState
+	(EventType)(0),                // 3: update.EventType
+	(Trigger)(0),                  // 4: update.Trigger
+	(*StatsReply)(nil),            // 5: update.StatsReply
+	(*StatusReply)(nil),           // 6: update.StatusReply
+	(*UpdateRequest)(nil),         // 7: update.UpdateRequest
+	(*UpdateReply)(nil),           // 8: update.UpdateReply
+	(*JobRequest)(nil),            // 9: update.JobRequest
+	(*ProgressReply)(nil),         // 10: update.ProgressReply
+	(*UpdateEvent)(nil),           // 11: update.UpdateEvent
+	(*Run)(nil),                   // 12: update.Run
+	(*FailedComic)(nil),           // 13: update.FailedComic
+	(*RunsRequest)(nil),           // 14: update.RunsRequest
+	(*RunsReply)(nil),             // 15: update.RunsReply
+	(*ComicRequest)(nil),          // 16: update.ComicRequest
+	(*HideRequest)(nil),           // 17: update.HideRequest
+	(*CoverageRequest)(nil),       // 18: update.CoverageRequest
+	(*ComicCoverage)(nil),         // 19: update.ComicCoverage
+	(*CoverageReply)(nil),         // 20: update.CoverageReply
+	(*ImageReply)(nil),            // 21: update.ImageReply
+	(*Annotation)(nil),            // 22: update.Annotation
+	(*timestamppb.Timestamp)(nil), // 23: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 24: google.protobuf.Duration
+	(*emptypb.Empty)(nil),         // 25: google.protobuf.Empty
 }
 var file_proto_update_update_proto_depIdxs = []int32{
-	0, // 0: update.StatusReply.status:type_name -> update.Status
-	3, // 1: update.Update.Ping:input_type -> google.protobuf.Empty
-	3, // 2: update.Update.Status:input_type -> google.protobuf.Empty
-	3, // 3: update.Update.Update:input_type -> google.protobuf.Empty
-	3, // 4: update.Update.Stats:input_type -> google.protobuf.Empty
-	3, // 5: update.Update.Drop:input_type -> google.protobuf.Empty
-	3, // 6: update.Update.Ping:output_type -> google.protobuf.Empty
-	2, // 7: update.Update.Status:output_type -> update.StatusReply
-	3, // 8: update.Update.Update:output_type -> google.protobuf.Empty
-	1, // 9: update.Update.Stats:output_type -> update.StatsReply
-	3, // 10: update.Update.Drop:output_type -> google.protobuf.Empty
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	23, // 0: update.StatsReply.latest_checked_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: update.StatusReply.status:type_name -> update.Status
+	23, // 2: update.StatusReply.next_run:type_name -> google.protobuf.Timestamp
+	1,  // 3: update.UpdateRequest.mode:type_name -> update.UpdateMode
+	24, // 4: update.UpdateRequest.older_than:type_name -> google.protobuf.Duration
+	2,  // 5: update.ProgressReply.state:type_name -> update.JobState
+	24, // 6: update.ProgressReply.eta:type_name -> google.protobuf.Duration
+	23, // 7: update.ProgressReply.started_at:type_name -> google.protobuf.Timestamp
+	23, // 8: update.ProgressReply.finished_at:type_name -> google.protobuf.Timestamp
+	3,  // 9: update.UpdateEvent.type:type_name -> update.EventType
+	10, // 10: update.UpdateEvent.progress:type_name -> update.ProgressReply
+	4,  // 11: update.Run.trigger:type_name -> update.Trigger
+	1,  // 12: update.Run.mode:type_name -> update.UpdateMode
+	2,  // 13: update.Run.state:type_name -> update.JobState
+	23, // 14: update.Run.started_at:type_name -> google.protobuf.Timestamp
+	23, // 15: update.Run.finished_at:type_name -> google.protobuf.Timestamp
+	23, // 16: update.FailedComic.failed_at:type_name -> google.protobuf.Timestamp
+	12, // 17: update.RunsReply.runs:type_name -> update.Run
+	13, // 18: update.RunsReply.failed:type_name -> update.FailedComic
+	19, // 19: update.CoverageReply.comics:type_name -> update.ComicCoverage
+	23, // 20: update.Annotation.updated_at:type_name -> google.protobuf.Timestamp
+	25, // 21: update.Update.Ping:input_type -> google.protobuf.Empty
+	25, // 22: update.Update.Status:input_type -> google.protobuf.Empty
+	7,  // 23: update.Update.Update:input_type -> update.UpdateRequest
+	25, // 24: update.Update.Reindex:input_type -> google.protobuf.Empty
+	9,  // 25: update.Update.Progress:input_type -> update.JobRequest
+	9,  // 26: update.Update.Cancel:input_type -> update.JobRequest
+	25, // 27: update.Update.WatchUpdate:input_type -> google.protobuf.Empty
+	25, // 28: update.Update.Stats:input_type -> google.protobuf.Empty
+	14, // 29: update.Update.Runs:input_type -> update.RunsRequest
+	18, // 30: update.Update.Coverage:input_type -> update.CoverageRequest
+	25, // 31: update.Update.Drop:input_type -> google.protobuf.Empty
+	16, // 32: update.Update.DeleteComic:input_type -> update.ComicRequest
+	17, // 33: update.Update.HideComic:input_type -> update.HideRequest
+	16, // 34: update.Update.RefetchComic:input_type -> update.ComicRequest
+	16, // 35: update.Update.Image:input_type -> update.ComicRequest
+	16, // 36: update.Update.GetAnnotation:input_type -> update.ComicRequest
+	22, // 37: update.Update.SetAnnotation:input_type -> update.Annotation
+	16, // 38: update.Update.DeleteAnnotation:input_type -> update.ComicRequest
+	25, // 39: update.Update.Ping:output_type -> google.protobuf.Empty
+	6,  // 40: update.Update.Status:output_type -> update.StatusReply
+	8,  // 41: update.Update.Update:output_type -> update.UpdateReply
+	8,  // 42: update.Update.Reindex:output_type -> update.UpdateReply
+	10, // 43: update.Update.Progress:output_type -> update.ProgressReply
+	25, // 44: update.Update.Cancel:output_type -> google.protobuf.Empty
+	11, // 45: update.Update.WatchUpdate:output_type -> update.UpdateEvent
+	5,  // 46: update.Update.Stats:output_type -> update.StatsReply
+	15, // 47: update.Update.Runs:output_type -> update.RunsReply
+	20, // 48: update.Update.Coverage:output_type -> update.CoverageReply
+	25, // 49: update.Update.Drop:output_type -> google.protobuf.Empty
+	25, // 50: update.Update.DeleteComic:output_type -> google.protobuf.Empty
+	25, // 51: update.Update.HideComic:output_type -> google.protobuf.Empty
+	25, // 52: update.Update.RefetchComic:output_type -> google.protobuf.Empty
+	21, // 53: update.Update.Image:output_type -> update.ImageReply
+	22, // 54: update.Update.GetAnnotation:output_type -> update.Annotation
+	25, // 55: update.Update.SetAnnotation:output_type -> google.protobuf.Empty
+	25, // 56: update.Update.DeleteAnnotation:output_type -> google.protobuf.Empty
+	39, // [39:57] is the sub-list for method output_type
+	21, // [21:39] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_proto_update_update_proto_init() }
@@ -257,8 +1777,8 @@ func file_proto_update_update_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_update_update_proto_rawDesc), len(file_proto_update_update_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   2,
+			NumEnums:      5,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,11 +20,24 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Update_Ping_FullMethodName   = "/update.Update/Ping"
-	Update_Status_FullMethodName = "/update.Update/Status"
-	Update_Update_FullMethodName = "/update.Update/Update"
-	Update_Stats_FullMethodName  = "/update.Update/Stats"
-	Update_Drop_FullMethodName   = "/update.Update/Drop"
+	Update_Ping_FullMethodName             = "/update.Update/Ping"
+	Update_Status_FullMethodName           = "/update.Update/Status"
+	Update_Update_FullMethodName           = "/update.Update/Update"
+	Update_Reindex_FullMethodName          = "/update.Update/Reindex"
+	Update_Progress_FullMethodName         = "/update.Update/Progress"
+	Update_Cancel_FullMethodName           = "/update.Update/Cancel"
+	Update_WatchUpdate_FullMethodName      = "/update.Update/WatchUpdate"
+	Update_Stats_FullMethodName            = "/update.Update/Stats"
+	Update_Runs_FullMethodName             = "/update.Update/Runs"
+	Update_Coverage_FullMethodName         = "/update.Update/Coverage"
+	Update_Drop_FullMethodName             = "/update.Update/Drop"
+	Update_DeleteComic_FullMethodName      = "/update.Update/DeleteComic"
+	Update_HideComic_FullMethodName        = "/update.Update/HideComic"
+	Update_RefetchComic_FullMethodName     = "/update.Update/RefetchComic"
+	Update_Image_FullMethodName            = "/update.Update/Image"
+	Update_GetAnnotation_FullMethodName    = "/update.Update/GetAnnotation"
+	Update_SetAnnotation_FullMethodName    = "/update.Update/SetAnnotation"
+	Update_DeleteAnnotation_FullMethodName = "/update.Update/DeleteAnnotation"
 )
 
 // UpdateClient is the client API for Update service.
@@ -33,9 +46,31 @@ const (
 type UpdateClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusReply, error)
-	Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Starts an update job in background
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateReply, error)
+	// Starts a job normalizing stored comics again without fetching them
+	Reindex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UpdateReply, error)
+	Progress(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*ProgressReply, error)
+	Cancel(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Streams events of every update until the client goes away
+	WatchUpdate(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UpdateEvent], error)
 	Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatsReply, error)
+	// Lists recent update runs and comics not fetched yet
+	Runs(ctx context.Context, in *RunsRequest, opts ...grpc.CallOption) (*RunsReply, error)
+	// Lists comics without a transcript, fewest tokens first
+	Coverage(ctx context.Context, in *CoverageRequest, opts ...grpc.CallOption) (*CoverageReply, error)
 	Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Soft deletes a comic, updates do not fetch it again
+	DeleteComic(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	HideComic(ctx context.Context, in *HideRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Fetches a comic again, restoring it if deleted
+	RefetchComic(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Returns a stored comic image
+	Image(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*ImageReply, error)
+	GetAnnotation(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*Annotation, error)
+	// Replaces the annotation of a comic and indexes the comic again
+	SetAnnotation(ctx context.Context, in *Annotation, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteAnnotation(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type updateClient struct {
@@ -66,9 +101,9 @@ func (c *updateClient) Status(ctx context.Context, in *emptypb.Empty, opts ...gr
 	return out, nil
 }
 
-func (c *updateClient) Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (c *updateClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(emptypb.Empty)
+	out := new(UpdateReply)
 	err := c.cc.Invoke(ctx, Update_Update_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -76,6 +111,55 @@ func (c *updateClient) Update(ctx context.Context, in *emptypb.Empty, opts ...gr
 	return out, nil
 }
 
+func (c *updateClient) Reindex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UpdateReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateReply)
+	err := c.cc.Invoke(ctx, Update_Reindex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) Progress(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*ProgressReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProgressReply)
+	err := c.cc.Invoke(ctx, Update_Progress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) Cancel(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Update_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) WatchUpdate(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UpdateEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Update_ServiceDesc.Streams[0], Update_WatchUpdate_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, UpdateEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Update_WatchUpdateClient = grpc.ServerStreamingClient[UpdateEvent]
+
 func (c *updateClient) Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatsReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatsReply)
@@ -86,6 +170,26 @@ func (c *updateClient) Stats(ctx context.Context, in *emptypb.Empty, opts ...grp
 	return out, nil
 }
 
+func (c *updateClient) Runs(ctx context.Context, in *RunsRequest, opts ...grpc.CallOption) (*RunsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunsReply)
+	err := c.cc.Invoke(ctx, Update_Runs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) Coverage(ctx context.Context, in *CoverageRequest, opts ...grpc.CallOption) (*CoverageReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CoverageReply)
+	err := c.cc.Invoke(ctx, Update_Coverage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *updateClient) Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -96,15 +200,107 @@ func (c *updateClient) Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc
 	return out, nil
 }
 
+func (c *updateClient) DeleteComic(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Update_DeleteComic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) HideComic(ctx context.Context, in *HideRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Update_HideComic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) RefetchComic(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Update_RefetchComic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) Image(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*ImageReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImageReply)
+	err := c.cc.Invoke(ctx, Update_Image_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) GetAnnotation(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*Annotation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Annotation)
+	err := c.cc.Invoke(ctx, Update_GetAnnotation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) SetAnnotation(ctx context.Context, in *Annotation, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Update_SetAnnotation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) DeleteAnnotation(ctx context.Context, in *ComicRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Update_DeleteAnnotation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UpdateServer is the server API for Update service.
 // All implementations must embed UnimplementedUpdateServer
 // for forward compatibility.
 type UpdateServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Status(context.Context, *emptypb.Empty) (*StatusReply, error)
-	Update(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Starts an update job in background
+	Update(context.Context, *UpdateRequest) (*UpdateReply, error)
+	// Starts a job normalizing stored comics again without fetching them
+	Reindex(context.Context, *emptypb.Empty) (*UpdateReply, error)
+	Progress(context.Context, *JobRequest) (*ProgressReply, error)
+	Cancel(context.Context, *JobRequest) (*emptypb.Empty, error)
+	// Streams events of every update until the client goes away
+	WatchUpdate(*emptypb.Empty, grpc.ServerStreamingServer[UpdateEvent]) error
 	Stats(context.Context, *emptypb.Empty) (*StatsReply, error)
+	// Lists recent update runs and comics not fetched yet
+	Runs(context.Context, *RunsRequest) (*RunsReply, error)
+	// Lists comics without a transcript, fewest tokens first
+	Coverage(context.Context, *CoverageRequest) (*CoverageReply, error)
 	Drop(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Soft deletes a comic, updates do not fetch it again
+	DeleteComic(context.Context, *ComicRequest) (*emptypb.Empty, error)
+	HideComic(context.Context, *HideRequest) (*emptypb.Empty, error)
+	// Fetches a comic again, restoring it if deleted
+	RefetchComic(context.Context, *ComicRequest) (*emptypb.Empty, error)
+	// Returns a stored comic image
+	Image(context.Context, *ComicRequest) (*ImageReply, error)
+	GetAnnotation(context.Context, *ComicRequest) (*Annotation, error)
+	// Replaces the annotation of a comic and indexes the comic again
+	SetAnnotation(context.Context, *Annotation) (*emptypb.Empty, error)
+	DeleteAnnotation(context.Context, *ComicRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedUpdateServer()
 }
 
@@ -121,15 +317,54 @@ func (UnimplementedUpdateServer) Ping(context.Context, *emptypb.Empty) (*emptypb
 func (UnimplementedUpdateServer) Status(context.Context, *emptypb.Empty) (*StatusReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
-func (UnimplementedUpdateServer) Update(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+func (UnimplementedUpdateServer) Update(context.Context, *UpdateRequest) (*UpdateReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedUpdateServer) Reindex(context.Context, *emptypb.Empty) (*UpdateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reindex not implemented")
+}
+func (UnimplementedUpdateServer) Progress(context.Context, *JobRequest) (*ProgressReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Progress not implemented")
+}
+func (UnimplementedUpdateServer) Cancel(context.Context, *JobRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedUpdateServer) WatchUpdate(*emptypb.Empty, grpc.ServerStreamingServer[UpdateEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchUpdate not implemented")
+}
 func (UnimplementedUpdateServer) Stats(context.Context, *emptypb.Empty) (*StatsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
 }
+func (UnimplementedUpdateServer) Runs(context.Context, *RunsRequest) (*RunsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Runs not implemented")
+}
+func (UnimplementedUpdateServer) Coverage(context.Context, *CoverageRequest) (*CoverageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Coverage not implemented")
+}
 func (UnimplementedUpdateServer) Drop(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Drop not implemented")
 }
+func (UnimplementedUpdateServer) DeleteComic(context.Context, *ComicRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteComic not implemented")
+}
+func (UnimplementedUpdateServer) HideComic(context.Context, *HideRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HideComic not implemented")
+}
+func (UnimplementedUpdateServer) RefetchComic(context.Context, *ComicRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefetchComic not implemented")
+}
+func (UnimplementedUpdateServer) Image(context.Context, *ComicRequest) (*ImageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Image not implemented")
+}
+func (UnimplementedUpdateServer) GetAnnotation(context.Context, *ComicRequest) (*Annotation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAnnotation not implemented")
+}
+func (UnimplementedUpdateServer) SetAnnotation(context.Context, *Annotation) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAnnotation not implemented")
+}
+func (UnimplementedUpdateServer) DeleteAnnotation(context.Context, *ComicRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAnnotation not implemented")
+}
 func (UnimplementedUpdateServer) mustEmbedUnimplementedUpdateServer() {}
 func (UnimplementedUpdateServer) testEmbeddedByValue()                {}
 
@@ -188,7 +423,7 @@ func _Update_Status_Handler(srv interface{}, ctx context.Context, dec func(inter
 }
 
 func _Update_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(UpdateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -200,11 +435,76 @@ func _Update_Update_Handler(srv interface{}, ctx context.Context, dec func(inter
 		FullMethod: Update_Update_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UpdateServer).Update(ctx, req.(*emptypb.Empty))
+		return srv.(UpdateServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_Reindex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Reindex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Reindex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Reindex(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_Progress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Progress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Progress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Progress(ctx, req.(*JobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Cancel(ctx, req.(*JobRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Update_WatchUpdate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UpdateServer).WatchUpdate(m, &grpc.GenericServerStream[emptypb.Empty, UpdateEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Update_WatchUpdateServer = grpc.ServerStreamingServer[UpdateEvent]
+
 func _Update_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -223,6 +523,42 @@ func _Update_Stats_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Update_Runs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Runs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Runs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Runs(ctx, req.(*RunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_Coverage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CoverageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Coverage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Coverage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Coverage(ctx, req.(*CoverageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Update_Drop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -241,6 +577,132 @@ func _Update_Drop_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Update_DeleteComic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).DeleteComic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_DeleteComic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).DeleteComic(ctx, req.(*ComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_HideComic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).HideComic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_HideComic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).HideComic(ctx, req.(*HideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_RefetchComic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).RefetchComic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_RefetchComic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).RefetchComic(ctx, req.(*ComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_Image_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Image(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Image_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Image(ctx, req.(*ComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_GetAnnotation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).GetAnnotation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_GetAnnotation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).GetAnnotation(ctx, req.(*ComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_SetAnnotation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Annotation)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).SetAnnotation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_SetAnnotation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).SetAnnotation(ctx, req.(*Annotation))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_DeleteAnnotation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).DeleteAnnotation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_DeleteAnnotation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).DeleteAnnotation(ctx, req.(*ComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Update_ServiceDesc is the grpc.ServiceDesc for Update service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -260,15 +722,69 @@ var Update_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _Update_Update_Handler,
 		},
+		{
+			MethodName: "Reindex",
+			Handler:    _Update_Reindex_Handler,
+		},
+		{
+			MethodName: "Progress",
+			Handler:    _Update_Progress_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Update_Cancel_Handler,
+		},
 		{
 			MethodName: "Stats",
 			Handler:    _Update_Stats_Handler,
 		},
+		{
+			MethodName: "Runs",
+			Handler:    _Update_Runs_Handler,
+		},
+		{
+			MethodName: "Coverage",
+			Handler:    _Update_Coverage_Handler,
+		},
 		{
 			MethodName: "Drop",
 			Handler:    _Update_Drop_Handler,
 		},
+		{
+			MethodName: "DeleteComic",
+			Handler:    _Update_DeleteComic_Handler,
+		},
+		{
+			MethodName: "HideComic",
+			Handler:    _Update_HideComic_Handler,
+		},
+		{
+			MethodName: "RefetchComic",
+			Handler:    _Update_RefetchComic_Handler,
+		},
+		{
+			MethodName: "Image",
+			Handler:    _Update_Image_Handler,
+		},
+		{
+			MethodName: "GetAnnotation",
+			Handler:    _Update_GetAnnotation_Handler,
+		},
+		{
+			MethodName: "SetAnnotation",
+			Handler:    _Update_SetAnnotation_Handler,
+		},
+		{
+			MethodName: "DeleteAnnotation",
+			Handler:    _Update_DeleteAnnotation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchUpdate",
+			Handler:       _Update_WatchUpdate_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/update/update.proto",
 }
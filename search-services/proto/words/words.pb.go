@@ -23,8 +23,10 @@ const (
 )
 
 type WordsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Phrase string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	// also emit bigrams of adjacent stems joined by "_"
+	Shingles      bool `protobuf:"varint,2,opt,name=shingles,proto3" json:"shingles,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -66,6 +68,13 @@ func (x *WordsRequest) GetPhrase() string {
 	return ""
 }
 
+func (x *WordsRequest) GetShingles() bool {
+	if x != nil {
+		return x.Shingles
+	}
+	return false
+}
+
 type WordsReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Words         []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
@@ -110,19 +119,432 @@ func (x *WordsReply) GetWords() []string {
 	return nil
 }
 
+type Document struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Phrase        string                 `protobuf:"bytes,2,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Document) Reset() {
+	*x = Document{}
+	mi := &file_proto_words_words_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Document) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Document) ProtoMessage() {}
+
+func (x *Document) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Document.ProtoReflect.Descriptor instead.
+func (*Document) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Document) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Document) GetPhrase() string {
+	if x != nil {
+		return x.Phrase
+	}
+	return ""
+}
+
+type NormBatchRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Documents []*Document            `protobuf:"bytes,1,rep,name=documents,proto3" json:"documents,omitempty"`
+	// also emit bigrams of adjacent stems joined by "_"
+	Shingles      bool `protobuf:"varint,2,opt,name=shingles,proto3" json:"shingles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormBatchRequest) Reset() {
+	*x = NormBatchRequest{}
+	mi := &file_proto_words_words_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormBatchRequest) ProtoMessage() {}
+
+func (x *NormBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormBatchRequest.ProtoReflect.Descriptor instead.
+func (*NormBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NormBatchRequest) GetDocuments() []*Document {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+func (x *NormBatchRequest) GetShingles() bool {
+	if x != nil {
+		return x.Shingles
+	}
+	return false
+}
+
+type DocumentWords struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Words         []string               `protobuf:"bytes,2,rep,name=words,proto3" json:"words,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocumentWords) Reset() {
+	*x = DocumentWords{}
+	mi := &file_proto_words_words_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentWords) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentWords) ProtoMessage() {}
+
+func (x *DocumentWords) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentWords.ProtoReflect.Descriptor instead.
+func (*DocumentWords) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DocumentWords) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DocumentWords) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+type NormBatchReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documents     []*DocumentWords       `protobuf:"bytes,1,rep,name=documents,proto3" json:"documents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormBatchReply) Reset() {
+	*x = NormBatchReply{}
+	mi := &file_proto_words_words_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormBatchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormBatchReply) ProtoMessage() {}
+
+func (x *NormBatchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormBatchReply.ProtoReflect.Descriptor instead.
+func (*NormBatchReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NormBatchReply) GetDocuments() []*DocumentWords {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+type Token struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Original string                 `protobuf:"bytes,1,opt,name=original,proto3" json:"original,omitempty"`
+	Stem     string                 `protobuf:"bytes,2,opt,name=stem,proto3" json:"stem,omitempty"`
+	// ordinal number of the token in the phrase, stop-words included
+	Position int32 `protobuf:"varint,3,opt,name=position,proto3" json:"position,omitempty"`
+	// byte offsets of the token in the phrase, end is exclusive
+	Start         int32 `protobuf:"varint,4,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32 `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`
+	StopWord      bool  `protobuf:"varint,6,opt,name=stop_word,json=stopWord,proto3" json:"stop_word,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_proto_words_words_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Token) GetOriginal() string {
+	if x != nil {
+		return x.Original
+	}
+	return ""
+}
+
+func (x *Token) GetStem() string {
+	if x != nil {
+		return x.Stem
+	}
+	return ""
+}
+
+func (x *Token) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Token) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Token) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Token) GetStopWord() bool {
+	if x != nil {
+		return x.StopWord
+	}
+	return false
+}
+
+type AnalyzeReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*Token               `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeReply) Reset() {
+	*x = AnalyzeReply{}
+	mi := &file_proto_words_words_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeReply) ProtoMessage() {}
+
+func (x *AnalyzeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeReply.ProtoReflect.Descriptor instead.
+func (*AnalyzeReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AnalyzeReply) GetTokens() []*Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type ReloadReply struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	StopWords      int64                  `protobuf:"varint,1,opt,name=stop_words,json=stopWords,proto3" json:"stop_words,omitempty"`
+	ProtectedWords int64                  `protobuf:"varint,2,opt,name=protected_words,json=protectedWords,proto3" json:"protected_words,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReloadReply) Reset() {
+	*x = ReloadReply{}
+	mi := &file_proto_words_words_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadReply) ProtoMessage() {}
+
+func (x *ReloadReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadReply.ProtoReflect.Descriptor instead.
+func (*ReloadReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReloadReply) GetStopWords() int64 {
+	if x != nil {
+		return x.StopWords
+	}
+	return 0
+}
+
+func (x *ReloadReply) GetProtectedWords() int64 {
+	if x != nil {
+		return x.ProtectedWords
+	}
+	return 0
+}
+
 var File_proto_words_words_proto protoreflect.FileDescriptor
 
 const file_proto_words_words_proto_rawDesc = "" +
 	"\n" +
-	"\x17proto/words/words.proto\x12\x05words\x1a\x1bgoogle/protobuf/empty.proto\"&\n" +
+	"\x17proto/words/words.proto\x12\x05words\x1a\x1bgoogle/protobuf/empty.proto\"B\n" +
 	"\fWordsRequest\x12\x16\n" +
-	"\x06phrase\x18\x01 \x01(\tR\x06phrase\"\"\n" +
+	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x1a\n" +
+	"\bshingles\x18\x02 \x01(\bR\bshingles\"\"\n" +
 	"\n" +
 	"WordsReply\x12\x14\n" +
-	"\x05words\x18\x01 \x03(\tR\x05words2s\n" +
+	"\x05words\x18\x01 \x03(\tR\x05words\"2\n" +
+	"\bDocument\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
+	"\x06phrase\x18\x02 \x01(\tR\x06phrase\"]\n" +
+	"\x10NormBatchRequest\x12-\n" +
+	"\tdocuments\x18\x01 \x03(\v2\x0f.words.DocumentR\tdocuments\x12\x1a\n" +
+	"\bshingles\x18\x02 \x01(\bR\bshingles\"5\n" +
+	"\rDocumentWords\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05words\x18\x02 \x03(\tR\x05words\"D\n" +
+	"\x0eNormBatchReply\x122\n" +
+	"\tdocuments\x18\x01 \x03(\v2\x14.words.DocumentWordsR\tdocuments\"\x98\x01\n" +
+	"\x05Token\x12\x1a\n" +
+	"\boriginal\x18\x01 \x01(\tR\boriginal\x12\x12\n" +
+	"\x04stem\x18\x02 \x01(\tR\x04stem\x12\x1a\n" +
+	"\bposition\x18\x03 \x01(\x05R\bposition\x12\x14\n" +
+	"\x05start\x18\x04 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x05 \x01(\x05R\x03end\x12\x1b\n" +
+	"\tstop_word\x18\x06 \x01(\bR\bstopWord\"4\n" +
+	"\fAnalyzeReply\x12$\n" +
+	"\x06tokens\x18\x01 \x03(\v2\f.words.TokenR\x06tokens\"U\n" +
+	"\vReloadReply\x12\x1d\n" +
+	"\n" +
+	"stop_words\x18\x01 \x01(\x03R\tstopWords\x12'\n" +
+	"\x0fprotected_words\x18\x02 \x01(\x03R\x0eprotectedWords2\xdb\x02\n" +
 	"\x05Words\x128\n" +
 	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x120\n" +
-	"\x04Norm\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00B\x1eZ\x1cyadro.com/course/proto/wordsb\x06proto3"
+	"\x04Norm\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00\x128\n" +
+	"\n" +
+	"NormStream\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00(\x01\x12=\n" +
+	"\tNormBatch\x12\x17.words.NormBatchRequest\x1a\x15.words.NormBatchReply\"\x00\x125\n" +
+	"\aAnalyze\x12\x13.words.WordsRequest\x1a\x13.words.AnalyzeReply\"\x00\x126\n" +
+	"\x06Reload\x12\x16.google.protobuf.Empty\x1a\x12.words.ReloadReply\"\x00B\x1eZ\x1cyadro.com/course/proto/wordsb\x06proto3"
 
 var (
 	file_proto_words_words_proto_rawDescOnce sync.Once
@@ -136,22 +558,40 @@ func file_proto_words_words_proto_rawDescGZIP() []byte {
 	return file_proto_words_words_proto_rawDescData
 }
 
-var file_proto_words_words_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_words_words_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_proto_words_words_proto_goTypes = []any{
-	(*WordsRequest)(nil),  // 0: words.WordsRequest
-	(*WordsReply)(nil),    // 1: words.WordsReply
-	(*emptypb.Empty)(nil), // 2: google.protobuf.Empty
+	(*WordsRequest)(nil),     // 0: words.WordsRequest
+	(*WordsReply)(nil),       // 1: words.WordsReply
+	(*Document)(nil),         // 2: words.Document
+	(*NormBatchRequest)(nil), // 3: words.NormBatchRequest
+	(*DocumentWords)(nil),    // 4: words.DocumentWords
+	(*NormBatchReply)(nil),   // 5: words.NormBatchReply
+	(*Token)(nil),            // 6: words.Token
+	(*AnalyzeReply)(nil),     // 7: words.AnalyzeReply
+	(*ReloadReply)(nil),      // 8: words.ReloadReply
+	(*emptypb.Empty)(nil),    // 9: google.protobuf.Empty
 }
 var file_proto_words_words_proto_depIdxs = []int32{
-	2, // 0: words.Words.Ping:input_type -> google.protobuf.Empty
-	0, // 1: words.Words.Norm:input_type -> words.WordsRequest
-	2, // 2: words.Words.Ping:output_type -> google.protobuf.Empty
-	1, // 3: words.Words.Norm:output_type -> words.WordsReply
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: words.NormBatchRequest.documents:type_name -> words.Document
+	4, // 1: words.NormBatchReply.documents:type_name -> words.DocumentWords
+	6, // 2: words.AnalyzeReply.tokens:type_name -> words.Token
+	9, // 3: words.Words.Ping:input_type -> google.protobuf.Empty
+	0, // 4: words.Words.Norm:input_type -> words.WordsRequest
+	0, // 5: words.Words.NormStream:input_type -> words.WordsRequest
+	3, // 6: words.Words.NormBatch:input_type -> words.NormBatchRequest
+	0, // 7: words.Words.Analyze:input_type -> words.WordsRequest
+	9, // 8: words.Words.Reload:input_type -> google.protobuf.Empty
+	9, // 9: words.Words.Ping:output_type -> google.protobuf.Empty
+	1, // 10: words.Words.Norm:output_type -> words.WordsReply
+	1, // 11: words.Words.NormStream:output_type -> words.WordsReply
+	5, // 12: words.Words.NormBatch:output_type -> words.NormBatchReply
+	7, // 13: words.Words.Analyze:output_type -> words.AnalyzeReply
+	8, // 14: words.Words.Reload:output_type -> words.ReloadReply
+	9, // [9:15] is the sub-list for method output_type
+	3, // [3:9] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_proto_words_words_proto_init() }
@@ -165,7 +605,7 @@ func file_proto_words_words_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_words_words_proto_rawDesc), len(file_proto_words_words_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
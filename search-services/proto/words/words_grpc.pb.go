@@ -20,8 +20,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Words_Ping_FullMethodName = "/words.Words/Ping"
-	Words_Norm_FullMethodName = "/words.Words/Norm"
+	Words_Ping_FullMethodName       = "/words.Words/Ping"
+	Words_Norm_FullMethodName       = "/words.Words/Norm"
+	Words_NormStream_FullMethodName = "/words.Words/NormStream"
+	Words_NormBatch_FullMethodName  = "/words.Words/NormBatch"
+	Words_Analyze_FullMethodName    = "/words.Words/Analyze"
+	Words_Reload_FullMethodName     = "/words.Words/Reload"
 )
 
 // WordsClient is the client API for Words service.
@@ -33,6 +37,15 @@ type WordsClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// Send name, receive greeting
 	Norm(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*WordsReply, error)
+	// Same as Norm for arbitrarily long text sent in chunks of up to 4 KiB,
+	// words may be split between chunks
+	NormStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[WordsRequest, WordsReply], error)
+	// Norm for many documents at once, each one up to 4 KiB
+	NormBatch(ctx context.Context, in *NormBatchRequest, opts ...grpc.CallOption) (*NormBatchReply, error)
+	// Same as Norm, but keeps every token in order with its original form
+	Analyze(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*AnalyzeReply, error)
+	// Re-read custom stop-words and protected words from config
+	Reload(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReloadReply, error)
 }
 
 type wordsClient struct {
@@ -63,6 +76,49 @@ func (c *wordsClient) Norm(ctx context.Context, in *WordsRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *wordsClient) NormStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[WordsRequest, WordsReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Words_ServiceDesc.Streams[0], Words_NormStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WordsRequest, WordsReply]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Words_NormStreamClient = grpc.ClientStreamingClient[WordsRequest, WordsReply]
+
+func (c *wordsClient) NormBatch(ctx context.Context, in *NormBatchRequest, opts ...grpc.CallOption) (*NormBatchReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NormBatchReply)
+	err := c.cc.Invoke(ctx, Words_NormBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) Analyze(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*AnalyzeReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeReply)
+	err := c.cc.Invoke(ctx, Words_Analyze_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) Reload(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReloadReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadReply)
+	err := c.cc.Invoke(ctx, Words_Reload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WordsServer is the server API for Words service.
 // All implementations must embed UnimplementedWordsServer
 // for forward compatibility.
@@ -72,6 +128,15 @@ type WordsServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	// Send name, receive greeting
 	Norm(context.Context, *WordsRequest) (*WordsReply, error)
+	// Same as Norm for arbitrarily long text sent in chunks of up to 4 KiB,
+	// words may be split between chunks
+	NormStream(grpc.ClientStreamingServer[WordsRequest, WordsReply]) error
+	// Norm for many documents at once, each one up to 4 KiB
+	NormBatch(context.Context, *NormBatchRequest) (*NormBatchReply, error)
+	// Same as Norm, but keeps every token in order with its original form
+	Analyze(context.Context, *WordsRequest) (*AnalyzeReply, error)
+	// Re-read custom stop-words and protected words from config
+	Reload(context.Context, *emptypb.Empty) (*ReloadReply, error)
 	mustEmbedUnimplementedWordsServer()
 }
 
@@ -88,6 +153,18 @@ func (UnimplementedWordsServer) Ping(context.Context, *emptypb.Empty) (*emptypb.
 func (UnimplementedWordsServer) Norm(context.Context, *WordsRequest) (*WordsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Norm not implemented")
 }
+func (UnimplementedWordsServer) NormStream(grpc.ClientStreamingServer[WordsRequest, WordsReply]) error {
+	return status.Errorf(codes.Unimplemented, "method NormStream not implemented")
+}
+func (UnimplementedWordsServer) NormBatch(context.Context, *NormBatchRequest) (*NormBatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NormBatch not implemented")
+}
+func (UnimplementedWordsServer) Analyze(context.Context, *WordsRequest) (*AnalyzeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Analyze not implemented")
+}
+func (UnimplementedWordsServer) Reload(context.Context, *emptypb.Empty) (*ReloadReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reload not implemented")
+}
 func (UnimplementedWordsServer) mustEmbedUnimplementedWordsServer() {}
 func (UnimplementedWordsServer) testEmbeddedByValue()               {}
 
@@ -145,6 +222,67 @@ func _Words_Norm_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Words_NormStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WordsServer).NormStream(&grpc.GenericServerStream[WordsRequest, WordsReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Words_NormStreamServer = grpc.ClientStreamingServer[WordsRequest, WordsReply]
+
+func _Words_NormBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NormBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).NormBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_NormBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).NormBatch(ctx, req.(*NormBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_Analyze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Analyze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Analyze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Analyze(ctx, req.(*WordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_Reload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Reload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Reload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Reload(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Words_ServiceDesc is the grpc.ServiceDesc for Words service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -160,7 +298,25 @@ var Words_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Norm",
 			Handler:    _Words_Norm_Handler,
 		},
+		{
+			MethodName: "NormBatch",
+			Handler:    _Words_NormBatch_Handler,
+		},
+		{
+			MethodName: "Analyze",
+			Handler:    _Words_Analyze_Handler,
+		},
+		{
+			MethodName: "Reload",
+			Handler:    _Words_Reload_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "NormStream",
+			Handler:       _Words_NormStream_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/words/words.proto",
 }
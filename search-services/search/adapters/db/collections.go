@@ -0,0 +1,204 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"yadro.com/course/search/core"
+)
+
+func (db *DB) Tags(ctx context.Context) ([]core.TagCount, error) {
+	const query = `SELECT TAG, COUNT(*) FROM comic_tags GROUP BY TAG ORDER BY COUNT(*) DESC, TAG`
+	rows, err := db.conn.QueryContext(ctx, query)
+	if err != nil {
+		db.log.Error("failed to list tags", "error", err)
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tags []core.TagCount
+	for rows.Next() {
+		var t core.TagCount
+		if err := rows.Scan(&t.Tag, &t.Comics); err != nil {
+			db.log.Error("failed to scan tag", "error", err)
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (db *DB) ComicTags(ctx context.Context, id int64) ([]string, error) {
+	tags := []string{}
+	const query = `SELECT TAG FROM comic_tags WHERE COMIC_ID = $1 ORDER BY TAG`
+	if err := db.conn.SelectContext(ctx, &tags, query, id); err != nil {
+		db.log.Error("failed to get comic tags", "id", id, "error", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (db *DB) AddTag(ctx context.Context, id int64, tag string) error {
+	const sqlStmt = `INSERT INTO comic_tags (COMIC_ID, TAG)
+	SELECT ID, $2 FROM comics WHERE ID = $1
+	ON CONFLICT DO NOTHING`
+	res, err := db.conn.ExecContext(ctx, sqlStmt, id, tag)
+	if err != nil {
+		db.log.Error("failed to tag comic", "id", id, "tag", tag, "error", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// already tagged or not stored
+		return db.exists(ctx, id)
+	}
+	return nil
+}
+
+func (db *DB) RemoveTag(ctx context.Context, id int64, tag string) error {
+	const sqlStmt = `DELETE FROM comic_tags WHERE COMIC_ID = $1 AND TAG = $2`
+	res, err := db.conn.ExecContext(ctx, sqlStmt, id, tag)
+	if err != nil {
+		db.log.Error("failed to untag comic", "id", id, "tag", tag, "error", err)
+		return err
+	}
+	return affected(res)
+}
+
+func (db *DB) Collections(ctx context.Context) ([]core.Collection, error) {
+	const query = `SELECT NAME, DESCRIPTION, CREATED_AT,
+		COALESCE((SELECT jsonb_agg(COMIC_ID ORDER BY COMIC_ID) FROM collection_comics c WHERE c.COLLECTION = collections.NAME), '[]')
+	FROM collections ORDER BY NAME`
+	rows, err := db.conn.QueryContext(ctx, query)
+	if err != nil {
+		db.log.Error("failed to list collections", "error", err)
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var collections []core.Collection
+	for rows.Next() {
+		c, err := db.scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+func (db *DB) Collection(ctx context.Context, name string) (core.Collection, error) {
+	const query = `SELECT NAME, DESCRIPTION, CREATED_AT,
+		COALESCE((SELECT jsonb_agg(COMIC_ID ORDER BY COMIC_ID) FROM collection_comics c WHERE c.COLLECTION = collections.NAME), '[]')
+	FROM collections WHERE NAME = $1`
+	c, err := db.scanCollection(db.conn.QueryRowContext(ctx, query, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Collection{}, core.ErrNotFound
+	}
+	return c, err
+}
+
+func (db *DB) scanCollection(row interface{ Scan(...any) error }) (core.Collection, error) {
+	var c core.Collection
+	var comics []byte
+	if err := row.Scan(&c.Name, &c.Description, &c.CreatedAt, &comics); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			db.log.Error("failed to scan collection", "error", err)
+		}
+		return core.Collection{}, err
+	}
+	if err := json.Unmarshal(comics, &c.Comics); err != nil {
+		db.log.Error("failed to unmarshal collection comics", "name", c.Name, "error", err)
+		return core.Collection{}, err
+	}
+	return c, nil
+}
+
+func (db *DB) SaveCollection(ctx context.Context, c core.Collection) error {
+	const sqlStmt = `INSERT INTO collections (NAME, DESCRIPTION) VALUES ($1, $2)
+	ON CONFLICT (NAME) DO UPDATE SET DESCRIPTION = EXCLUDED.DESCRIPTION`
+	if _, err := db.conn.ExecContext(ctx, sqlStmt, c.Name, c.Description); err != nil {
+		db.log.Error("failed to save collection", "name", c.Name, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (db *DB) DeleteCollection(ctx context.Context, name string) ([]int64, error) {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		db.log.Error("failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ids := []int64{}
+	if err := tx.SelectContext(ctx, &ids, `SELECT COMIC_ID FROM collection_comics WHERE COLLECTION = $1`, name); err != nil {
+		db.log.Error("failed to get collection comics", "name", name, "error", err)
+		return nil, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM collections WHERE NAME = $1`, name)
+	if err != nil {
+		db.log.Error("failed to delete collection", "name", name, "error", err)
+		return nil, err
+	}
+	if err := affected(res); err != nil {
+		return nil, err
+	}
+	return ids, tx.Commit()
+}
+
+func (db *DB) AddToCollection(ctx context.Context, name string, id int64) error {
+	const sqlStmt = `INSERT INTO collection_comics (COLLECTION, COMIC_ID)
+	SELECT c.NAME, comics.ID FROM collections c, comics WHERE c.NAME = $1 AND comics.ID = $2
+	ON CONFLICT DO NOTHING`
+	res, err := db.conn.ExecContext(ctx, sqlStmt, name, id)
+	if err != nil {
+		db.log.Error("failed to add comic to collection", "id", id, "name", name, "error", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// already in the collection, or the collection or comic is missing
+		if _, err := db.Collection(ctx, name); err != nil {
+			return err
+		}
+		return db.exists(ctx, id)
+	}
+	return nil
+}
+
+func (db *DB) RemoveFromCollection(ctx context.Context, name string, id int64) error {
+	const sqlStmt = `DELETE FROM collection_comics WHERE COLLECTION = $1 AND COMIC_ID = $2`
+	res, err := db.conn.ExecContext(ctx, sqlStmt, name, id)
+	if err != nil {
+		db.log.Error("failed to remove comic from collection", "id", id, "name", name, "error", err)
+		return err
+	}
+	return affected(res)
+}
+
+// exists returns core.ErrNotFound if the comic is not stored.
+func (db *DB) exists(ctx context.Context, id int64) error {
+	var ok bool
+	if err := db.conn.GetContext(ctx, &ok, `SELECT EXISTS (SELECT 1 FROM comics WHERE ID = $1)`, id); err != nil {
+		db.log.Error("failed to check comic", "id", id, "error", err)
+		return err
+	}
+	if !ok {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// affected returns core.ErrNotFound if the statement changed nothing.
+func affected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
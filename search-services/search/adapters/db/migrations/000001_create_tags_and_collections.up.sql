@@ -0,0 +1,25 @@
+-- no foreign keys to comics: tags and collections outlive dropped comics,
+-- and comics belong to update, which migrates on its own
+CREATE TABLE IF NOT EXISTS comic_tags (
+    COMIC_ID INTEGER NOT NULL,
+    TAG TEXT NOT NULL,
+    CREATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (COMIC_ID, TAG)
+);
+
+CREATE INDEX IF NOT EXISTS comic_tags_tag_idx ON comic_tags (TAG);
+
+CREATE TABLE IF NOT EXISTS collections (
+    NAME TEXT PRIMARY KEY,
+    DESCRIPTION TEXT NOT NULL DEFAULT '',
+    CREATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS collection_comics (
+    COLLECTION TEXT NOT NULL REFERENCES collections (NAME) ON DELETE CASCADE,
+    COMIC_ID INTEGER NOT NULL,
+    ADDED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (COLLECTION, COMIC_ID)
+);
+
+CREATE INDEX IF NOT EXISTS collection_comics_comic_idx ON collection_comics (COMIC_ID)
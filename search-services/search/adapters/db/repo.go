@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log/slog"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -27,21 +29,23 @@ func New(log *slog.Logger, address string) (*DB, error) {
 	}, nil
 }
 
-func (db *DB) Search(ctx context.Context, keywords []string, limit int) ([]core.Comic, int64, error) {
+func (db *DB) Search(ctx context.Context, keywords []string, filter core.Filter, limit int) ([]core.Comic, int64, error) {
 	query := `
 		SELECT ID, URL_ADRESS 
 		FROM comics 
-		WHERE WORDS ?| $1::text[] 
+		WHERE WORDS ?| $1::text[] AND NOT HIDDEN AND DELETED_AT IS NULL
+			AND ($5 = '' OR EXISTS (SELECT 1 FROM comic_tags t WHERE t.COMIC_ID = comics.ID AND t.TAG = $5))
+			AND ($6 = '' OR EXISTS (SELECT 1 FROM collection_comics c WHERE c.COMIC_ID = comics.ID AND c.COLLECTION = $6))
 		ORDER BY (
-			SELECT COUNT(*) 
+			SELECT COALESCE(SUM(CASE WHEN strpos(w, $3) > 0 THEN $4 ELSE 1 END), 0)
 			FROM jsonb_array_elements_text(WORDS) AS w 
 			WHERE w = ANY($1::text[])
 		) DESC
 		LIMIT $2
 	`
 
-	db.log.Debug("executing search query", "query", query, "keywords", keywords, "limit", limit)
-	rows, err := db.conn.QueryContext(ctx, query, keywords, limit)
+	db.log.Debug("executing search query", "query", query, "keywords", keywords, "filter", filter, "limit", limit)
+	rows, err := db.conn.QueryContext(ctx, query, keywords, limit, core.ShingleSep, core.ShingleWeight, filter.Tag, filter.Collection)
 	if err != nil {
 		db.log.Error("failed to search comics", "error", err)
 		return nil, 0, err
@@ -67,7 +71,8 @@ func (db *DB) Search(ctx context.Context, keywords []string, limit int) ([]core.
 }
 
 func (db *DB) Scan(ctx context.Context) ([]core.Comic, error) {
-	query := `SELECT ID, URL_ADRESS, WORDS FROM comics`
+	query := `SELECT ID, URL_ADRESS, WORDS, IMAGE_PHASH, ` + labelColumns + `
+	FROM comics WHERE NOT HIDDEN AND DELETED_AT IS NULL`
 	rows, err := db.conn.QueryContext(ctx, query)
 	if err != nil {
 		db.log.Error("failed to scan comics", "error", err)
@@ -83,10 +88,14 @@ func (db *DB) Scan(ctx context.Context) ([]core.Comic, error) {
 	for rows.Next() {
 		var c core.Comic
 		var wordsBytes []byte
-		if err := rows.Scan(&c.ID, &c.URL, &wordsBytes); err != nil {
+		var phash sql.NullInt64
+		var tags, collections []byte
+		if err := rows.Scan(&c.ID, &c.URL, &wordsBytes, &phash, &tags, &collections); err != nil {
 			db.log.Error("failed to scan comic for index", "error", err)
 			continue
 		}
+		c.PHash, c.HasPHash = uint64(phash.Int64), phash.Valid
+		db.labels(&c, tags, collections)
 
 		var words []string
 		if err := json.Unmarshal(wordsBytes, &words); err != nil {
@@ -98,3 +107,43 @@ func (db *DB) Scan(ctx context.Context) ([]core.Comic, error) {
 	}
 	return comics, nil
 }
+
+// Comic returns a visible comic, core.ErrNotFound if it is hidden or deleted.
+func (db *DB) Comic(ctx context.Context, id int64) (core.Comic, error) {
+	query := `SELECT ID, URL_ADRESS, WORDS, IMAGE_PHASH, ` + labelColumns + `
+	FROM comics WHERE ID = $1 AND NOT HIDDEN AND DELETED_AT IS NULL`
+
+	var c core.Comic
+	var wordsBytes []byte
+	var phash sql.NullInt64
+	var tags, collections []byte
+	err := db.conn.QueryRowContext(ctx, query, id).Scan(&c.ID, &c.URL, &wordsBytes, &phash, &tags, &collections)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Comic{}, core.ErrNotFound
+	}
+	if err != nil {
+		db.log.Error("failed to get comic", "id", id, "error", err)
+		return core.Comic{}, err
+	}
+
+	if err := json.Unmarshal(wordsBytes, &c.Keywords); err != nil {
+		db.log.Error("failed to unmarshal words", "id", c.ID, "error", err)
+	}
+	c.PHash, c.HasPHash = uint64(phash.Int64), phash.Valid
+	db.labels(&c, tags, collections)
+	return c, nil
+}
+
+// labelColumns selects tags and collections of a comic as JSON arrays.
+const labelColumns = `
+	COALESCE((SELECT jsonb_agg(TAG) FROM comic_tags t WHERE t.COMIC_ID = comics.ID), '[]'),
+	COALESCE((SELECT jsonb_agg(COLLECTION) FROM collection_comics c WHERE c.COMIC_ID = comics.ID), '[]')`
+
+func (db *DB) labels(c *core.Comic, tags, collections []byte) {
+	if err := json.Unmarshal(tags, &c.Tags); err != nil {
+		db.log.Error("failed to unmarshal tags", "id", c.ID, "error", err)
+	}
+	if err := json.Unmarshal(collections, &c.Collections); err != nil {
+		db.log.Error("failed to unmarshal collections", "id", c.ID, "error", err)
+	}
+}
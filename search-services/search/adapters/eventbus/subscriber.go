@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
 
 	"github.com/nats-io/nats.go"
 	"yadro.com/course/search/core"
@@ -43,6 +44,23 @@ func (s *Subscriber) Subscribe(ctx context.Context) error {
 	}
 	s.log.Info("subscribed to xkcd.db.updated")
 
+	_, err = s.nc.Subscribe("xkcd.comic.updated", func(msg *nats.Msg) {
+		id, err := strconv.ParseInt(string(msg.Data), 10, 64)
+		if err != nil {
+			s.log.Error("bad comic event", "data", string(msg.Data), "error", err)
+			return
+		}
+		s.log.Info("received comic event", "id", id)
+
+		if err := s.service.UpdateComic(context.Background(), id); err != nil {
+			s.log.Error("failed to update comic in index", "id", id, "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to comic updates: %w", err)
+	}
+	s.log.Info("subscribed to xkcd.comic.updated")
+
 	if err := s.nc.Flush(); err != nil {
 		return fmt.Errorf("failed to flush nats connection: %w", err)
 	}
@@ -2,8 +2,12 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"yadro.com/course/proto/search"
 	"yadro.com/course/search/core"
 )
@@ -18,9 +22,9 @@ func NewServer(service *core.Service) *Server {
 }
 
 func (s *Server) Search(ctx context.Context, req *search.SearchRequest) (*search.SearchResponse, error) {
-	res, err := s.service.Search(ctx, req.Phrase, int(req.Limit))
+	res, err := s.service.Search(ctx, req.Phrase, filter(req), int(req.Limit))
 	if err != nil {
-		return nil, err
+		return nil, serviceError(err)
 	}
 
 	var comics []*search.Comic
@@ -37,14 +41,18 @@ func (s *Server) Search(ctx context.Context, req *search.SearchRequest) (*search
 	}, nil
 }
 
+func filter(req *search.SearchRequest) core.Filter {
+	return core.Filter{Tag: req.GetTag(), Collection: req.GetCollection()}
+}
+
 func (s *Server) Ping(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
 	return &emptypb.Empty{}, nil
 }
 
 func (s *Server) ISearch(ctx context.Context, req *search.SearchRequest) (*search.SearchResponse, error) {
-	res, err := s.service.ISearch(ctx, req.Phrase, int(req.Limit))
+	res, err := s.service.ISearch(ctx, req.Phrase, filter(req), int(req.Limit))
 	if err != nil {
-		return nil, err
+		return nil, serviceError(err)
 	}
 
 	var comics []*search.Comic
@@ -60,3 +68,134 @@ func (s *Server) ISearch(ctx context.Context, req *search.SearchRequest) (*searc
 		Total:  res.Total,
 	}, nil
 }
+
+func (s *Server) Similar(ctx context.Context, req *search.SimilarRequest) (*search.SimilarResponse, error) {
+	res, err := s.service.Similar(ctx, req.Id, int(req.Limit))
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return similarResponse(res), nil
+}
+
+func (s *Server) Match(ctx context.Context, req *search.MatchRequest) (*search.SimilarResponse, error) {
+	res, err := s.service.Match(ctx, req.Image, int(req.Limit))
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return similarResponse(res), nil
+}
+
+func similarResponse(res []core.SimilarComic) *search.SimilarResponse {
+	comics := make([]*search.SimilarComic, 0, len(res))
+	for _, c := range res {
+		comics = append(comics, &search.SimilarComic{
+			Id:       c.ID,
+			Url:      c.URL,
+			Distance: int32(c.Distance),
+		})
+	}
+	return &search.SimilarResponse{Comics: comics}
+}
+
+func (s *Server) Tags(ctx context.Context, _ *emptypb.Empty) (*search.TagsResponse, error) {
+	tags, err := s.service.Tags(ctx)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	resp := &search.TagsResponse{Tags: make([]*search.TagCount, 0, len(tags))}
+	for _, t := range tags {
+		resp.Tags = append(resp.Tags, &search.TagCount{Tag: t.Tag, Comics: int64(t.Comics)})
+	}
+	return resp, nil
+}
+
+func (s *Server) ComicTags(ctx context.Context, req *search.ComicRequest) (*search.ComicTagsResponse, error) {
+	tags, err := s.service.ComicTags(ctx, req.GetId())
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return &search.ComicTagsResponse{Tags: tags}, nil
+}
+
+func (s *Server) AddTag(ctx context.Context, req *search.TagRequest) (*emptypb.Empty, error) {
+	if err := s.service.AddTag(ctx, req.GetId(), req.GetTag()); err != nil {
+		return nil, serviceError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) RemoveTag(ctx context.Context, req *search.TagRequest) (*emptypb.Empty, error) {
+	if err := s.service.RemoveTag(ctx, req.GetId(), req.GetTag()); err != nil {
+		return nil, serviceError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) Collections(ctx context.Context, _ *emptypb.Empty) (*search.CollectionsResponse, error) {
+	collections, err := s.service.Collections(ctx)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	resp := &search.CollectionsResponse{Collections: make([]*search.Collection, 0, len(collections))}
+	for _, c := range collections {
+		resp.Collections = append(resp.Collections, collection(c))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetCollection(ctx context.Context, req *search.CollectionRequest) (*search.Collection, error) {
+	c, err := s.service.Collection(ctx, req.GetName())
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return collection(c), nil
+}
+
+func collection(c core.Collection) *search.Collection {
+	return &search.Collection{
+		Name:        c.Name,
+		Description: c.Description,
+		Comics:      c.Comics,
+		CreatedAt:   timestamppb.New(c.CreatedAt),
+	}
+}
+
+func (s *Server) SaveCollection(ctx context.Context, req *search.Collection) (*emptypb.Empty, error) {
+	err := s.service.SaveCollection(ctx, core.Collection{Name: req.GetName(), Description: req.GetDescription()})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) DeleteCollection(ctx context.Context, req *search.CollectionRequest) (*emptypb.Empty, error) {
+	if err := s.service.DeleteCollection(ctx, req.GetName()); err != nil {
+		return nil, serviceError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) AddToCollection(ctx context.Context, req *search.CollectionComicRequest) (*emptypb.Empty, error) {
+	if err := s.service.AddToCollection(ctx, req.GetName(), req.GetId()); err != nil {
+		return nil, serviceError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) RemoveFromCollection(ctx context.Context, req *search.CollectionComicRequest) (*emptypb.Empty, error) {
+	if err := s.service.RemoveFromCollection(ctx, req.GetName(), req.GetId()); err != nil {
+		return nil, serviceError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, core.ErrBadArguments):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
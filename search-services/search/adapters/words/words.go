@@ -11,24 +11,27 @@ import (
 )
 
 type Client struct {
-	log    *slog.Logger
-	client wordspb.WordsClient
+	log      *slog.Logger
+	client   wordspb.WordsClient
+	shingles bool
 }
 
-func NewClient(address string, log *slog.Logger) (*Client, error) {
+func NewClient(address string, shingles bool, log *slog.Logger) (*Client, error) {
 	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, err
 	}
 	return &Client{
-		client: wordspb.NewWordsClient(conn),
-		log:    log,
+		client:   wordspb.NewWordsClient(conn),
+		log:      log,
+		shingles: shingles,
 	}, nil
 }
 
 func (c Client) Norm(ctx context.Context, phrase string) ([]string, error) {
 	req := &wordspb.WordsRequest{
-		Phrase: phrase,
+		Phrase:   phrase,
+		Shingles: c.shingles,
 	}
 	resp, err := c.client.Norm(ctx, req)
 	if err != nil {
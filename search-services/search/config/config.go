@@ -12,6 +12,7 @@ type Config struct {
 	Address       string        `yaml:"address" env:"SEARCH_ADDRESS" env-default:"0.0.0.0:8082"`
 	DBAddress     string        `yaml:"db_address" env:"DB_ADDRESS" env-required:"true"`
 	WordsAddress  string        `yaml:"words_address" env:"WORDS_ADDRESS" env-required:"true"`
+	WordsShingles bool          `yaml:"words_shingles" env:"WORDS_SHINGLES" env-default:"false"`
 	LogLevel      string        `yaml:"log_level" env:"LOG_LEVEL" env-default:"INFO"`
 	IndexTTL      time.Duration `yaml:"index_ttl" env:"INDEX_TTL" env-default:"20s"`
 	BrokerAddress string        `yaml:"broker_address" env:"BROKER_ADDRESS" env-default:"nats://nats:4222"`
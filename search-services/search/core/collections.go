@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxNameLen limits tags and collection names, in runes.
+const maxNameLen = 64
+
+// TagName normalizes a tag, tags are case insensitive.
+func TagName(tag string) (string, error) {
+	return name("tag", strings.ToLower(tag))
+}
+
+// CollectionName normalizes a collection name.
+func CollectionName(collection string) (string, error) {
+	return name("collection name", collection)
+}
+
+func name(what, s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || utf8.RuneCountInString(s) > maxNameLen {
+		return "", fmt.Errorf("%w: %s must be 1 to %d characters", ErrBadArguments, what, maxNameLen)
+	}
+	return s, nil
+}
+
+// clean normalizes names set in the filter.
+func (f Filter) clean() (Filter, error) {
+	var err error
+	if f.Tag != "" {
+		if f.Tag, err = TagName(f.Tag); err != nil {
+			return Filter{}, err
+		}
+	}
+	if f.Collection != "" {
+		if f.Collection, err = CollectionName(f.Collection); err != nil {
+			return Filter{}, err
+		}
+	}
+	return f, nil
+}
+
+func (s *Service) Tags(ctx context.Context) ([]TagCount, error) {
+	return s.db.Tags(ctx)
+}
+
+func (s *Service) ComicTags(ctx context.Context, id int64) ([]string, error) {
+	return s.db.ComicTags(ctx, id)
+}
+
+func (s *Service) AddTag(ctx context.Context, id int64, tag string) error {
+	tag, err := TagName(tag)
+	if err != nil {
+		return err
+	}
+	s.log.Info("tagging comic", "id", id, "tag", tag)
+	if err := s.db.AddTag(ctx, id, tag); err != nil {
+		return err
+	}
+	return s.UpdateComic(ctx, id)
+}
+
+func (s *Service) RemoveTag(ctx context.Context, id int64, tag string) error {
+	tag, err := TagName(tag)
+	if err != nil {
+		return err
+	}
+	s.log.Info("untagging comic", "id", id, "tag", tag)
+	if err := s.db.RemoveTag(ctx, id, tag); err != nil {
+		return err
+	}
+	return s.UpdateComic(ctx, id)
+}
+
+func (s *Service) Collections(ctx context.Context) ([]Collection, error) {
+	return s.db.Collections(ctx)
+}
+
+func (s *Service) Collection(ctx context.Context, name string) (Collection, error) {
+	name, err := CollectionName(name)
+	if err != nil {
+		return Collection{}, err
+	}
+	return s.db.Collection(ctx, name)
+}
+
+// SaveCollection creates a collection or changes its description.
+func (s *Service) SaveCollection(ctx context.Context, c Collection) error {
+	name, err := CollectionName(c.Name)
+	if err != nil {
+		return err
+	}
+	c.Name = name
+	c.Description = strings.TrimSpace(c.Description)
+	s.log.Info("saving collection", "name", c.Name)
+	return s.db.SaveCollection(ctx, c)
+}
+
+// DeleteCollection removes a collection, its comics are kept.
+func (s *Service) DeleteCollection(ctx context.Context, name string) error {
+	name, err := CollectionName(name)
+	if err != nil {
+		return err
+	}
+	s.log.Info("deleting collection", "name", name)
+	ids, err := s.db.DeleteCollection(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.UpdateComic(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) AddToCollection(ctx context.Context, name string, id int64) error {
+	name, err := CollectionName(name)
+	if err != nil {
+		return err
+	}
+	s.log.Info("adding comic to collection", "id", id, "collection", name)
+	if err := s.db.AddToCollection(ctx, name, id); err != nil {
+		return err
+	}
+	return s.UpdateComic(ctx, id)
+}
+
+func (s *Service) RemoveFromCollection(ctx context.Context, name string, id int64) error {
+	name, err := CollectionName(name)
+	if err != nil {
+		return err
+	}
+	s.log.Info("removing comic from collection", "id", id, "collection", name)
+	if err := s.db.RemoveFromCollection(ctx, name, id); err != nil {
+		return err
+	}
+	return s.UpdateComic(ctx, id)
+}
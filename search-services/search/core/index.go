@@ -1,10 +1,21 @@
 package core
 
 import (
+	"slices"
 	"sort"
+	"strings"
 	"sync"
+
+	"yadro.com/course/imagehash"
 )
 
+// ShingleSep joins two adjacent stems in a shingle keyword, e.g. "machin_learn".
+const ShingleSep = "_"
+
+// ShingleWeight makes a matched multi-word concept outrank its separate
+// words, in the index and in database search alike.
+const ShingleWeight = 2
+
 type Index struct {
 	mu    sync.RWMutex
 	items map[string][]int64 // keyword -> []comicID
@@ -42,7 +53,85 @@ func (i *Index) Add(comics []Comic) {
 	i.docs = newDocs
 }
 
-func (i *Index) Search(keywords []string) []Comic {
+// Put adds a comic to the index or replaces its keywords.
+func (i *Index) Put(comic Comic) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(comic.ID)
+	i.docs[comic.ID] = comic
+	for _, keyword := range comic.Keywords {
+		ids := i.items[keyword]
+		pos, found := slices.BinarySearch(ids, comic.ID)
+		if !found {
+			i.items[keyword] = slices.Insert(ids, pos, comic.ID)
+		}
+	}
+}
+
+// Remove drops a comic from the index.
+func (i *Index) Remove(id int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+}
+
+func (i *Index) remove(id int64) {
+	comic, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	for _, keyword := range comic.Keywords {
+		ids := i.items[keyword]
+		if pos, found := slices.BinarySearch(ids, id); found {
+			ids = slices.Delete(ids, pos, pos+1)
+		}
+		if len(ids) == 0 {
+			delete(i.items, keyword)
+		} else {
+			i.items[keyword] = ids
+		}
+	}
+	delete(i.docs, id)
+}
+
+// Get returns an indexed comic.
+func (i *Index) Get(id int64) (Comic, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	comic, ok := i.docs[id]
+	return comic, ok
+}
+
+// Similar returns comics with an image hash within maxDistance of hash,
+// closest first, leaving out the comic exclude.
+func (i *Index) Similar(hash uint64, maxDistance int, exclude int64) []SimilarComic {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var result []SimilarComic
+	for id, comic := range i.docs {
+		if id == exclude || !comic.HasPHash {
+			continue
+		}
+		if d := imagehash.Distance(hash, comic.PHash); d <= maxDistance {
+			result = append(result, SimilarComic{Comic: comic, Distance: d})
+		}
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Distance != result[b].Distance {
+			return result[a].Distance < result[b].Distance
+		}
+		return result[a].ID < result[b].ID
+	})
+	return result
+}
+
+// Search ranks comics matching the filter by keywords they have.
+func (i *Index) Search(keywords []string, filter Filter) []Comic {
 	i.mu.RLock()
 	defer i.mu.RUnlock()
 
@@ -53,9 +142,15 @@ func (i *Index) Search(keywords []string) []Comic {
 	matches := make(map[int64]int)
 
 	for _, kw := range keywords {
+		weight := 1
+		if strings.Contains(kw, ShingleSep) {
+			weight = ShingleWeight
+		}
 		if ids, ok := i.items[kw]; ok {
 			for _, id := range ids {
-				matches[id]++
+				if filter.match(i.docs[id]) {
+					matches[id] += weight
+				}
 			}
 		}
 	}
@@ -1,9 +1,53 @@
 package core
 
+import (
+	"slices"
+	"time"
+)
+
 type Comic struct {
 	ID       int64
 	URL      string
 	Keywords []string
+	// PHash is the perceptual hash of the comic image, if it has one.
+	PHash    uint64
+	HasPHash bool
+	// Tags and Collections the comic is in, used to filter search.
+	Tags        []string
+	Collections []string
+}
+
+// Filter narrows search to comics with a tag or in a collection,
+// empty fields do not restrict.
+type Filter struct {
+	Tag        string
+	Collection string
+}
+
+func (f Filter) match(c Comic) bool {
+	return (f.Tag == "" || slices.Contains(c.Tags, f.Tag)) &&
+		(f.Collection == "" || slices.Contains(c.Collections, f.Collection))
+}
+
+// TagCount is a tag and the number of comics tagged with it.
+type TagCount struct {
+	Tag    string
+	Comics int
+}
+
+// Collection is a named group of comics, e.g. "onboarding".
+type Collection struct {
+	Name        string
+	Description string
+	Comics      []int64
+	CreatedAt   time.Time
+}
+
+// SimilarComic is a comic found by image, Distance is the number of
+// differing perceptual hash bits.
+type SimilarComic struct {
+	Comic
+	Distance int
 }
 
 type SearchResult struct {
@@ -5,8 +5,29 @@ import (
 )
 
 type DB interface {
-	Search(ctx context.Context, keywords []string, limit int) ([]Comic, int64, error)
+	Search(ctx context.Context, keywords []string, filter Filter, limit int) ([]Comic, int64, error)
 	Scan(ctx context.Context) ([]Comic, error)
+	// Comic returns a visible comic or ErrNotFound.
+	Comic(ctx context.Context, id int64) (Comic, error)
+
+	// Tags lists all tags, most used first.
+	Tags(ctx context.Context) ([]TagCount, error)
+	ComicTags(ctx context.Context, id int64) ([]string, error)
+	// AddTag returns ErrNotFound if the comic is not stored.
+	AddTag(ctx context.Context, id int64, tag string) error
+	// RemoveTag returns ErrNotFound if the comic has no such tag.
+	RemoveTag(ctx context.Context, id int64, tag string) error
+
+	Collections(ctx context.Context) ([]Collection, error)
+	// Collection returns ErrNotFound for unknown collections.
+	Collection(ctx context.Context, name string) (Collection, error)
+	// SaveCollection creates a collection or changes its description.
+	SaveCollection(ctx context.Context, c Collection) error
+	// DeleteCollection returns comics that were in the collection.
+	DeleteCollection(ctx context.Context, name string) ([]int64, error)
+	// AddToCollection returns ErrNotFound for unknown collections or comics.
+	AddToCollection(ctx context.Context, name string, id int64) error
+	RemoveFromCollection(ctx context.Context, name string, id int64) error
 }
 
 type Words interface {
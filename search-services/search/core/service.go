@@ -1,9 +1,17 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
 	"log/slog"
+
+	"yadro.com/course/imagehash"
 )
 
 type Service struct {
@@ -22,7 +30,12 @@ func NewService(log *slog.Logger, db DB, words Words) *Service {
 	}
 }
 
-func (s *Service) Search(ctx context.Context, phrase string, limit int) (SearchResult, error) {
+func (s *Service) Search(ctx context.Context, phrase string, filter Filter, limit int) (SearchResult, error) {
+	filter, err := filter.clean()
+	if err != nil {
+		return SearchResult{}, err
+	}
+
 	s.log.Debug("normalizing phrase", "phrase", phrase)
 	keywords, err := s.words.Norm(ctx, phrase)
 	if err != nil {
@@ -33,8 +46,8 @@ func (s *Service) Search(ctx context.Context, phrase string, limit int) (SearchR
 		return SearchResult{}, nil
 	}
 
-	s.log.Debug("searching comics", "keywords", keywords, "limit", limit)
-	comics, total, err := s.db.Search(ctx, keywords, limit)
+	s.log.Debug("searching comics", "keywords", keywords, "filter", filter, "limit", limit)
+	comics, total, err := s.db.Search(ctx, keywords, filter, limit)
 	if err != nil {
 		return SearchResult{}, fmt.Errorf("failed to search comics: %w", err)
 	}
@@ -58,7 +71,30 @@ func (s *Service) BuildIndex(ctx context.Context) error {
 	return nil
 }
 
-func (s *Service) ISearch(ctx context.Context, phrase string, limit int) (SearchResult, error) {
+// UpdateComic brings a single changed comic in the index up to date,
+// dropping it if it was hidden or deleted.
+func (s *Service) UpdateComic(ctx context.Context, id int64) error {
+	comic, err := s.db.Comic(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		s.log.Info("removing comic from index", "id", id)
+		s.index.Remove(id)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get comic: %w", err)
+	}
+
+	s.log.Info("updating comic in index", "id", id)
+	s.index.Put(comic)
+	return nil
+}
+
+func (s *Service) ISearch(ctx context.Context, phrase string, filter Filter, limit int) (SearchResult, error) {
+	filter, err := filter.clean()
+	if err != nil {
+		return SearchResult{}, err
+	}
+
 	s.log.Debug("isearch: normalizing phrase", "phrase", phrase)
 	keywords, err := s.words.Norm(ctx, phrase)
 	if err != nil {
@@ -69,8 +105,8 @@ func (s *Service) ISearch(ctx context.Context, phrase string, limit int) (Search
 		return SearchResult{}, nil
 	}
 
-	s.log.Debug("isearch: searching index", "keywords", keywords)
-	foundComics := s.index.Search(keywords)
+	s.log.Debug("isearch: searching index", "keywords", keywords, "filter", filter)
+	foundComics := s.index.Search(keywords, filter)
 
 	s.log.Debug("isearch: found comics", "count", len(foundComics))
 
@@ -85,3 +121,52 @@ func (s *Service) ISearch(ctx context.Context, phrase string, limit int) (Search
 		Total:  total,
 	}, nil
 }
+
+// similarDistance is how many perceptual hash bits may differ for images
+// to look alike.
+const similarDistance = 10
+
+// Similar returns up to limit comics whose image looks like the image of
+// the comic id.
+func (s *Service) Similar(_ context.Context, id int64, limit int) ([]SimilarComic, error) {
+	comic, ok := s.index.Get(id)
+	if !ok || !comic.HasPHash {
+		return nil, fmt.Errorf("%w: comic %d has no indexed image", ErrNotFound, id)
+	}
+
+	similar := s.index.Similar(comic.PHash, similarDistance, id)
+	s.log.Debug("found visually similar comics", "id", id, "count", len(similar))
+	return truncate(similar, limit), nil
+}
+
+// maxMatchPixels bounds the size of pictures to match, a small upload may
+// declare a huge image and take gigabytes to decode.
+const maxMatchPixels = 4096 * 4096
+
+// Match finds up to limit comics looking like the given picture,
+// e.g. a screenshot.
+func (s *Service) Match(_ context.Context, data []byte, limit int) ([]SimilarComic, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode image: %v", ErrBadArguments, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxMatchPixels {
+		return nil, fmt.Errorf("%w: image of %dx%d is too large", ErrBadArguments, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode image: %v", ErrBadArguments, err)
+	}
+
+	matched := s.index.Similar(imagehash.DHash(img), similarDistance, 0)
+	s.log.Debug("matched comics by image", "count", len(matched))
+	return truncate(matched, limit), nil
+}
+
+func truncate(comics []SimilarComic, limit int) []SimilarComic {
+	if limit > 0 && len(comics) > limit {
+		return comics[:limit]
+	}
+	return comics
+}
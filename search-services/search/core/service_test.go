@@ -1,14 +1,21 @@
 package core_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"yadro.com/course/imagehash"
 	"yadro.com/course/search/core"
 )
 
@@ -16,8 +23,8 @@ type MockDB struct {
 	mock.Mock
 }
 
-func (m *MockDB) Search(ctx context.Context, keywords []string, limit int) ([]core.Comic, int64, error) {
-	args := m.Called(ctx, keywords, limit)
+func (m *MockDB) Search(ctx context.Context, keywords []string, filter core.Filter, limit int) ([]core.Comic, int64, error) {
+	args := m.Called(ctx, keywords, filter, limit)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(int64), args.Error(2)
 	}
@@ -32,6 +39,61 @@ func (m *MockDB) Scan(ctx context.Context) ([]core.Comic, error) {
 	return args.Get(0).([]core.Comic), args.Error(1)
 }
 
+func (m *MockDB) Comic(ctx context.Context, id int64) (core.Comic, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(core.Comic), args.Error(1)
+}
+
+func (m *MockDB) Tags(ctx context.Context) ([]core.TagCount, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]core.TagCount), args.Error(1)
+}
+
+func (m *MockDB) ComicTags(ctx context.Context, id int64) ([]string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDB) AddTag(ctx context.Context, id int64, tag string) error {
+	args := m.Called(ctx, id, tag)
+	return args.Error(0)
+}
+
+func (m *MockDB) RemoveTag(ctx context.Context, id int64, tag string) error {
+	args := m.Called(ctx, id, tag)
+	return args.Error(0)
+}
+
+func (m *MockDB) Collections(ctx context.Context) ([]core.Collection, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]core.Collection), args.Error(1)
+}
+
+func (m *MockDB) Collection(ctx context.Context, name string) (core.Collection, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(core.Collection), args.Error(1)
+}
+
+func (m *MockDB) SaveCollection(ctx context.Context, c core.Collection) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteCollection(ctx context.Context, name string) ([]int64, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockDB) AddToCollection(ctx context.Context, name string, id int64) error {
+	args := m.Called(ctx, name, id)
+	return args.Error(0)
+}
+
+func (m *MockDB) RemoveFromCollection(ctx context.Context, name string, id int64) error {
+	args := m.Called(ctx, name, id)
+	return args.Error(0)
+}
+
 type MockWords struct {
 	mock.Mock
 }
@@ -52,24 +114,24 @@ func TestSearch(t *testing.T) {
 	service := core.NewService(log, mockDB, mockWords)
 
 	mockWords.On("Norm", mock.Anything, "fail").Return(nil, errors.New("norm error")).Once()
-	_, err := service.Search(context.Background(), "fail", 10)
+	_, err := service.Search(context.Background(), "fail", core.Filter{}, 10)
 	assert.Error(t, err)
 
 	mockWords.On("Norm", mock.Anything, "empty").Return([]string{}, nil).Once()
-	res, err := service.Search(context.Background(), "empty", 10)
+	res, err := service.Search(context.Background(), "empty", core.Filter{}, 10)
 	assert.NoError(t, err)
 	assert.Empty(t, res.Comics)
 
 	mockWords.On("Norm", mock.Anything, "test").Return([]string{"test"}, nil).Once()
-	mockDB.On("Search", mock.Anything, []string{"test"}, 10).Return([]core.Comic{{ID: 1}}, int64(1), nil).Once()
-	res, err = service.Search(context.Background(), "test", 10)
+	mockDB.On("Search", mock.Anything, []string{"test"}, core.Filter{}, 10).Return([]core.Comic{{ID: 1}}, int64(1), nil).Once()
+	res, err = service.Search(context.Background(), "test", core.Filter{}, 10)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(res.Comics))
 	assert.Equal(t, int64(1), res.Total)
 
 	mockWords.On("Norm", mock.Anything, "dbfail").Return([]string{"dbfail"}, nil).Once()
-	mockDB.On("Search", mock.Anything, []string{"dbfail"}, 10).Return(nil, int64(0), errors.New("db error")).Once()
-	_, err = service.Search(context.Background(), "dbfail", 10)
+	mockDB.On("Search", mock.Anything, []string{"dbfail"}, core.Filter{}, 10).Return(nil, int64(0), errors.New("db error")).Once()
+	_, err = service.Search(context.Background(), "dbfail", core.Filter{}, 10)
 	assert.Error(t, err)
 }
 
@@ -86,18 +148,234 @@ func TestISearch(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockWords.On("Norm", mock.Anything, "test").Return([]string{"test"}, nil).Once()
-	res, err := service.ISearch(context.Background(), "test", 10)
+	res, err := service.ISearch(context.Background(), "test", core.Filter{}, 10)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(res.Comics))
 	assert.Equal(t, int64(1), res.Comics[0].ID)
 
 	mockWords.On("Norm", mock.Anything, "bar").Return([]string{"bar"}, nil).Once()
-	res, err = service.ISearch(context.Background(), "bar", 10)
+	res, err = service.ISearch(context.Background(), "bar", core.Filter{}, 10)
 	assert.NoError(t, err)
 	assert.Empty(t, res.Comics)
 
 	mockWords.On("Norm", mock.Anything, " ").Return([]string{}, nil).Once()
-	res, err = service.ISearch(context.Background(), " ", 10)
+	res, err = service.ISearch(context.Background(), " ", core.Filter{}, 10)
 	assert.NoError(t, err)
 	assert.Empty(t, res.Comics)
 }
+
+func TestISearch_Shingles(t *testing.T) {
+	mockDB := new(MockDB)
+	mockWords := new(MockWords)
+	service := core.NewService(log, mockDB, mockWords)
+
+	mockDB.On("Scan", mock.Anything).Return([]core.Comic{
+		{ID: 1, Keywords: []string{"machin", "learn", "deep"}},
+		{ID: 2, Keywords: []string{"machin", "learn", "machin_learn"}},
+	}, nil).Once()
+	assert.NoError(t, service.BuildIndex(context.Background()))
+
+	mockWords.On("Norm", mock.Anything, "deep machine learning").
+		Return([]string{"deep", "machin", "learn", "machin_learn"}, nil).Once()
+	res, err := service.ISearch(context.Background(), "deep machine learning", core.Filter{}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(res.Comics))
+	assert.Equal(t, int64(2), res.Comics[0].ID)
+}
+
+func TestUpdateComic(t *testing.T) {
+	mockDB := new(MockDB)
+	mockWords := new(MockWords)
+	service := core.NewService(log, mockDB, mockWords)
+
+	mockDB.On("Scan", mock.Anything).Return([]core.Comic{
+		{ID: 1, Keywords: []string{"foo"}},
+		{ID: 2, Keywords: []string{"foo", "bar"}},
+	}, nil).Once()
+	assert.NoError(t, service.BuildIndex(context.Background()))
+
+	mockDB.On("Comic", mock.Anything, int64(1)).Return(core.Comic{ID: 1, Keywords: []string{"bar"}}, nil).Once()
+	assert.NoError(t, service.UpdateComic(context.Background(), 1))
+
+	mockDB.On("Comic", mock.Anything, int64(2)).Return(core.Comic{}, core.ErrNotFound).Once()
+	assert.NoError(t, service.UpdateComic(context.Background(), 2))
+
+	mockWords.On("Norm", mock.Anything, "foo").Return([]string{"foo"}, nil).Once()
+	res, err := service.ISearch(context.Background(), "foo", core.Filter{}, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Comics)
+
+	mockWords.On("Norm", mock.Anything, "bar").Return([]string{"bar"}, nil).Once()
+	res, err = service.ISearch(context.Background(), "bar", core.Filter{}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(res.Comics))
+	assert.Equal(t, int64(1), res.Comics[0].ID)
+
+	mockDB.On("Comic", mock.Anything, int64(3)).Return(core.Comic{}, errors.New("db error")).Once()
+	assert.Error(t, service.UpdateComic(context.Background(), 3))
+	mockDB.AssertExpectations(t)
+}
+
+func TestSimilar(t *testing.T) {
+	mockDB := new(MockDB)
+	service := core.NewService(log, mockDB, new(MockWords))
+
+	mockDB.On("Scan", mock.Anything).Return([]core.Comic{
+		{ID: 1, PHash: 0b0000, HasPHash: true},
+		{ID: 2, PHash: 0b0111, HasPHash: true},
+		{ID: 3, PHash: 0b0001, HasPHash: true},
+		{ID: 4, PHash: ^uint64(0), HasPHash: true},
+		{ID: 5},
+	}, nil).Once()
+	assert.NoError(t, service.BuildIndex(context.Background()))
+
+	res, err := service.Similar(context.Background(), 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(res))
+	assert.Equal(t, int64(3), res[0].ID)
+	assert.Equal(t, 1, res[0].Distance)
+	assert.Equal(t, int64(2), res[1].ID)
+
+	res, err = service.Similar(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(res))
+
+	_, err = service.Similar(context.Background(), 5, 10)
+	assert.ErrorIs(t, err, core.ErrNotFound)
+	_, err = service.Similar(context.Background(), 42, 10)
+	assert.ErrorIs(t, err, core.ErrNotFound)
+}
+
+func TestMatch(t *testing.T) {
+	mockDB := new(MockDB)
+	service := core.NewService(log, mockDB, new(MockWords))
+
+	comic := image.NewGray(image.Rect(0, 0, 90, 80))
+	for i := range comic.Pix {
+		comic.Pix[i] = uint8(i * 7 % 251)
+	}
+	hash := imagehash.DHash(comic)
+
+	mockDB.On("Scan", mock.Anything).Return([]core.Comic{
+		{ID: 1, PHash: hash, HasPHash: true},
+		{ID: 2, PHash: ^hash, HasPHash: true},
+	}, nil).Once()
+	assert.NoError(t, service.BuildIndex(context.Background()))
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, comic))
+	res, err := service.Match(context.Background(), buf.Bytes(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, int64(1), res[0].ID)
+	assert.Equal(t, 0, res[0].Distance)
+
+	_, err = service.Match(context.Background(), []byte("not an image"), 10)
+	assert.ErrorIs(t, err, core.ErrBadArguments)
+
+	// a tiny PNG declaring 50000x50000 pixels is refused before decoding
+	huge := bytes.Clone(buf.Bytes())
+	binary.BigEndian.PutUint32(huge[16:], 50000) // IHDR width
+	binary.BigEndian.PutUint32(huge[20:], 50000) // IHDR height
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	_, err = service.Match(context.Background(), huge, 10)
+	assert.ErrorIs(t, err, core.ErrBadArguments)
+	assert.ErrorContains(t, err, "too large")
+}
+
+func TestSearch_Filter(t *testing.T) {
+	mockDB := new(MockDB)
+	mockWords := new(MockWords)
+	service := core.NewService(log, mockDB, mockWords)
+
+	mockWords.On("Norm", mock.Anything, "test").Return([]string{"test"}, nil)
+	mockDB.On("Search", mock.Anything, []string{"test"}, core.Filter{Tag: "security", Collection: "Talks"}, 10).
+		Return([]core.Comic{{ID: 1}}, int64(1), nil).Once()
+
+	res, err := service.Search(context.Background(), "test", core.Filter{Tag: " Security ", Collection: "Talks "}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.Total)
+
+	_, err = service.Search(context.Background(), "test", core.Filter{Tag: strings.Repeat("x", 65)}, 10)
+	assert.ErrorIs(t, err, core.ErrBadArguments)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestISearch_Filter(t *testing.T) {
+	mockDB := new(MockDB)
+	mockWords := new(MockWords)
+	service := core.NewService(log, mockDB, mockWords)
+
+	mockDB.On("Scan", mock.Anything).Return([]core.Comic{
+		{ID: 1, Keywords: []string{"test"}, Tags: []string{"security"}},
+		{ID: 2, Keywords: []string{"test"}, Collections: []string{"onboarding"}},
+		{ID: 3, Keywords: []string{"test"}},
+	}, nil).Once()
+	assert.NoError(t, service.BuildIndex(context.Background()))
+	mockWords.On("Norm", mock.Anything, "test").Return([]string{"test"}, nil)
+
+	res, err := service.ISearch(context.Background(), "test", core.Filter{}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.Total)
+
+	res, err = service.ISearch(context.Background(), "test", core.Filter{Tag: "SECURITY"}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.Total)
+	assert.Equal(t, int64(1), res.Comics[0].ID)
+
+	res, err = service.ISearch(context.Background(), "test", core.Filter{Collection: "onboarding"}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.Total)
+	assert.Equal(t, int64(2), res.Comics[0].ID)
+
+	res, err = service.ISearch(context.Background(), "test", core.Filter{Tag: "security", Collection: "onboarding"}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total)
+}
+
+func TestTags(t *testing.T) {
+	mockDB := new(MockDB)
+	mockWords := new(MockWords)
+	service := core.NewService(log, mockDB, mockWords)
+
+	mockDB.On("AddTag", mock.Anything, int64(1), "security").Return(nil).Once()
+	mockDB.On("AddTag", mock.Anything, int64(9), "x").Return(core.ErrNotFound).Once()
+	mockDB.On("Comic", mock.Anything, int64(1)).Return(core.Comic{ID: 1, Keywords: []string{"foo"}, Tags: []string{"security"}}, nil).Once()
+
+	assert.NoError(t, service.AddTag(context.Background(), 1, " Security"))
+	assert.ErrorIs(t, service.AddTag(context.Background(), 9, "x"), core.ErrNotFound)
+	assert.ErrorIs(t, service.AddTag(context.Background(), 1, " "), core.ErrBadArguments)
+
+	// the index picks up the tag at once
+	mockWords.On("Norm", mock.Anything, "foo").Return([]string{"foo"}, nil)
+	res, err := service.ISearch(context.Background(), "foo", core.Filter{Tag: "security"}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.Total)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestCollections(t *testing.T) {
+	mockDB := new(MockDB)
+	service := core.NewService(log, mockDB, nil)
+
+	mockDB.On("SaveCollection", mock.Anything, core.Collection{Name: "security talks", Description: "d"}).Return(nil).Once()
+	assert.NoError(t, service.SaveCollection(context.Background(), core.Collection{Name: " security talks ", Description: " d"}))
+	assert.ErrorIs(t, service.SaveCollection(context.Background(), core.Collection{}), core.ErrBadArguments)
+
+	mockDB.On("AddToCollection", mock.Anything, "security talks", int64(2)).Return(nil).Once()
+	mockDB.On("Comic", mock.Anything, int64(2)).Return(core.Comic{ID: 2}, nil).Once()
+	assert.NoError(t, service.AddToCollection(context.Background(), "security talks", 2))
+
+	// members are reindexed without the collection
+	mockDB.On("DeleteCollection", mock.Anything, "security talks").Return([]int64{2, 3}, nil).Once()
+	mockDB.On("Comic", mock.Anything, int64(2)).Return(core.Comic{ID: 2}, nil).Once()
+	mockDB.On("Comic", mock.Anything, int64(3)).Return(core.Comic{}, core.ErrNotFound).Once()
+	assert.NoError(t, service.DeleteCollection(context.Background(), "security talks"))
+
+	mockDB.On("DeleteCollection", mock.Anything, "nope").Return([]int64(nil), core.ErrNotFound).Once()
+	assert.ErrorIs(t, service.DeleteCollection(context.Background(), "nope"), core.ErrNotFound)
+
+	mockDB.AssertExpectations(t)
+}
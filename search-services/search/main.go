@@ -43,8 +43,11 @@ func run(cfg config.Config, log *slog.Logger) error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to db: %w", err)
 	}
+	if err := repo.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate db: %w", err)
+	}
 
-	wordsClient, err := words.NewClient(cfg.WordsAddress, log)
+	wordsClient, err := words.NewClient(cfg.WordsAddress, cfg.WordsShingles, log)
 	if err != nil {
 		return fmt.Errorf("failed to create words client: %w", err)
 	}
@@ -68,7 +71,8 @@ func run(cfg config.Config, log *slog.Logger) error {
 		return fmt.Errorf("failed to subscribe to events: %w", err)
 	}
 
-	s := grpc.NewServer()
+	// leave room for pictures uploaded to Match
+	s := grpc.NewServer(grpc.MaxRecvMsgSize(10 << 20))
 	searchpb.RegisterSearchServer(s, searchgrpc.NewServer(svc))
 	reflection.Register(s)
 
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS failed_comics;
+DROP TABLE IF EXISTS update_runs;
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE update_runs (
+    ID TEXT PRIMARY KEY,
+    TRIGGER TEXT NOT NULL,
+    MODE TEXT NOT NULL,
+    STATE TEXT NOT NULL,
+    STARTED_AT TIMESTAMPTZ NOT NULL,
+    FINISHED_AT TIMESTAMPTZ,
+    TOTAL INT NOT NULL DEFAULT 0,
+    FETCHED INT NOT NULL DEFAULT 0,
+    FAILED INT NOT NULL DEFAULT 0,
+    ERROR TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX update_runs_started_at_idx ON update_runs (STARTED_AT DESC);
+
+CREATE TABLE failed_comics (
+    ID INT PRIMARY KEY,
+    RUN_ID TEXT NOT NULL REFERENCES update_runs (ID) ON DELETE CASCADE,
+    ERROR TEXT NOT NULL,
+    ATTEMPTS INT NOT NULL DEFAULT 1,
+    FAILED_AT TIMESTAMPTZ NOT NULL
+)
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS comics_fetched_at_idx;
+
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS FETCHED_AT,
+    DROP COLUMN IF EXISTS CONTENT_HASH
@@ -0,0 +1,5 @@
+ALTER TABLE comics
+    ADD COLUMN FETCHED_AT TIMESTAMPTZ,
+    ADD COLUMN CONTENT_HASH TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX comics_fetched_at_idx ON comics (FETCHED_AT)
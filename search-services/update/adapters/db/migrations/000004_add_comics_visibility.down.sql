@@ -0,0 +1,3 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS HIDDEN,
+    DROP COLUMN IF EXISTS DELETED_AT
@@ -0,0 +1,3 @@
+ALTER TABLE comics
+    ADD COLUMN HIDDEN BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN DELETED_AT TIMESTAMPTZ
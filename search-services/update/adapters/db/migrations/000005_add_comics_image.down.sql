@@ -0,0 +1,4 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS IMAGE_HASH,
+    DROP COLUMN IF EXISTS IMAGE_WIDTH,
+    DROP COLUMN IF EXISTS IMAGE_HEIGHT
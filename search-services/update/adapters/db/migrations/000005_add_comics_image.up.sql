@@ -0,0 +1,4 @@
+ALTER TABLE comics
+    ADD COLUMN IMAGE_HASH TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IMAGE_WIDTH INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IMAGE_HEIGHT INTEGER NOT NULL DEFAULT 0
@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    ADD COLUMN IMAGE_PHASH BIGINT
@@ -0,0 +1,4 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS TITLE_TOKENS,
+    DROP COLUMN IF EXISTS ALT_TOKENS,
+    DROP COLUMN IF EXISTS TRANSCRIPT_TOKENS
@@ -0,0 +1,12 @@
+ALTER TABLE comics
+    ADD COLUMN TITLE_TOKENS INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN ALT_TOKENS INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN TRANSCRIPT_TOKENS INTEGER NOT NULL DEFAULT 0;
+
+UPDATE comics SET
+    TITLE_TOKENS = CASE WHEN btrim(COALESCE(TITLE, ''), E' \t\r\n') = '' THEN 0
+        ELSE array_length(regexp_split_to_array(btrim(TITLE, E' \t\r\n'), E'\\s+'), 1) END,
+    ALT_TOKENS = CASE WHEN btrim(COALESCE(ALT, ''), E' \t\r\n') = '' THEN 0
+        ELSE array_length(regexp_split_to_array(btrim(ALT, E' \t\r\n'), E'\\s+'), 1) END,
+    TRANSCRIPT_TOKENS = CASE WHEN btrim(COALESCE(TRANSCRIPT, ''), E' \t\r\n') = '' THEN 0
+        ELSE array_length(regexp_split_to_array(btrim(TRANSCRIPT, E' \t\r\n'), E'\\s+'), 1) END
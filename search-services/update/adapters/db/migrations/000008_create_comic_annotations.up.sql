@@ -0,0 +1,7 @@
+-- no foreign key: annotations outlive comics dropped or not fetched yet
+CREATE TABLE comic_annotations (
+    ID INTEGER PRIMARY KEY,
+    TRANSCRIPT TEXT NOT NULL DEFAULT '',
+    KEYWORDS JSONB NOT NULL DEFAULT '[]',
+    UPDATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)
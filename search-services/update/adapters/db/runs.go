@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"yadro.com/course/update/core"
+)
+
+type runRow struct {
+	ID         string       `db:"id"`
+	Trigger    string       `db:"trigger"`
+	Mode       string       `db:"mode"`
+	State      string       `db:"state"`
+	StartedAt  time.Time    `db:"started_at"`
+	FinishedAt sql.NullTime `db:"finished_at"`
+	Total      int          `db:"total"`
+	Fetched    int          `db:"fetched"`
+	Failed     int          `db:"failed"`
+	Error      string       `db:"error"`
+}
+
+type failedRow struct {
+	ID       int       `db:"id"`
+	RunID    string    `db:"run_id"`
+	Error    string    `db:"error"`
+	Attempts int       `db:"attempts"`
+	FailedAt time.Time `db:"failed_at"`
+}
+
+func (db *DB) SaveRun(ctx context.Context, run core.UpdateRun) error {
+	const sqlStmt = `INSERT INTO update_runs (ID, TRIGGER, MODE, STATE, STARTED_AT, FINISHED_AT, TOTAL, FETCHED, FAILED, ERROR)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (ID) DO UPDATE SET
+		STATE = EXCLUDED.STATE,
+		FINISHED_AT = EXCLUDED.FINISHED_AT,
+		TOTAL = EXCLUDED.TOTAL,
+		FETCHED = EXCLUDED.FETCHED,
+		FAILED = EXCLUDED.FAILED,
+		ERROR = EXCLUDED.ERROR;`
+	finishedAt := sql.NullTime{Time: run.FinishedAt, Valid: !run.FinishedAt.IsZero()}
+	_, err := db.conn.ExecContext(ctx, sqlStmt, run.ID, run.Trigger, run.Mode, run.State, run.StartedAt, finishedAt,
+		run.Total, run.Fetched, run.Failed, run.Error)
+	if err != nil {
+		db.log.Error("failed to save update run", "error", err, "run_id", run.ID)
+		return err
+	}
+	return nil
+}
+
+func (db *DB) Runs(ctx context.Context, limit int) ([]core.UpdateRun, error) {
+	const query = `SELECT ID, TRIGGER, MODE, STATE, STARTED_AT, FINISHED_AT, TOTAL, FETCHED, FAILED, ERROR
+	FROM update_runs ORDER BY STARTED_AT DESC LIMIT $1`
+	var rows []runRow
+	if err := db.conn.SelectContext(ctx, &rows, query, limit); err != nil {
+		db.log.Error("failed to fetch update runs", "error", err)
+		return nil, err
+	}
+
+	runs := make([]core.UpdateRun, 0, len(rows))
+	for _, r := range rows {
+		runs = append(runs, core.UpdateRun{
+			ID:         r.ID,
+			Trigger:    core.Trigger(r.Trigger),
+			Mode:       core.UpdateMode(r.Mode),
+			State:      core.JobState(r.State),
+			StartedAt:  r.StartedAt,
+			FinishedAt: r.FinishedAt.Time,
+			Total:      r.Total,
+			Fetched:    r.Fetched,
+			Failed:     r.Failed,
+			Error:      r.Error,
+		})
+	}
+	return runs, nil
+}
+
+func (db *DB) AddFailed(ctx context.Context, failed core.FailedComic) error {
+	const sqlStmt = `INSERT INTO failed_comics (ID, RUN_ID, ERROR, FAILED_AT) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (ID) DO UPDATE SET
+		RUN_ID = EXCLUDED.RUN_ID,
+		ERROR = EXCLUDED.ERROR,
+		ATTEMPTS = failed_comics.ATTEMPTS + 1,
+		FAILED_AT = EXCLUDED.FAILED_AT;`
+	_, err := db.conn.ExecContext(ctx, sqlStmt, failed.ID, failed.RunID, failed.Error, failed.FailedAt)
+	if err != nil {
+		db.log.Error("failed to record failed comic", "error", err, "comic_id", failed.ID)
+		return err
+	}
+	return nil
+}
+
+func (db *DB) Failed(ctx context.Context) ([]core.FailedComic, error) {
+	const query = `SELECT ID, RUN_ID, ERROR, ATTEMPTS, FAILED_AT FROM failed_comics ORDER BY ID`
+	var rows []failedRow
+	if err := db.conn.SelectContext(ctx, &rows, query); err != nil {
+		db.log.Error("failed to fetch failed comics", "error", err)
+		return nil, err
+	}
+
+	failed := make([]core.FailedComic, 0, len(rows))
+	for _, r := range rows {
+		failed = append(failed, core.FailedComic(r))
+	}
+	return failed, nil
+}
@@ -2,8 +2,12 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
@@ -30,14 +34,27 @@ func New(log *slog.Logger, address string) (*DB, error) {
 }
 
 func (db *DB) Add(ctx context.Context, comics core.Comics) error {
-	sqlStmt := `INSERT INTO comics (ID, URL_ADRESS, WORDS, TITLE, ALT, TRANSCRIPT, SAFE_TITLE) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	// the stored image is kept when the comic comes without one
+	sqlStmt := `INSERT INTO comics (ID, URL_ADRESS, WORDS, TITLE, ALT, TRANSCRIPT, SAFE_TITLE, CONTENT_HASH, FETCHED_AT,
+		IMAGE_HASH, IMAGE_WIDTH, IMAGE_HEIGHT, IMAGE_PHASH, TITLE_TOKENS, ALT_TOKENS, TRANSCRIPT_TOKENS)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), $9, $10, $11, $12, $13, $14, $15)
 	ON CONFLICT (ID) DO UPDATE SET
 		URL_ADRESS = EXCLUDED.URL_ADRESS,
 		WORDS = EXCLUDED.WORDS,
 		TITLE = EXCLUDED.TITLE,
 		ALT = EXCLUDED.ALT,
 		TRANSCRIPT = EXCLUDED.TRANSCRIPT,
-		SAFE_TITLE = EXCLUDED.SAFE_TITLE;`
+		SAFE_TITLE = EXCLUDED.SAFE_TITLE,
+		CONTENT_HASH = EXCLUDED.CONTENT_HASH,
+		FETCHED_AT = EXCLUDED.FETCHED_AT,
+		TITLE_TOKENS = EXCLUDED.TITLE_TOKENS,
+		ALT_TOKENS = EXCLUDED.ALT_TOKENS,
+		TRANSCRIPT_TOKENS = EXCLUDED.TRANSCRIPT_TOKENS,
+		IMAGE_HASH = COALESCE(NULLIF(EXCLUDED.IMAGE_HASH, ''), comics.IMAGE_HASH),
+		IMAGE_WIDTH = CASE WHEN EXCLUDED.IMAGE_HASH = '' THEN comics.IMAGE_WIDTH ELSE EXCLUDED.IMAGE_WIDTH END,
+		IMAGE_HEIGHT = CASE WHEN EXCLUDED.IMAGE_HASH = '' THEN comics.IMAGE_HEIGHT ELSE EXCLUDED.IMAGE_HEIGHT END,
+		IMAGE_PHASH = CASE WHEN EXCLUDED.IMAGE_HASH = '' THEN comics.IMAGE_PHASH ELSE EXCLUDED.IMAGE_PHASH END,
+		DELETED_AT = NULL;`
 	if comics.Words == nil {
 		comics.Words = []string{}
 	}
@@ -47,22 +64,43 @@ func (db *DB) Add(ctx context.Context, comics core.Comics) error {
 		return err
 	}
 
-	_, err = db.conn.ExecContext(ctx, sqlStmt, comics.ID, comics.URL, wordsJSON, comics.Title, comics.Alt, comics.Transcript, comics.SafeTitle)
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		db.log.Error("failed to begin transaction", "error", err, "comic_id", comics.ID)
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, sqlStmt, comics.ID, comics.URL, wordsJSON, comics.Title, comics.Alt, comics.Transcript, comics.SafeTitle, comics.ContentHash,
+		comics.Image.Hash, comics.Image.Width, comics.Image.Height,
+		sql.NullInt64{Int64: int64(comics.Image.PHash), Valid: comics.Image.HasPHash},
+		comics.Coverage.TitleTokens, comics.Coverage.AltTokens, comics.Coverage.TranscriptTokens)
 	if err != nil {
 		db.log.Error("failed to insert comic", "error", err, "comic_id", comics.ID)
 		return err
 	}
 
-	return nil
+	if _, err = tx.ExecContext(ctx, `DELETE FROM failed_comics WHERE ID = $1`, comics.ID); err != nil {
+		db.log.Error("failed to clear failed comic", "error", err, "comic_id", comics.ID)
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (db *DB) Stats(ctx context.Context) (core.DBStats, error) {
 	const sqlStmt = `SELECT
     COUNT(*) AS comics_fetched,
     COALESCE(SUM(jsonb_array_length(WORDS)), 0) AS words_total,
-    (SELECT COUNT(DISTINCT word) FROM comics, jsonb_array_elements_text(comics.words) AS word) AS words_unique
+    (SELECT COUNT(DISTINCT word) FROM comics, jsonb_array_elements_text(comics.words) AS word
+        WHERE comics.DELETED_AT IS NULL) AS words_unique,
+    COUNT(*) FILTER (WHERE TITLE_TOKENS = 0) AS no_title,
+    COUNT(*) FILTER (WHERE ALT_TOKENS = 0) AS no_alt,
+    COUNT(*) FILTER (WHERE TRANSCRIPT_TOKENS = 0) AS no_transcript
 FROM
-    comics;`
+    comics
+WHERE
+    DELETED_AT IS NULL;`
 	var stats core.DBStats
 	err := db.conn.GetContext(ctx, &stats, sqlStmt)
 	if err != nil {
@@ -73,6 +111,35 @@ FROM
 	return stats, nil
 }
 
+func (db *DB) Coverage(ctx context.Context, limit int) ([]core.ComicCoverage, int, error) {
+	const query = `SELECT ID, COALESCE(TITLE, '') AS TITLE, TITLE_TOKENS, ALT_TOKENS, TRANSCRIPT_TOKENS,
+		COUNT(*) OVER () AS TOTAL
+	FROM comics
+	WHERE TRANSCRIPT_TOKENS = 0 AND DELETED_AT IS NULL
+		AND NOT EXISTS (SELECT 1 FROM comic_annotations a WHERE a.ID = comics.ID AND a.TRANSCRIPT <> '')
+	ORDER BY TITLE_TOKENS + ALT_TOKENS, ID
+	LIMIT $1`
+
+	rows, err := db.conn.QueryContext(ctx, query, limit)
+	if err != nil {
+		db.log.Error("failed to get text coverage", "error", err)
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var comics []core.ComicCoverage
+	total := 0
+	for rows.Next() {
+		var c core.ComicCoverage
+		if err := rows.Scan(&c.ID, &c.Title, &c.TitleTokens, &c.AltTokens, &c.TranscriptTokens, &total); err != nil {
+			db.log.Error("failed to read coverage row", "error", err)
+			return nil, 0, err
+		}
+		comics = append(comics, c)
+	}
+	return comics, total, rows.Err()
+}
+
 func (db *DB) IDs(ctx context.Context) ([]int, error) {
 	var ids []int
 	const query = "SELECT ID FROM comics"
@@ -85,6 +152,251 @@ func (db *DB) IDs(ctx context.Context) ([]int, error) {
 	return ids, nil
 }
 
+func (db *DB) Count(ctx context.Context) (int, error) {
+	var count int
+	const query = "SELECT COUNT(*) FROM comics WHERE DELETED_AT IS NULL"
+	if err := db.conn.GetContext(ctx, &count, query); err != nil {
+		db.log.Error("failed to count comics", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (db *DB) Hashes(ctx context.Context, filter core.RefreshFilter) (map[int]string, error) {
+	query := `SELECT ID, CONTENT_HASH FROM comics WHERE DELETED_AT IS NULL`
+	var args []any
+	if filter.FromID > 0 {
+		args = append(args, filter.FromID)
+		query += fmt.Sprintf(" AND ID >= $%d", len(args))
+	}
+	if filter.ToID > 0 {
+		args = append(args, filter.ToID)
+		query += fmt.Sprintf(" AND ID <= $%d", len(args))
+	}
+	if filter.OlderThan > 0 {
+		args = append(args, time.Now().Add(-filter.OlderThan))
+		query += fmt.Sprintf(" AND (FETCHED_AT IS NULL OR FETCHED_AT < $%d)", len(args))
+	}
+
+	var rows []struct {
+		ID   int    `db:"id"`
+		Hash string `db:"content_hash"`
+	}
+	if err := db.conn.SelectContext(ctx, &rows, query, args...); err != nil {
+		db.log.Error("failed to fetch comic hashes", "error", err)
+		return nil, err
+	}
+
+	hashes := make(map[int]string, len(rows))
+	for _, r := range rows {
+		hashes[r.ID] = r.Hash
+	}
+	return hashes, nil
+}
+
+func (db *DB) Touch(ctx context.Context, id int) error {
+	const sqlStmt = `UPDATE comics SET FETCHED_AT = NOW() WHERE ID = $1`
+	if _, err := db.conn.ExecContext(ctx, sqlStmt, id); err != nil {
+		db.log.Error("failed to touch comic", "error", err, "comic_id", id)
+		return err
+	}
+	return nil
+}
+
+func (db *DB) Scan(ctx context.Context, fn func(core.Comics) error) error {
+	const query = `SELECT ID, URL_ADRESS, TITLE, ALT, TRANSCRIPT, SAFE_TITLE FROM comics
+	WHERE DELETED_AT IS NULL ORDER BY ID`
+	rows, err := db.conn.QueryContext(ctx, query)
+	if err != nil {
+		db.log.Error("failed to scan comics", "error", err)
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var comic core.Comics
+		var title, alt, transcript, safeTitle sql.NullString
+		if err := rows.Scan(&comic.ID, &comic.URL, &title, &alt, &transcript, &safeTitle); err != nil {
+			db.log.Error("failed to read comic row", "error", err)
+			return err
+		}
+		comic.Title = title.String
+		comic.Alt = alt.String
+		comic.Transcript = transcript.String
+		comic.SafeTitle = safeTitle.String
+
+		if err := fn(comic); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (db *DB) SetWords(ctx context.Context, id int, words []string) error {
+	wordsJSON, err := json.Marshal(words)
+	if err != nil {
+		db.log.Error("failed to marshal words to JSON", "error", err, "comic_id", id)
+		return err
+	}
+
+	const sqlStmt = `UPDATE comics SET WORDS = $2 WHERE ID = $1`
+	if _, err := db.conn.ExecContext(ctx, sqlStmt, id, wordsJSON); err != nil {
+		db.log.Error("failed to update comic words", "error", err, "comic_id", id)
+		return err
+	}
+	return nil
+}
+
+func (db *DB) Delete(ctx context.Context, id int) error {
+	const sqlStmt = `UPDATE comics SET DELETED_AT = NOW() WHERE ID = $1 AND DELETED_AT IS NULL`
+	res, err := db.conn.ExecContext(ctx, sqlStmt, id)
+	if err != nil {
+		db.log.Error("failed to delete comic", "error", err, "comic_id", id)
+		return err
+	}
+	return db.affected(res, id)
+}
+
+func (db *DB) SetHidden(ctx context.Context, id int, hidden bool) error {
+	const sqlStmt = `UPDATE comics SET HIDDEN = $2 WHERE ID = $1 AND DELETED_AT IS NULL`
+	res, err := db.conn.ExecContext(ctx, sqlStmt, id, hidden)
+	if err != nil {
+		db.log.Error("failed to change comic visibility", "error", err, "comic_id", id)
+		return err
+	}
+	return db.affected(res, id)
+}
+
+func (db *DB) Image(ctx context.Context, id int) (core.ComicImage, error) {
+	const query = `SELECT IMAGE_HASH, IMAGE_WIDTH, IMAGE_HEIGHT FROM comics
+	WHERE ID = $1 AND IMAGE_HASH <> '' AND NOT HIDDEN AND DELETED_AT IS NULL`
+	var img core.ComicImage
+	err := db.conn.QueryRowContext(ctx, query, id).Scan(&img.Hash, &img.Width, &img.Height)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.ComicImage{}, core.ErrNotFound
+	}
+	if err != nil {
+		db.log.Error("failed to get comic image", "error", err, "comic_id", id)
+		return core.ComicImage{}, err
+	}
+	return img, nil
+}
+
+func (db *DB) Comic(ctx context.Context, id int) (core.Comics, error) {
+	const query = `SELECT ID, URL_ADRESS, TITLE, ALT, TRANSCRIPT, SAFE_TITLE FROM comics WHERE ID = $1`
+	var comic core.Comics
+	var title, alt, transcript, safeTitle sql.NullString
+	err := db.conn.QueryRowContext(ctx, query, id).Scan(&comic.ID, &comic.URL, &title, &alt, &transcript, &safeTitle)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Comics{}, core.ErrNotFound
+	}
+	if err != nil {
+		db.log.Error("failed to get comic", "error", err, "comic_id", id)
+		return core.Comics{}, err
+	}
+	comic.Title = title.String
+	comic.Alt = alt.String
+	comic.Transcript = transcript.String
+	comic.SafeTitle = safeTitle.String
+	return comic, nil
+}
+
+type annotationRow struct {
+	ID         int       `db:"id"`
+	Transcript string    `db:"transcript"`
+	Keywords   []byte    `db:"keywords"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (r annotationRow) annotation() (core.Annotation, error) {
+	note := core.Annotation{ID: r.ID, Transcript: r.Transcript, UpdatedAt: r.UpdatedAt}
+	if err := json.Unmarshal(r.Keywords, &note.Keywords); err != nil {
+		return core.Annotation{}, fmt.Errorf("failed to decode keywords of comic %d: %w", r.ID, err)
+	}
+	return note, nil
+}
+
+func (db *DB) Annotations(ctx context.Context, ids []int) (map[int]core.Annotation, error) {
+	const query = `SELECT ID, TRANSCRIPT, KEYWORDS, UPDATED_AT FROM comic_annotations WHERE ID = ANY($1)`
+	var rows []annotationRow
+	if err := db.conn.SelectContext(ctx, &rows, query, ids); err != nil {
+		db.log.Error("failed to get annotations", "error", err)
+		return nil, err
+	}
+
+	notes := make(map[int]core.Annotation, len(rows))
+	for _, r := range rows {
+		note, err := r.annotation()
+		if err != nil {
+			db.log.Error("failed to read annotation", "error", err, "comic_id", r.ID)
+			return nil, err
+		}
+		notes[r.ID] = note
+	}
+	return notes, nil
+}
+
+func (db *DB) Annotation(ctx context.Context, id int) (core.Annotation, error) {
+	const query = `SELECT ID, TRANSCRIPT, KEYWORDS, UPDATED_AT FROM comic_annotations WHERE ID = $1`
+	var row annotationRow
+	err := db.conn.GetContext(ctx, &row, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Annotation{}, core.ErrNotFound
+	}
+	if err != nil {
+		db.log.Error("failed to get annotation", "error", err, "comic_id", id)
+		return core.Annotation{}, err
+	}
+	return row.annotation()
+}
+
+func (db *DB) SetAnnotation(ctx context.Context, note core.Annotation) error {
+	const sqlStmt = `INSERT INTO comic_annotations (ID, TRANSCRIPT, KEYWORDS, UPDATED_AT)
+	VALUES ($1, $2, $3, NOW())
+	ON CONFLICT (ID) DO UPDATE SET
+		TRANSCRIPT = EXCLUDED.TRANSCRIPT,
+		KEYWORDS = EXCLUDED.KEYWORDS,
+		UPDATED_AT = EXCLUDED.UPDATED_AT`
+	if note.Keywords == nil {
+		note.Keywords = []string{}
+	}
+	keywordsJSON, err := json.Marshal(note.Keywords)
+	if err != nil {
+		db.log.Error("failed to marshal keywords to JSON", "error", err, "comic_id", note.ID)
+		return err
+	}
+	if _, err := db.conn.ExecContext(ctx, sqlStmt, note.ID, note.Transcript, keywordsJSON); err != nil {
+		db.log.Error("failed to save annotation", "error", err, "comic_id", note.ID)
+		return err
+	}
+	return nil
+}
+
+func (db *DB) DeleteAnnotation(ctx context.Context, id int) error {
+	const sqlStmt = `DELETE FROM comic_annotations WHERE ID = $1`
+	res, err := db.conn.ExecContext(ctx, sqlStmt, id)
+	if err != nil {
+		db.log.Error("failed to delete annotation", "error", err, "comic_id", id)
+		return err
+	}
+	return db.affected(res, id)
+}
+
+// affected returns core.ErrNotFound if the statement changed no comic.
+func (db *DB) affected(res sql.Result, id int) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		db.log.Error("failed to get affected rows", "error", err, "comic_id", id)
+		return err
+	}
+	if n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// Drop removes all comics. Annotations are kept and merged into
+// the comics once they are fetched again.
 func (db *DB) Drop(ctx context.Context) error {
 	const sqlStmt = `TRUNCATE TABLE comics`
 	_, err := db.conn.ExecContext(ctx, sqlStmt)
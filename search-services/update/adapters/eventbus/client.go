@@ -3,6 +3,7 @@ package eventbus
 import (
 	"fmt"
 	"log/slog"
+	"strconv"
 
 	"github.com/nats-io/nats.go"
 )
@@ -32,6 +33,15 @@ func (c *Client) PublishUpdate() error {
 	return c.nc.Flush()
 }
 
+func (c *Client) PublishComic(id int) error {
+	c.log.Debug("publishing xkcd.comic.updated event", "id", id)
+	err := c.nc.Publish("xkcd.comic.updated", []byte(strconv.Itoa(id)))
+	if err != nil {
+		return fmt.Errorf("failed to publish comic event: %w", err)
+	}
+	return c.nc.Flush()
+}
+
 func (c *Client) Close() {
 	if c.nc != nil {
 		c.nc.Close()
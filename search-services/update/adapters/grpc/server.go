@@ -2,21 +2,31 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	updatepb "yadro.com/course/proto/update"
 	"yadro.com/course/update/core"
 )
 
-func NewServer(service core.Updater) *Server {
-	return &Server{service: service}
+// defaultRunsLimit is how many runs are listed when the limit is not set.
+const defaultRunsLimit = 20
+
+// defaultCoverageLimit is how many comics are listed when the limit is not set.
+const defaultCoverageLimit = 50
+
+func NewServer(service core.Updater, scheduler core.Scheduler) *Server {
+	return &Server{service: service, scheduler: scheduler}
 }
 
 type Server struct {
 	updatepb.UnimplementedUpdateServer
-	service core.Updater
+	service   core.Updater
+	scheduler core.Scheduler
 }
 
 func (s *Server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
@@ -34,30 +44,269 @@ func (s *Server) Status(ctx context.Context, _ *emptypb.Empty) (*updatepb.Status
 	default:
 		pbStatus = updatepb.Status_STATUS_IDLE // Default to idle or handle error if needed
 	}
-	return &updatepb.StatusReply{Status: pbStatus}, nil
+	reply := &updatepb.StatusReply{Status: pbStatus}
+	if next := s.scheduler.NextRun(); !next.IsZero() {
+		reply.NextRun = timestamppb.New(next)
+	}
+	return reply, nil
+}
+
+func (s *Server) Update(ctx context.Context, in *updatepb.UpdateRequest) (*updatepb.UpdateReply, error) {
+	opts := core.UpdateOptions{Mode: core.ModeNew}
+	switch in.GetMode() {
+	case updatepb.UpdateMode_UPDATE_MODE_FAILED:
+		opts.Mode = core.ModeFailed
+	case updatepb.UpdateMode_UPDATE_MODE_REFRESH:
+		opts.Mode = core.ModeRefresh
+		opts.Refresh = core.RefreshFilter{
+			FromID:    int(in.GetFromId()),
+			ToID:      int(in.GetToId()),
+			OlderThan: in.GetOlderThan().AsDuration(),
+		}
+	case updatepb.UpdateMode_UPDATE_MODE_REINDEX:
+		opts.Mode = core.ModeReindex
+	}
+
+	id, err := s.service.Update(ctx, core.TriggerManual, opts)
+	if errors.Is(err, core.ErrUpdateInProgress) {
+		return &updatepb.UpdateReply{JobId: id, AlreadyRunning: true}, nil
+	}
+	if errors.Is(err, core.ErrBadArguments) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &updatepb.UpdateReply{JobId: id}, nil
+}
+
+func (s *Server) Reindex(ctx context.Context, _ *emptypb.Empty) (*updatepb.UpdateReply, error) {
+	id, err := s.service.Reindex(ctx, core.TriggerManual)
+	if errors.Is(err, core.ErrUpdateInProgress) {
+		return &updatepb.UpdateReply{JobId: id, AlreadyRunning: true}, nil
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &updatepb.UpdateReply{JobId: id}, nil
+}
+
+func (s *Server) Progress(ctx context.Context, in *updatepb.JobRequest) (*updatepb.ProgressReply, error) {
+	progress, err := s.service.Progress(ctx, in.GetJobId())
+	if errors.Is(err, core.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return progressReply(progress), nil
 }
 
-func (s *Server) Update(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
-	err := s.service.Update(ctx)
+func (s *Server) Cancel(ctx context.Context, in *updatepb.JobRequest) (*emptypb.Empty, error) {
+	err := s.service.Cancel(ctx, in.GetJobId())
+	if errors.Is(err, core.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	return &emptypb.Empty{}, nil
 }
 
+func (s *Server) WatchUpdate(_ *emptypb.Empty, stream updatepb.Update_WatchUpdateServer) error {
+	for event := range s.service.Watch(stream.Context()) {
+		err := stream.Send(&updatepb.UpdateEvent{
+			Type:     eventType(event.Type),
+			ComicId:  int64(event.ComicID),
+			Error:    event.Error,
+			Progress: progressReply(event.Progress),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func progressReply(progress core.JobProgress) *updatepb.ProgressReply {
+	reply := &updatepb.ProgressReply{
+		JobId:    progress.ID,
+		State:    jobState(progress.State),
+		Total:    int64(progress.Total),
+		Fetched:  int64(progress.Fetched),
+		Failed:   int64(progress.Failed),
+		InFlight: int64(progress.InFlight),
+		Error:    progress.Error,
+	}
+	if progress.ETA > 0 {
+		reply.Eta = durationpb.New(progress.ETA)
+	}
+	if !progress.StartedAt.IsZero() {
+		reply.StartedAt = timestamppb.New(progress.StartedAt)
+	}
+	if !progress.FinishedAt.IsZero() {
+		reply.FinishedAt = timestamppb.New(progress.FinishedAt)
+	}
+	return reply
+}
+
+func eventType(t core.EventType) updatepb.EventType {
+	switch t {
+	case core.EventProgress:
+		return updatepb.EventType_EVENT_TYPE_PROGRESS
+	case core.EventStarted:
+		return updatepb.EventType_EVENT_TYPE_STARTED
+	case core.EventFetched:
+		return updatepb.EventType_EVENT_TYPE_FETCHED
+	case core.EventFailed:
+		return updatepb.EventType_EVENT_TYPE_FAILED
+	case core.EventSaved:
+		return updatepb.EventType_EVENT_TYPE_SAVED
+	case core.EventFinished:
+		return updatepb.EventType_EVENT_TYPE_FINISHED
+	case core.EventSkipped:
+		return updatepb.EventType_EVENT_TYPE_SKIPPED
+	default:
+		return updatepb.EventType_EVENT_TYPE_UNSPECIFIED
+	}
+}
+
+func trigger(t core.Trigger) updatepb.Trigger {
+	switch t {
+	case core.TriggerManual:
+		return updatepb.Trigger_TRIGGER_MANUAL
+	case core.TriggerSchedule:
+		return updatepb.Trigger_TRIGGER_SCHEDULE
+	default:
+		return updatepb.Trigger_TRIGGER_UNSPECIFIED
+	}
+}
+
+func updateMode(mode core.UpdateMode) updatepb.UpdateMode {
+	switch mode {
+	case core.ModeNew:
+		return updatepb.UpdateMode_UPDATE_MODE_NEW
+	case core.ModeFailed:
+		return updatepb.UpdateMode_UPDATE_MODE_FAILED
+	case core.ModeRefresh:
+		return updatepb.UpdateMode_UPDATE_MODE_REFRESH
+	case core.ModeReindex:
+		return updatepb.UpdateMode_UPDATE_MODE_REINDEX
+	default:
+		return updatepb.UpdateMode_UPDATE_MODE_UNSPECIFIED
+	}
+}
+
+func jobState(state core.JobState) updatepb.JobState {
+	switch state {
+	case core.JobRunning:
+		return updatepb.JobState_JOB_STATE_RUNNING
+	case core.JobDone:
+		return updatepb.JobState_JOB_STATE_DONE
+	case core.JobFailed:
+		return updatepb.JobState_JOB_STATE_FAILED
+	case core.JobCanceled:
+		return updatepb.JobState_JOB_STATE_CANCELED
+	default:
+		return updatepb.JobState_JOB_STATE_UNSPECIFIED
+	}
+}
+
 func (s *Server) Stats(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatsReply, error) {
 	stats, err := s.service.Stats(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	return &updatepb.StatsReply{
-		WordsTotal:    int64(stats.WordsTotal),
-		WordsUnique:   int64(stats.WordsUnique),
-		ComicsFetched: int64(stats.ComicsFetched),
-		ComicsTotal:   int64(stats.ComicsTotal),
+		WordsTotal:         int64(stats.WordsTotal),
+		WordsUnique:        int64(stats.WordsUnique),
+		ComicsFetched:      int64(stats.ComicsFetched),
+		ComicsTotal:        int64(stats.ComicsTotal),
+		LatestCheckedAt:    timestamppb.New(stats.LatestCheckedAt),
+		ComicsNoTitle:      int64(stats.NoTitle),
+		ComicsNoAlt:        int64(stats.NoAlt),
+		ComicsNoTranscript: int64(stats.NoTranscript),
 	}, nil
 }
 
+func (s *Server) Coverage(ctx context.Context, in *updatepb.CoverageRequest) (*updatepb.CoverageReply, error) {
+	limit := int(in.GetLimit())
+	if limit == 0 {
+		limit = defaultCoverageLimit
+	}
+
+	comics, total, err := s.service.Coverage(ctx, limit)
+	if errors.Is(err, core.ErrBadArguments) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	reply := &updatepb.CoverageReply{
+		Comics: make([]*updatepb.ComicCoverage, 0, len(comics)),
+		Total:  int64(total),
+	}
+	for _, c := range comics {
+		reply.Comics = append(reply.Comics, &updatepb.ComicCoverage{
+			Id:               int64(c.ID),
+			Title:            c.Title,
+			TitleTokens:      int64(c.TitleTokens),
+			AltTokens:        int64(c.AltTokens),
+			TranscriptTokens: int64(c.TranscriptTokens),
+		})
+	}
+	return reply, nil
+}
+
+func (s *Server) Runs(ctx context.Context, in *updatepb.RunsRequest) (*updatepb.RunsReply, error) {
+	limit := int(in.GetLimit())
+	if limit == 0 {
+		limit = defaultRunsLimit
+	}
+
+	runs, failed, err := s.service.Runs(ctx, limit)
+	if errors.Is(err, core.ErrBadArguments) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	reply := &updatepb.RunsReply{
+		Runs:   make([]*updatepb.Run, 0, len(runs)),
+		Failed: make([]*updatepb.FailedComic, 0, len(failed)),
+	}
+	for _, run := range runs {
+		r := &updatepb.Run{
+			JobId:     run.ID,
+			Trigger:   trigger(run.Trigger),
+			Mode:      updateMode(run.Mode),
+			State:     jobState(run.State),
+			StartedAt: timestamppb.New(run.StartedAt),
+			Total:     int64(run.Total),
+			Fetched:   int64(run.Fetched),
+			Failed:    int64(run.Failed),
+			Error:     run.Error,
+		}
+		if !run.FinishedAt.IsZero() {
+			r.FinishedAt = timestamppb.New(run.FinishedAt)
+		}
+		reply.Runs = append(reply.Runs, r)
+	}
+	for _, f := range failed {
+		reply.Failed = append(reply.Failed, &updatepb.FailedComic{
+			ComicId:  int64(f.ID),
+			JobId:    f.RunID,
+			Error:    f.Error,
+			Attempts: int64(f.Attempts),
+			FailedAt: timestamppb.New(f.FailedAt),
+		})
+	}
+	return reply, nil
+}
+
 func (s *Server) Drop(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
 	err := s.service.Drop(ctx)
 	if err != nil {
@@ -65,3 +314,82 @@ func (s *Server) Drop(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, er
 	}
 	return &emptypb.Empty{}, nil
 }
+
+func (s *Server) DeleteComic(ctx context.Context, in *updatepb.ComicRequest) (*emptypb.Empty, error) {
+	if err := s.service.DeleteComic(ctx, int(in.GetId())); err != nil {
+		return nil, comicError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) HideComic(ctx context.Context, in *updatepb.HideRequest) (*emptypb.Empty, error) {
+	if err := s.service.HideComic(ctx, int(in.GetId()), in.GetHidden()); err != nil {
+		return nil, comicError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) RefetchComic(ctx context.Context, in *updatepb.ComicRequest) (*emptypb.Empty, error) {
+	if err := s.service.RefetchComic(ctx, int(in.GetId())); err != nil {
+		return nil, comicError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) Image(ctx context.Context, in *updatepb.ComicRequest) (*updatepb.ImageReply, error) {
+	img, data, err := s.service.Image(ctx, int(in.GetId()))
+	if err != nil {
+		return nil, comicError(err)
+	}
+	return &updatepb.ImageReply{
+		Data:   data,
+		Hash:   img.Hash,
+		Width:  int32(img.Width),
+		Height: int32(img.Height),
+	}, nil
+}
+
+func (s *Server) GetAnnotation(ctx context.Context, in *updatepb.ComicRequest) (*updatepb.Annotation, error) {
+	note, err := s.service.Annotation(ctx, int(in.GetId()))
+	if err != nil {
+		return nil, comicError(err)
+	}
+	return &updatepb.Annotation{
+		Id:         int64(note.ID),
+		Transcript: note.Transcript,
+		Keywords:   note.Keywords,
+		UpdatedAt:  timestamppb.New(note.UpdatedAt),
+	}, nil
+}
+
+func (s *Server) SetAnnotation(ctx context.Context, in *updatepb.Annotation) (*emptypb.Empty, error) {
+	err := s.service.Annotate(ctx, core.Annotation{
+		ID:         int(in.GetId()),
+		Transcript: in.GetTranscript(),
+		Keywords:   in.GetKeywords(),
+	})
+	if err != nil {
+		return nil, comicError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) DeleteAnnotation(ctx context.Context, in *updatepb.ComicRequest) (*emptypb.Empty, error) {
+	if err := s.service.DeleteAnnotation(ctx, int(in.GetId())); err != nil {
+		return nil, comicError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func comicError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, core.ErrBadArguments):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, core.ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,72 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"yadro.com/course/update/core"
+)
+
+// Store keeps images in a directory, each in a file named by its hash
+// and sharded by the first two hash characters.
+type Store struct {
+	root string
+}
+
+func NewStore(root string) (*Store, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create image directory: %w", err)
+	}
+	return &Store{root: root}, nil
+}
+
+func (s *Store) Put(_ context.Context, hash string, data []byte) error {
+	name, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(name); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// write aside and rename, so readers never see a partial image
+	tmp, err := os.CreateTemp(filepath.Dir(name), hash+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *Store) Get(_ context.Context, hash string) ([]byte, error) {
+	name, err := s.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, core.ErrNotFound
+	}
+	return data, err
+}
+
+func (s *Store) path(hash string) (string, error) {
+	if len(hash) < 3 || filepath.Base(hash) != hash {
+		return "", fmt.Errorf("%w: bad image hash %q", core.ErrBadArguments, hash)
+	}
+	return filepath.Join(s.root, hash[:2], hash), nil
+}
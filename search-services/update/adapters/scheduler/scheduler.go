@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"yadro.com/course/update/core"
+)
+
+// Scheduler triggers comics update every period plus a random jitter.
+type Scheduler struct {
+	log     *slog.Logger
+	service core.Updater
+	period  time.Duration
+	jitter  time.Duration
+
+	// clock and random source, replaced in tests
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+	randN func(time.Duration) time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func NewScheduler(log *slog.Logger, service core.Updater, period, jitter time.Duration) *Scheduler {
+	return &Scheduler{
+		log:     log,
+		service: service,
+		period:  period,
+		jitter:  jitter,
+		now:     time.Now,
+		after:   time.After,
+		randN:   rand.N[time.Duration],
+	}
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	if s.period <= 0 {
+		s.log.Info("scheduled updates are disabled")
+		return
+	}
+	s.log.Info("starting update scheduler", "period", s.period, "jitter", s.jitter)
+
+	go func() {
+		for {
+			tick := s.after(s.schedule())
+			select {
+			case <-ctx.Done():
+				s.log.Info("stopping update scheduler")
+				return
+			case <-tick:
+				s.run(ctx)
+			}
+		}
+	}()
+}
+
+// NextRun returns the time of the next scheduled update,
+// zero when scheduling is off or an update is being run.
+func (s *Scheduler) NextRun() time.Time {
+	if s.service.Status(context.Background()) == core.StatusRunning {
+		return time.Time{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next
+}
+
+func (s *Scheduler) schedule() time.Duration {
+	delay := s.period
+	if s.jitter > 0 {
+		delay += s.randN(s.jitter)
+	}
+
+	s.mu.Lock()
+	s.next = s.now().Add(delay)
+	s.mu.Unlock()
+
+	s.log.Debug("next update scheduled", "in", delay)
+	return delay
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	s.mu.Lock()
+	s.next = time.Time{}
+	s.mu.Unlock()
+
+	if s.service.Status(ctx) == core.StatusRunning {
+		s.log.Info("update in progress, skipping scheduled run")
+		return
+	}
+
+	id, err := s.service.Update(ctx, core.TriggerSchedule, core.UpdateOptions{Mode: core.ModeNew})
+	if err != nil {
+		if errors.Is(err, core.ErrUpdateInProgress) {
+			s.log.Info("update in progress, skipping scheduled run")
+			return
+		}
+		s.log.Error("failed to start scheduled update", "error", err)
+		return
+	}
+	s.log.Info("scheduled update started", "job", id)
+}
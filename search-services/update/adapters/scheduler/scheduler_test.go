@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"yadro.com/course/update/core"
+)
+
+var log = slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+// fakeUpdater counts scheduled updates, other Updater methods are not used.
+type fakeUpdater struct {
+	core.Updater
+
+	mu      sync.Mutex
+	status  core.ServiceStatus
+	err     error
+	updates int
+}
+
+func (f *fakeUpdater) Status(context.Context) core.ServiceStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.status
+}
+
+func (f *fakeUpdater) Update(_ context.Context, trigger core.Trigger, opts core.UpdateOptions) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if trigger != core.TriggerSchedule || opts.Mode != core.ModeNew {
+		panic("unexpected scheduled update")
+	}
+	f.updates++
+	return "job", f.err
+}
+
+func (f *fakeUpdater) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.updates
+}
+
+func TestSchedule_Jitter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewScheduler(log, &fakeUpdater{status: core.StatusIdle}, time.Hour, 10*time.Minute)
+	s.now = func() time.Time { return now }
+
+	tests := []struct {
+		name   string
+		jitter time.Duration
+		want   time.Duration
+	}{
+		{name: "no jitter", jitter: 0, want: time.Hour},
+		{name: "max jitter", jitter: 10*time.Minute - 1, want: time.Hour + 10*time.Minute - 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s.randN = func(n time.Duration) time.Duration {
+				assert.Equal(t, 10*time.Minute, n)
+				return tc.jitter
+			}
+			assert.Equal(t, tc.want, s.schedule())
+			assert.Equal(t, now.Add(tc.want), s.NextRun())
+		})
+	}
+
+	// the real random source stays within the jitter
+	s.randN = NewScheduler(log, nil, 0, 0).randN
+	for range 100 {
+		delay := s.schedule()
+		assert.GreaterOrEqual(t, delay, time.Hour)
+		assert.Less(t, delay, time.Hour+10*time.Minute)
+	}
+
+	// without jitter the random source is not asked
+	s = NewScheduler(log, &fakeUpdater{}, time.Hour, 0)
+	s.randN = func(time.Duration) time.Duration { panic("jitter is off") }
+	assert.Equal(t, time.Hour, s.schedule())
+}
+
+func TestRun_SkipsRunningUpdate(t *testing.T) {
+	updater := &fakeUpdater{status: core.StatusRunning}
+	s := NewScheduler(log, updater, time.Hour, 0)
+	s.schedule()
+	assert.True(t, s.NextRun().IsZero(), "no next run while an update is running")
+
+	s.run(context.Background())
+	assert.Equal(t, 0, updater.count())
+
+	// an update started meanwhile is not an error
+	updater.status = core.StatusIdle
+	updater.err = core.ErrUpdateInProgress
+	s.run(context.Background())
+	assert.Equal(t, 1, updater.count())
+	assert.True(t, s.NextRun().IsZero(), "run clears the next run until rescheduled")
+}
+
+func TestStart(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	updater := &fakeUpdater{status: core.StatusIdle}
+	s := NewScheduler(log, updater, time.Hour, time.Minute)
+	s.now = func() time.Time { return now }
+	s.randN = func(time.Duration) time.Duration { return 30 * time.Second }
+	ticks := make(chan time.Time)
+	delays := make(chan time.Duration, 10)
+	s.after = func(d time.Duration) <-chan time.Time {
+		delays <- d
+		return ticks
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	assert.Equal(t, time.Hour+30*time.Second, <-delays)
+	assert.Equal(t, now.Add(time.Hour+30*time.Second), s.NextRun())
+	ticks <- now
+	<-delays
+	assert.Equal(t, 1, updater.count())
+	ticks <- now
+	<-delays
+	assert.Equal(t, 2, updater.count())
+
+	// disabled scheduler never runs
+	off := NewScheduler(log, updater, 0, 0)
+	off.after = func(time.Duration) <-chan time.Time { panic("scheduling is off") }
+	off.Start(ctx)
+	assert.True(t, off.NextRun().IsZero())
+}
@@ -3,6 +3,7 @@ package words
 import (
 	"context"
 	"log/slog"
+	"unicode/utf8"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -10,35 +11,99 @@ import (
 	wordspb "yadro.com/course/proto/words"
 )
 
+// maxPhraseLen is the longest phrase words service accepts in one message.
+const maxPhraseLen = 4096
+
 type Client struct {
-	log    *slog.Logger
-	client wordspb.WordsClient
+	log      *slog.Logger
+	client   wordspb.WordsClient
+	shingles bool
 }
 
-func NewClient(address string, log *slog.Logger) (*Client, error) {
+func NewClient(address string, shingles bool, log *slog.Logger) (*Client, error) {
 	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, err
 	}
 	return &Client{
-		client: wordspb.NewWordsClient(conn),
-		log:    log,
+		client:   wordspb.NewWordsClient(conn),
+		log:      log,
+		shingles: shingles,
 	}, nil
 }
 
-func (c Client) Norm(ctx context.Context, phrase string) ([]string, error) {
-	req := &wordspb.WordsRequest{
-		Phrase: phrase,
+// NormBatch normalizes phrases keyed by ID in one call. Phrases too long
+// for a single message, like long transcripts, are streamed one by one.
+func (c Client) NormBatch(ctx context.Context, phrases map[int]string) (map[int][]string, error) {
+	result := make(map[int][]string, len(phrases))
+	req := &wordspb.NormBatchRequest{Shingles: c.shingles}
+	for id, phrase := range phrases {
+		if len(phrase) > maxPhraseLen {
+			words, err := c.normStream(ctx, phrase)
+			if err != nil {
+				return nil, err
+			}
+			result[id] = words
+			continue
+		}
+		req.Documents = append(req.Documents, &wordspb.Document{
+			Id:     int64(id),
+			Phrase: phrase,
+		})
+	}
+	if len(req.Documents) == 0 {
+		return result, nil
+	}
+
+	resp, err := c.client.NormBatch(ctx, req)
+	if err != nil {
+		c.log.Error("gRPC NormBatch call failed", "error", err)
+		return nil, err
+	}
+	for _, doc := range resp.GetDocuments() {
+		result[int(doc.GetId())] = doc.GetWords()
 	}
-	resp, err := c.client.Norm(ctx, req)
+
+	return result, nil
+}
+
+func (c Client) normStream(ctx context.Context, phrase string) ([]string, error) {
+	stream, err := c.client.NormStream(ctx)
 	if err != nil {
-		c.log.Error("gRPC Norm call failed", "error", err)
+		c.log.Error("gRPC NormStream call failed", "error", err)
+		return nil, err
+	}
+
+	for _, chunk := range chunks(phrase, maxPhraseLen) {
+		if err := stream.Send(&wordspb.WordsRequest{Phrase: chunk, Shingles: c.shingles}); err != nil {
+			c.log.Error("failed to send phrase chunk", "error", err)
+			return nil, err
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		c.log.Error("gRPC NormStream call failed", "error", err)
 		return nil, err
 	}
 
 	return resp.GetWords(), nil
 }
 
+// chunks splits s into pieces of at most size bytes without breaking runes.
+func chunks(s string, size int) []string {
+	var result []string
+	for len(s) > size {
+		cut := size
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		result = append(result, s[:cut])
+		s = s[cut:]
+	}
+	return append(result, s)
+}
+
 func (c Client) Ping(ctx context.Context) error {
 	_, err := c.client.Ping(ctx, &emptypb.Empty{})
 	return err
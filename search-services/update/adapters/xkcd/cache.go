@@ -0,0 +1,47 @@
+package xkcd
+
+import "sync"
+
+// cacheSize bounds how many responses are kept for revalidation.
+const cacheSize = 128
+
+type cacheEntry struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// cache keeps the latest responses with their validators, evicting the
+// oldest entry once full. The pinned URL, the latest comic checked on
+// every update, is never evicted by comics fetched meanwhile.
+type cache struct {
+	pinned string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	order   []string
+}
+
+func newCache(pinned string) *cache {
+	return &cache{pinned: pinned, entries: make(map[string]cacheEntry)}
+}
+
+func (c *cache) get(url string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[url]
+	return entry, ok
+}
+
+func (c *cache) put(url string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[url]; !ok && url != c.pinned {
+		if len(c.order) == cacheSize {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, url)
+	}
+	c.entries[url] = entry
+}
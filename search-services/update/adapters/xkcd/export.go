@@ -0,0 +1,130 @@
+package xkcd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"yadro.com/course/update/core"
+)
+
+// MirrorWriter dumps comics in the layout read by Mirror, into a directory
+// or, if the path ends with .tar, .tar.gz or .tgz, into a tarball.
+type MirrorWriter struct {
+	root   string
+	file   *os.File
+	gz     *gzip.Writer
+	tar    *tar.Writer
+	latest core.XKCDInfo
+	err    error
+}
+
+func NewMirrorWriter(root string) (*MirrorWriter, error) {
+	w := &MirrorWriter{root: root}
+	if !strings.HasSuffix(root, ".tar") && !strings.HasSuffix(root, ".tar.gz") && !strings.HasSuffix(root, ".tgz") {
+		if err := os.MkdirAll(root, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mirror directory: %w", err)
+		}
+		return w, nil
+	}
+
+	f, err := os.Create(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mirror archive: %w", err)
+	}
+	w.file = f
+	var out io.Writer = f
+	if !strings.HasSuffix(root, ".tar") {
+		w.gz = gzip.NewWriter(f)
+		out = w.gz
+	}
+	w.tar = tar.NewWriter(out)
+	return w, nil
+}
+
+func (w *MirrorWriter) Put(info core.XKCDInfo) error {
+	if info.ID > w.latest.ID {
+		w.latest = info
+	}
+	return w.write(filepath.Join(strconv.Itoa(info.ID), infoFile), info)
+}
+
+// Close writes the latest comic as the top-level info.0.json and
+// flushes the archive, if any. An archive that failed to be written
+// is removed rather than left truncated.
+func (w *MirrorWriter) Close() error {
+	if w.latest.ID > 0 {
+		_ = w.write(infoFile, w.latest)
+	}
+	if w.tar == nil {
+		return w.err
+	}
+	err := w.err
+	if err == nil {
+		err = w.tar.Close()
+	}
+	if err == nil && w.gz != nil {
+		err = w.gz.Close()
+	}
+	err = errors.Join(err, w.file.Close())
+	if err != nil {
+		err = errors.Join(err, os.Remove(w.root))
+	}
+	return err
+}
+
+// Abort closes the writer and removes a partial archive, for an export
+// that failed before all comics were put.
+func (w *MirrorWriter) Abort() {
+	if w.err == nil {
+		w.err = errors.New("export aborted")
+	}
+	_ = w.Close()
+}
+
+// write puts a comic under name, remembering the first failure so that
+// Close does not finish a broken archive.
+func (w *MirrorWriter) write(name string, info core.XKCDInfo) error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.put(name, info); err != nil {
+		w.err = fmt.Errorf("failed to write comic %d: %w", info.ID, err)
+	}
+	return w.err
+}
+
+func (w *MirrorWriter) put(name string, info core.XKCDInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to encode comic: %w", err)
+	}
+
+	if w.tar == nil {
+		name = filepath.Join(w.root, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(name, data, 0o644)
+	}
+
+	hdr := &tar.Header{
+		Name:    filepath.ToSlash(name),
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := w.tar.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = w.tar.Write(data)
+	return err
+}
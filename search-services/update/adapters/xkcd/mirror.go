@@ -0,0 +1,152 @@
+package xkcd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"yadro.com/course/update/core"
+)
+
+const infoFile = "info.0.json"
+
+// Mirror serves comics from a local copy of XKCD laid out like the site:
+// <id>/info.0.json per comic and info.0.json for the latest one. The copy
+// is either a directory or a tarball (.tar, .tar.gz, .tgz).
+type Mirror struct {
+	log    *slog.Logger
+	comics map[int][]byte
+	latest []byte
+}
+
+func NewMirror(root string, log *slog.Logger) (*Mirror, error) {
+	if root == "" {
+		return nil, fmt.Errorf("empty mirror path specified")
+	}
+	m := &Mirror{log: log, comics: make(map[int][]byte)}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mirror: %w", err)
+	}
+	if info.IsDir() {
+		err = m.loadDir(root)
+	} else {
+		err = m.loadTar(root)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mirror %q: %w", root, err)
+	}
+
+	log.Info("loaded xkcd mirror", "path", root, "comics", len(m.comics))
+	return m, nil
+}
+
+func (m *Mirror) loadDir(root string) error {
+	dir := os.DirFS(root)
+	return fs.WalkDir(dir, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Base(name) != infoFile {
+			return err
+		}
+		data, err := fs.ReadFile(dir, name)
+		if err != nil {
+			return err
+		}
+		m.add(name, data)
+		return nil
+	})
+}
+
+func (m *Mirror) loadTar(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") || strings.HasSuffix(file, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || path.Base(hdr.Name) != infoFile {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		m.add(hdr.Name, data)
+	}
+}
+
+// add files a comic by its parent directory name; info.0.json outside
+// of a numbered directory is the latest comic.
+func (m *Mirror) add(name string, data []byte) {
+	dir := path.Base(path.Dir(path.Clean(name)))
+	if id, err := strconv.Atoi(dir); err == nil {
+		m.comics[id] = data
+		return
+	}
+	m.latest = data
+}
+
+func (m *Mirror) Get(_ context.Context, id int) (core.XKCDInfo, error) {
+	var info core.XKCDInfo
+	data, ok := m.comics[id]
+	if !ok {
+		return info, fmt.Errorf("%w: comic %d is not in the mirror", core.ErrNotFound, id)
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		m.log.Error("failed to decode mirrored comic", "id", id, "error", err)
+		return info, fmt.Errorf("failed to decode comic %d: %w", id, err)
+	}
+	return info, nil
+}
+
+func (m *Mirror) LastID(_ context.Context) (int, error) {
+	if m.latest == nil {
+		maxID := 0
+		for id := range m.comics {
+			maxID = max(maxID, id)
+		}
+		return maxID, nil
+	}
+
+	var result struct {
+		ID int `json:"num"`
+	}
+	if err := json.Unmarshal(m.latest, &result); err != nil {
+		return 0, fmt.Errorf("failed to decode 'latest' comic: %w", err)
+	}
+	return result.ID, nil
+}
+
+// Image is not served by the mirror, comics keep their remote image URLs.
+func (m *Mirror) Image(_ context.Context, url string) ([]byte, error) {
+	return nil, fmt.Errorf("%w: images are not mirrored: %s", core.ErrNotFound, url)
+}
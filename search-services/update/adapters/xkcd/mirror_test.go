@@ -0,0 +1,88 @@
+package xkcd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"yadro.com/course/update/core"
+)
+
+func TestMirror_RoundTrip(t *testing.T) {
+	comics := []core.XKCDInfo{
+		{ID: 1, URL: "https://imgs.xkcd.com/comics/1.png", Title: "one", Alt: "alt", Transcript: "tr", SafeTitle: "one"},
+		{ID: 2, Title: "two"},
+		{ID: 5, Title: "five"},
+	}
+	for _, name := range []string{"mirror", "mirror.tar", "mirror.tar.gz", "mirror.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), name)
+			w, err := NewMirrorWriter(root)
+			require.NoError(t, err)
+			for _, comic := range comics {
+				require.NoError(t, w.Put(comic))
+			}
+			require.NoError(t, w.Close())
+
+			m, err := NewMirror(root, log)
+			require.NoError(t, err)
+			ctx := context.Background()
+			for _, comic := range comics {
+				got, err := m.Get(ctx, comic.ID)
+				require.NoError(t, err)
+				assert.Equal(t, comic, got)
+			}
+			last, err := m.LastID(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 5, last)
+
+			// updates skip comics missing from the mirror as not found
+			_, err = m.Get(ctx, 3)
+			assert.ErrorIs(t, err, core.ErrNotFound)
+			_, err = m.Image(ctx, comics[0].URL)
+			assert.ErrorIs(t, err, core.ErrNotFound)
+		})
+	}
+}
+
+func TestMirror_NoLatest(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "7"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "7", infoFile), []byte(`{"num": 7}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README"), []byte("not a comic"), 0o644))
+
+	m, err := NewMirror(root, log)
+	require.NoError(t, err)
+	last, err := m.LastID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 7, last, "the highest comic is the latest")
+
+	_, err = NewMirror(filepath.Join(root, "nope"), log)
+	assert.Error(t, err)
+}
+
+func TestMirrorWriter_Failed(t *testing.T) {
+	for _, name := range []string{"mirror.tar", "mirror.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), name)
+			w, err := NewMirrorWriter(root)
+			require.NoError(t, err)
+			require.NoError(t, w.file.Close())
+
+			// gzip buffers, so the failure may only show on Close
+			_ = w.Put(core.XKCDInfo{ID: 1, Title: "one"})
+			assert.Error(t, w.Close())
+			assert.NoFileExists(t, root, "a broken archive is removed")
+		})
+	}
+
+	root := filepath.Join(t.TempDir(), "mirror.tar")
+	w, err := NewMirrorWriter(root)
+	require.NoError(t, err)
+	require.NoError(t, w.Put(core.XKCDInfo{ID: 1, Title: "one"}))
+	w.Abort()
+	assert.NoFileExists(t, root, "an aborted archive is removed")
+}
@@ -3,79 +3,176 @@ package xkcd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"time"
 
+	"golang.org/x/time/rate"
 	"yadro.com/course/update/core"
 )
 
+// Policy controls how the client retries and throttles requests to XKCD.
+type Policy struct {
+	Retries          int
+	MinBackoff       time.Duration
+	MaxBackoff       time.Duration
+	RPS              float64
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// maxBodySize bounds a response read into memory, images included.
+const maxBodySize = 16 << 20
+
 type Client struct {
-	log    *slog.Logger
-	client http.Client
-	url    string
+	log     *slog.Logger
+	client  http.Client
+	url     string
+	policy  Policy
+	limiter *rate.Limiter
+	breaker *breaker
+	cache   *cache
 }
 
-func NewClient(url string, timeout time.Duration, log *slog.Logger) (*Client, error) {
+func NewClient(url string, timeout time.Duration, policy Policy, log *slog.Logger) (*Client, error) {
 	if url == "" {
 		return nil, fmt.Errorf("empty base url specified")
 	}
+	if policy.RPS <= 0 {
+		return nil, fmt.Errorf("wrong rps specified: %v", policy.RPS)
+	}
+	if policy.MinBackoff <= 0 || policy.MaxBackoff < policy.MinBackoff {
+		return nil, fmt.Errorf("wrong backoff specified: %v..%v", policy.MinBackoff, policy.MaxBackoff)
+	}
 	return &Client{
-		client: http.Client{Timeout: timeout},
-		log:    log,
-		url:    url,
+		client:  http.Client{Timeout: timeout},
+		log:     log,
+		url:     url,
+		policy:  policy,
+		limiter: rate.NewLimiter(rate.Limit(policy.RPS), 1),
+		breaker: newBreaker(policy.BreakerThreshold, policy.BreakerCooldown),
+		cache:   newCache(url + "/info.0.json"),
 	}, nil
 }
 
+// transientError is a failure worth retrying: a network error, 429 or 5xx.
+type transientError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
 func (c Client) Get(ctx context.Context, id int) (core.XKCDInfo, error) {
 	url := fmt.Sprintf("%s/%d/info.0.json", c.url, id)
 	var info core.XKCDInfo
 
-	// 2. Создаем запрос с Context
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	body, err := c.get(ctx, url, true)
 	if err != nil {
-		c.log.Error("failed to create request", "id", id, "error", err)
-		return info, fmt.Errorf("failed to create request: %w", err)
+		c.log.Warn("failed to get comic", "id", id, "error", err)
+		return core.XKCDInfo{}, err
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return core.XKCDInfo{}, fmt.Errorf("failed to decode response: %w", err)
 	}
+	return info, nil
+}
 
-	resp, err := c.client.Do(req)
+func (c Client) LastID(ctx context.Context) (int, error) {
+	url := fmt.Sprintf("%s/info.0.json", c.url)
+
+	var result struct {
+		ID int `json:"num"`
+	}
+	body, err := c.get(ctx, url, true)
 	if err != nil {
-		c.log.Error("failed to execute request", "id", id, "error", err)
-		return info, fmt.Errorf("request failed: %w", err)
+		c.log.Warn("failed to get 'latest' comic", "error", err)
+		return 0, err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			c.log.Error("failed to close response body", "error", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		c.log.Warn("request failed", "id", id, "status", resp.Status)
-		return info, fmt.Errorf("request failed with status: %s", resp.Status)
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("failed to decode 'latest' response: %w", err)
 	}
+	return result.ID, nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
-		c.log.Error("failed to decode json response", "id", id, "error", err)
-		return info, fmt.Errorf("failed to decode response: %w", err)
+// Image downloads a comic image. Images are not kept in the cache.
+func (c Client) Image(ctx context.Context, url string) ([]byte, error) {
+	data, err := c.get(ctx, url, false)
+	if err != nil {
+		c.log.Warn("failed to get image", "url", url, "error", err)
+		return nil, err
 	}
-
-	return info, nil
+	return data, nil
 }
 
-func (c Client) LastID(ctx context.Context) (int, error) {
-	url := fmt.Sprintf("%s/info.0.json", c.url)
+// get fetches url, retrying transient failures with exponential backoff.
+// Permanent failures, an open breaker and a Retry-After beyond MaxBackoff
+// are returned at once. Responses
+// are revalidated against the cache if cached is set.
+func (c Client) get(ctx context.Context, url string, cached bool) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if !c.breaker.allow() {
+			return nil, fmt.Errorf("%w: xkcd circuit breaker is open", core.ErrUnavailable)
+		}
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
 
+		body, err := c.do(ctx, url, cached)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var transient *transientError
+		if !errors.As(err, &transient) {
+			c.breaker.success()
+			return body, err
+		}
+		c.breaker.failure()
+
+		// a server asking to wait longer than the policy allows is not waited for
+		if attempt >= c.policy.Retries || transient.retryAfter > c.policy.MaxBackoff {
+			return nil, err
+		}
+		delay := max(c.backoff(attempt), transient.retryAfter)
+		c.log.Debug("request failed, retrying", "url", url, "attempt", attempt+1, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// do makes a single request, revalidating a cached response with
+// If-None-Match and If-Modified-Since.
+func (c Client) do(ctx context.Context, url string, cache bool) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		c.log.Error("failed to create 'latest' request", "error", err)
-		return 0, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	var cached cacheEntry
+	var ok bool
+	if cache {
+		cached, ok = c.cache.get(url)
+	}
+	if ok {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		c.log.Error("failed to execute 'latest' request", "error", err)
-		return 0, fmt.Errorf("request 'latest' failed: %w", err)
+		return nil, &transientError{err: fmt.Errorf("request failed: %w", err)}
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -83,19 +180,57 @@ func (c Client) LastID(ctx context.Context) (int, error) {
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		c.log.Warn("'latest' request failed", "status", resp.Status)
-		return 0, fmt.Errorf("request 'latest' failed with status: %s", resp.Status)
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		return cached.body, nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+		if err != nil {
+			return nil, &transientError{err: fmt.Errorf("failed to read response: %w", err)}
+		}
+		if len(body) > maxBodySize {
+			return nil, fmt.Errorf("response exceeds %d bytes: %s", maxBodySize, url)
+		}
+		entry := cacheEntry{
+			etag:         resp.Header.Get("ETag"),
+			lastModified: resp.Header.Get("Last-Modified"),
+			body:         body,
+		}
+		if cache && (entry.etag != "" || entry.lastModified != "") {
+			c.cache.put(url, entry)
+		}
+		return body, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", core.ErrNotFound, url)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, &transientError{
+			err:        fmt.Errorf("request failed with status: %s", resp.Status),
+			retryAfter: retryAfter(resp.Header.Get("Retry-After")),
+		}
+	default:
+		return nil, fmt.Errorf("request failed with status: %s", resp.Status)
 	}
+}
 
-	var result struct {
-		ID int `json:"num"`
+// backoff grows exponentially with the attempt, with the upper half jittered.
+func (c Client) backoff(attempt int) time.Duration {
+	d := c.policy.MaxBackoff
+	if attempt < 32 {
+		d = min(c.policy.MinBackoff<<attempt, c.policy.MaxBackoff)
 	}
+	return d/2 + rand.N(d/2+1)
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		c.log.Error("failed to decode 'latest' json response", "error", err)
-		return 0, fmt.Errorf("failed to decode 'latest' response: %w", err)
+// retryAfter parses Retry-After given either in seconds or as an HTTP date.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
 	}
-
-	return result.ID, nil
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
 }
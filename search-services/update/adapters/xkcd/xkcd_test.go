@@ -0,0 +1,259 @@
+package xkcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"yadro.com/course/update/core"
+)
+
+var log = slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+// replies serves the statuses in turn for each path, the last one repeats.
+type replies struct {
+	mu       sync.Mutex
+	statuses map[string][]int
+	headers  map[string]http.Header
+	hits     map[string]int
+}
+
+func newReplies() *replies {
+	return &replies{statuses: map[string][]int{}, headers: map[string]http.Header{}, hits: map[string]int{}}
+}
+
+func (r *replies) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statuses := r.statuses[req.URL.Path]
+	if len(statuses) == 0 {
+		http.NotFound(w, req)
+		return
+	}
+	status := statuses[min(r.hits[req.URL.Path], len(statuses)-1)]
+	r.hits[req.URL.Path]++
+	for name, values := range r.headers[req.URL.Path] {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(status)
+	if status == http.StatusOK {
+		_, _ = fmt.Fprintf(w, `{"num": 1, "title": "t"}`)
+	}
+}
+
+func (r *replies) set(path string, statuses ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses[path] = statuses
+	r.hits[path] = 0
+}
+
+func (r *replies) count(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hits[path]
+}
+
+func newTestClient(t *testing.T, h http.Handler, policy Policy) *Client {
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+	policy.RPS = 1000
+	if policy.MinBackoff == 0 {
+		policy.MinBackoff, policy.MaxBackoff = time.Millisecond, 5*time.Millisecond
+	}
+	client, err := NewClient(server.URL, time.Second, policy, log)
+	require.NoError(t, err)
+	return client
+}
+
+func TestGet_Retries(t *testing.T) {
+	server := newReplies()
+	server.headers["/1/info.0.json"] = http.Header{"Retry-After": {"1"}}
+	server.headers["/6/info.0.json"] = http.Header{"Retry-After": {"86400"}}
+	client := newTestClient(t, server, Policy{Retries: 3, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Second})
+
+	tests := []struct {
+		name     string
+		statuses []int
+		hits     int
+		err      error
+		minTime  time.Duration
+	}{
+		{name: "429 waits for retry after", statuses: []int{429, 200}, hits: 2, minTime: time.Second},
+		{name: "5xx recovers", statuses: []int{500, 503, 502, 200}, hits: 4},
+		{name: "5xx gives up", statuses: []int{500}, hits: 4, err: errors.New("500")},
+		{name: "404 is not retried", statuses: []int{404}, hits: 1, err: core.ErrNotFound},
+		{name: "4xx is not retried", statuses: []int{403}, hits: 1, err: errors.New("403")},
+		{name: "retry after beyond max backoff is not waited", statuses: []int{429, 200}, hits: 1, err: errors.New("429")},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fmt.Sprintf("/%d/info.0.json", i+1)
+			server.set(path, tc.statuses...)
+
+			start := time.Now()
+			info, err := client.Get(context.Background(), i+1)
+			assert.Equal(t, tc.hits, server.count(path))
+			assert.GreaterOrEqual(t, time.Since(start), tc.minTime)
+			switch {
+			case tc.err == nil:
+				require.NoError(t, err)
+				assert.Equal(t, "t", info.Title)
+			case errors.Is(tc.err, core.ErrNotFound):
+				assert.ErrorIs(t, err, core.ErrNotFound)
+			default:
+				assert.ErrorContains(t, err, tc.err.Error())
+			}
+		})
+	}
+}
+
+func TestImage_TooLarge(t *testing.T) {
+	server := http.NewServeMux()
+	server.HandleFunc("/small.png", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(make([]byte, maxBodySize))
+	})
+	server.HandleFunc("/large.png", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(make([]byte, maxBodySize+1))
+	})
+	client := newTestClient(t, server, Policy{})
+
+	image, err := client.Image(context.Background(), client.url+"/small.png")
+	require.NoError(t, err)
+	assert.Len(t, image, maxBodySize)
+
+	_, err = client.Image(context.Background(), client.url+"/large.png")
+	assert.ErrorContains(t, err, "exceeds")
+}
+
+func TestGet_Breaker(t *testing.T) {
+	const path = "/1/info.0.json"
+	server := newReplies()
+	client := newTestClient(t, server, Policy{BreakerThreshold: 2, BreakerCooldown: 100 * time.Millisecond})
+	get := func() error {
+		_, err := client.Get(context.Background(), 1)
+		return err
+	}
+
+	// permanent failures do not count
+	server.set(path, 404)
+	for range 3 {
+		assert.ErrorIs(t, get(), core.ErrNotFound)
+	}
+
+	server.set(path, 500)
+	assert.Error(t, get())
+	assert.Error(t, get())
+	assert.Equal(t, 2, server.count(path))
+
+	// open: rejected without a request
+	assert.ErrorIs(t, get(), core.ErrUnavailable)
+	assert.Equal(t, 2, server.count(path))
+
+	// half open after the cooldown: one failure opens it again
+	time.Sleep(150 * time.Millisecond)
+	assert.Error(t, get())
+	assert.Equal(t, 3, server.count(path))
+	assert.ErrorIs(t, get(), core.ErrUnavailable)
+	assert.Equal(t, 3, server.count(path))
+
+	// a success closes it
+	time.Sleep(150 * time.Millisecond)
+	server.set(path, 200, 500)
+	assert.NoError(t, get())
+	assert.Error(t, get())
+	assert.Error(t, get(), "one failure does not open a closed breaker")
+	assert.Equal(t, 3, server.count(path))
+}
+
+func TestBackoff(t *testing.T) {
+	client := Client{policy: Policy{MinBackoff: 10 * time.Millisecond, MaxBackoff: 80 * time.Millisecond}}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 0, min: 5 * time.Millisecond, max: 10 * time.Millisecond},
+		{attempt: 1, min: 10 * time.Millisecond, max: 20 * time.Millisecond},
+		{attempt: 3, min: 40 * time.Millisecond, max: 80 * time.Millisecond},
+		{attempt: 10, min: 40 * time.Millisecond, max: 80 * time.Millisecond},
+		{attempt: 100, min: 40 * time.Millisecond, max: 80 * time.Millisecond},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprint(tc.attempt), func(t *testing.T) {
+			for range 100 {
+				d := client.backoff(tc.attempt)
+				assert.GreaterOrEqual(t, d, tc.min)
+				assert.LessOrEqual(t, d, tc.max)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max time.Duration
+	}{
+		{value: "", min: 0, max: 0},
+		{value: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{value: "-1", min: 0, max: 0},
+		{value: "soon", min: 0, max: 0},
+		{value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), min: 58 * time.Second, max: time.Minute},
+		{value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), min: 0, max: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			d := retryAfter(tc.value)
+			assert.GreaterOrEqual(t, d, tc.min)
+			assert.LessOrEqual(t, d, tc.max)
+		})
+	}
+}
+
+func TestLastID_Revalidated(t *testing.T) {
+	var mu sync.Mutex
+	var revalidated int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /info.0.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"latest"`)
+		if r.Header.Get("If-None-Match") == `"latest"` {
+			mu.Lock()
+			revalidated++
+			mu.Unlock()
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"num": 3000}`)
+	})
+	mux.HandleFunc("GET /{id}/info.0.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.PathValue("id")+`"`)
+		_, _ = fmt.Fprintf(w, `{"num": %s}`, r.PathValue("id"))
+	})
+	client := newTestClient(t, mux, Policy{})
+
+	id, err := client.LastID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3000, id)
+
+	// a full update fetches more comics than the cache keeps
+	for id := 1; id <= 2*cacheSize; id++ {
+		_, err := client.Get(context.Background(), id)
+		require.NoError(t, err)
+	}
+
+	id, err = client.LastID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3000, id, "answered from the cache")
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, revalidated, "need a 304 revalidation")
+}
@@ -12,6 +12,14 @@ type XKCD struct {
 	Concurrency int           `yaml:"concurrency" env:"XKCD_CONCURRENCY" env-default:"1"`
 	Timeout     time.Duration `yaml:"timeout" env:"XKCD_TIMEOUT" env-default:"10s"`
 	CheckPeriod time.Duration `yaml:"check_period" env:"XKCD_CHECK_PERIOD" env-default:"1h"`
+	CheckJitter time.Duration `yaml:"check_jitter" env:"XKCD_CHECK_JITTER" env-default:"5m"`
+
+	Retries          int           `yaml:"retries" env:"XKCD_RETRIES" env-default:"5"`
+	MinBackoff       time.Duration `yaml:"min_backoff" env:"XKCD_MIN_BACKOFF" env-default:"500ms"`
+	MaxBackoff       time.Duration `yaml:"max_backoff" env:"XKCD_MAX_BACKOFF" env-default:"30s"`
+	RPS              float64       `yaml:"rps" env:"XKCD_RPS" env-default:"5"`
+	BreakerThreshold int           `yaml:"breaker_threshold" env:"XKCD_BREAKER_THRESHOLD" env-default:"5"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" env:"XKCD_BREAKER_COOLDOWN" env-default:"1m"`
 }
 
 type Config struct {
@@ -20,7 +28,10 @@ type Config struct {
 	XKCD          XKCD   `yaml:"xkcd"`
 	DBAddress     string `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
 	WordsAddress  string `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
+	WordsShingles bool   `yaml:"words_shingles" env:"WORDS_SHINGLES" env-default:"false"`
 	BrokerAddress string `yaml:"broker_address" env:"BROKER_ADDRESS" env-default:"nats://nats:4222"`
+	// ImagesDir enables downloading comic images into this directory.
+	ImagesDir string `yaml:"images_dir" env:"IMAGES_DIR"`
 }
 
 func MustLoad(configPath string) Config {
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxKeywords limits extra keywords of a single comic.
+const maxKeywords = 100
+
+func (s *Service) Annotation(ctx context.Context, id int) (Annotation, error) {
+	if id < 1 {
+		return Annotation{}, fmt.Errorf("%w: bad comic ID %d", ErrBadArguments, id)
+	}
+	return s.db.Annotation(ctx, id)
+}
+
+// Annotate stores a custom transcript and extra keywords of a comic.
+// A stored comic is indexed again at once, others once fetched.
+func (s *Service) Annotate(ctx context.Context, note Annotation) error {
+	if note.ID < 1 {
+		return fmt.Errorf("%w: bad comic ID %d", ErrBadArguments, note.ID)
+	}
+	note.Transcript = strings.TrimSpace(note.Transcript)
+	keywords := make([]string, 0, len(note.Keywords))
+	for _, kw := range note.Keywords {
+		if kw = strings.TrimSpace(kw); kw != "" {
+			keywords = append(keywords, kw)
+		}
+	}
+	note.Keywords = keywords
+	if note.Transcript == "" && len(note.Keywords) == 0 {
+		return fmt.Errorf("%w: empty annotation", ErrBadArguments)
+	}
+	if len(note.Keywords) > maxKeywords {
+		return fmt.Errorf("%w: more than %d keywords", ErrBadArguments, maxKeywords)
+	}
+
+	s.log.Info("annotating comic", "id", note.ID, "keywords", len(note.Keywords))
+	if err := s.db.SetAnnotation(ctx, note); err != nil {
+		return fmt.Errorf("failed to save annotation: %w", err)
+	}
+	return s.reindexComic(ctx, note.ID)
+}
+
+// DeleteAnnotation removes curated text of a comic and indexes it again.
+func (s *Service) DeleteAnnotation(ctx context.Context, id int) error {
+	if id < 1 {
+		return fmt.Errorf("%w: bad comic ID %d", ErrBadArguments, id)
+	}
+	s.log.Info("deleting comic annotation", "id", id)
+	if err := s.db.DeleteAnnotation(ctx, id); err != nil {
+		return err
+	}
+	return s.reindexComic(ctx, id)
+}
+
+// reindexComic normalizes a stored comic again. A comic not fetched yet
+// is left alone, its annotation is merged when it is fetched.
+func (s *Service) reindexComic(ctx context.Context, id int) error {
+	comic, err := s.db.Comic(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get comic: %w", err)
+	}
+
+	keywords, err := s.normalize(ctx, []XKCDInfo{{
+		ID:         comic.ID,
+		Title:      comic.Title,
+		Alt:        comic.Alt,
+		Transcript: comic.Transcript,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to normalize comic: %w", err)
+	}
+	words, ok := keywords[id]
+	if !ok {
+		words = []string{}
+	}
+	if err := s.db.SetWords(ctx, id, words); err != nil {
+		return fmt.Errorf("failed to save words: %w", err)
+	}
+
+	s.publishComic(id)
+	return nil
+}
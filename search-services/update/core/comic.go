@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DeleteComic removes a comic from search. The row is kept, so updates
+// do not fetch the comic again until it is refetched explicitly.
+func (s *Service) DeleteComic(ctx context.Context, id int) error {
+	s.log.Info("deleting comic", "id", id)
+	if err := s.db.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.publishComic(id)
+	return nil
+}
+
+// HideComic hides a comic from search or shows it again.
+func (s *Service) HideComic(ctx context.Context, id int, hidden bool) error {
+	s.log.Info("changing comic visibility", "id", id, "hidden", hidden)
+	if err := s.db.SetHidden(ctx, id, hidden); err != nil {
+		return err
+	}
+	s.publishComic(id)
+	return nil
+}
+
+// RefetchComic fetches and normalizes a single comic again, restoring
+// it if deleted.
+func (s *Service) RefetchComic(ctx context.Context, id int) error {
+	if id < 1 {
+		return fmt.Errorf("%w: bad comic ID %d", ErrBadArguments, id)
+	}
+	s.log.Info("refetching comic", "id", id)
+
+	comicData, err := s.xkcd.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to fetch comic: %w", err)
+	}
+
+	keywords, err := s.normalize(ctx, []XKCDInfo{comicData})
+	if err != nil {
+		return fmt.Errorf("failed to normalize comic: %w", err)
+	}
+	words, ok := keywords[comicData.ID]
+	if !ok {
+		words = []string{}
+	}
+
+	if err := s.db.Add(ctx, s.comic(ctx, comicData, words)); err != nil {
+		return fmt.Errorf("failed to save comic: %w", err)
+	}
+
+	s.publishComic(id)
+	return nil
+}
+
+func (s *Service) publishComic(id int) {
+	if err := s.eb.PublishComic(id); err != nil {
+		s.log.Error("failed to publish comic event", "id", id, "error", err)
+	}
+}
+
+// comic builds a comic to save from fetched data, counting tokens of its
+// text. If an image store is set, the image is downloaded and stored too;
+// a failed download leaves the comic without an image.
+func (s *Service) comic(ctx context.Context, info XKCDInfo, words []string) Comics {
+	c := Comics{
+		ID:          info.ID,
+		URL:         info.URL,
+		Words:       words,
+		Title:       info.Title,
+		Alt:         info.Alt,
+		Transcript:  info.Transcript,
+		SafeTitle:   info.SafeTitle,
+		ContentHash: ContentHash(info),
+		Coverage: TextCoverage{
+			TitleTokens:      len(strings.Fields(info.Title)),
+			AltTokens:        len(strings.Fields(info.Alt)),
+			TranscriptTokens: len(strings.Fields(info.Transcript)),
+		},
+	}
+	if s.images == nil || info.URL == "" {
+		return c
+	}
+
+	img, err := s.storeImage(ctx, info.URL)
+	if err != nil {
+		s.log.Warn("failed to store comic image", "id", info.ID, "url", info.URL, "error", err)
+		return c
+	}
+	c.Image = img
+	return c
+}
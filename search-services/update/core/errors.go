@@ -6,3 +6,4 @@ var ErrBadArguments = errors.New("arguments are not acceptable")
 var ErrAlreadyExists = errors.New("resource or task already exists")
 var ErrNotFound = errors.New("resource is not found")
 var ErrUpdateInProgress = errors.New("update in progress")
+var ErrUnavailable = errors.New("service is unavailable")
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"yadro.com/course/imagehash"
+)
+
+func (s *Service) storeImage(ctx context.Context, url string) (ComicImage, error) {
+	data, err := s.xkcd.Image(ctx, url)
+	if err != nil {
+		return ComicImage{}, fmt.Errorf("failed to download image: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	img := ComicImage{Hash: hex.EncodeToString(sum[:])}
+	if decoded, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+		img.Width, img.Height = decoded.Bounds().Dx(), decoded.Bounds().Dy()
+		img.PHash = imagehash.DHash(decoded)
+		img.HasPHash = true
+	} else {
+		s.log.Warn("failed to decode image", "url", url, "error", err)
+	}
+
+	if err := s.images.Put(ctx, img.Hash, data); err != nil {
+		return ComicImage{}, fmt.Errorf("failed to store image: %w", err)
+	}
+	return img, nil
+}
+
+// Image returns the stored image of a visible comic.
+func (s *Service) Image(ctx context.Context, id int) (ComicImage, []byte, error) {
+	if s.images == nil {
+		return ComicImage{}, nil, fmt.Errorf("%w: images are not stored", ErrNotFound)
+	}
+
+	img, err := s.db.Image(ctx, id)
+	if err != nil {
+		return ComicImage{}, nil, err
+	}
+	data, err := s.images.Get(ctx, img.Hash)
+	if err != nil {
+		s.log.Error("failed to read stored image", "id", id, "hash", img.Hash, "error", err)
+		return ComicImage{}, nil, err
+	}
+	return img, data, nil
+}
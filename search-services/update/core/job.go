@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxJobs is how many update jobs are kept for progress reports.
+const maxJobs = 16
+
+type job struct {
+	id        string
+	cancel    context.CancelFunc
+	startedAt time.Time
+	trigger   Trigger
+	mode      UpdateMode
+	refresh   RefreshFilter
+
+	total    atomic.Int64
+	fetched  atomic.Int64
+	failed   atomic.Int64
+	inFlight atomic.Int64
+
+	mu         sync.Mutex
+	state      JobState
+	finishedAt time.Time
+	err        error
+}
+
+func newJob(cancel context.CancelFunc, trigger Trigger, opts UpdateOptions) *job {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return &job{
+		id:        hex.EncodeToString(b),
+		cancel:    cancel,
+		startedAt: time.Now(),
+		trigger:   trigger,
+		mode:      opts.Mode,
+		refresh:   opts.Refresh,
+		state:     JobRunning,
+	}
+}
+
+func (j *job) finish(state JobState, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.state = state
+	j.err = err
+	j.finishedAt = time.Now()
+}
+
+func (j *job) progress() JobProgress {
+	j.mu.Lock()
+	p := JobProgress{
+		ID:         j.id,
+		State:      j.state,
+		StartedAt:  j.startedAt,
+		FinishedAt: j.finishedAt,
+	}
+	if j.err != nil {
+		p.Error = j.err.Error()
+	}
+	j.mu.Unlock()
+
+	p.Total = int(j.total.Load())
+	p.Fetched = int(j.fetched.Load())
+	p.Failed = int(j.failed.Load())
+	p.InFlight = int(j.inFlight.Load())
+
+	done := p.Fetched + p.Failed
+	if p.State == JobRunning && done > 0 {
+		elapsed := time.Since(p.StartedAt)
+		p.ETA = elapsed * time.Duration(p.Total-done) / time.Duration(done)
+	}
+	return p
+}
+
+func (j *job) run() UpdateRun {
+	p := j.progress()
+	return UpdateRun{
+		ID:         p.ID,
+		Trigger:    j.trigger,
+		Mode:       j.mode,
+		State:      p.State,
+		StartedAt:  p.StartedAt,
+		FinishedAt: p.FinishedAt,
+		Total:      p.Total,
+		Fetched:    p.Fetched,
+		Failed:     p.Failed,
+		Error:      p.Error,
+	}
+}
@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 type ServiceStatus string
 
 const (
@@ -11,21 +13,64 @@ type DBStats struct {
 	WordsTotal    int `db:"words_total"`
 	WordsUnique   int `db:"words_unique"`
 	ComicsFetched int `db:"comics_fetched"`
+	// comics with an empty field
+	NoTitle      int `db:"no_title"`
+	NoAlt        int `db:"no_alt"`
+	NoTranscript int `db:"no_transcript"`
 }
 
 type ServiceStats struct {
 	DBStats
-	ComicsTotal int
+	ComicsTotal     int
+	LatestCheckedAt time.Time // when ComicsTotal was checked on XKCD
 }
 
 type Comics struct {
+	ID          int
+	URL         string
+	Words       []string
+	Title       string
+	Alt         string
+	Transcript  string
+	SafeTitle   string
+	ContentHash string
+	Image       ComicImage
+	Coverage    TextCoverage
+}
+
+// TextCoverage counts tokens of each text field of a comic, 0 for an
+// empty field.
+type TextCoverage struct {
+	TitleTokens      int
+	AltTokens        int
+	TranscriptTokens int
+}
+
+// ComicCoverage is the text coverage of a stored comic.
+type ComicCoverage struct {
+	ID    int
+	Title string
+	TextCoverage
+}
+
+// Annotation is curated text of a comic kept apart from fetched data,
+// so it survives refetches and drops. It is indexed with the comic.
+type Annotation struct {
 	ID         int
-	URL        string
-	Words      []string
-	Title      string
-	Alt        string
 	Transcript string
-	SafeTitle  string
+	Keywords   []string
+	UpdatedAt  time.Time
+}
+
+// ComicImage describes a comic image kept in the image store under
+// its sha256 hash.
+type ComicImage struct {
+	Hash   string
+	Width  int
+	Height int
+	// PHash is the perceptual hash of the image, if it could be decoded.
+	PHash    uint64
+	HasPHash bool
 }
 
 type XKCDInfo struct {
@@ -36,3 +81,103 @@ type XKCDInfo struct {
 	Transcript string `json:"transcript"`
 	SafeTitle  string `json:"safe_title"`
 }
+
+type Trigger string
+
+const (
+	TriggerManual   Trigger = "manual"
+	TriggerSchedule Trigger = "schedule"
+)
+
+type UpdateMode string
+
+const (
+	ModeNew     UpdateMode = "new"     // comics missing in the database
+	ModeFailed  UpdateMode = "failed"  // comics in the failure ledger only
+	ModeRefresh UpdateMode = "refresh" // stored comics, see RefreshFilter
+	ModeReindex UpdateMode = "reindex" // normalize stored comics again
+)
+
+// RefreshFilter selects stored comics to re-fetch. FromID and ToID bound
+// an inclusive ID range, OlderThan picks comics fetched that long ago or
+// never recorded. Zero values do not restrict, but one must be set.
+type RefreshFilter struct {
+	FromID    int
+	ToID      int
+	OlderThan time.Duration
+}
+
+type UpdateOptions struct {
+	Mode    UpdateMode
+	Refresh RefreshFilter
+}
+
+type JobState string
+
+const (
+	JobRunning  JobState = "running"
+	JobDone     JobState = "done"
+	JobFailed   JobState = "failed"
+	JobCanceled JobState = "canceled"
+)
+
+// JobProgress is a snapshot of an update job. Fetched counts comics
+// stored or found unchanged, Failed the ones given up on and InFlight the ones picked by
+// workers but not finished yet. ETA is zero until the first comic is done.
+type JobProgress struct {
+	ID         string
+	State      JobState
+	Total      int
+	Fetched    int
+	Failed     int
+	InFlight   int
+	ETA        time.Duration
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Error      string
+}
+
+type EventType string
+
+const (
+	EventProgress EventType = "progress" // snapshot of the latest job
+	EventStarted  EventType = "started"
+	EventFetched  EventType = "fetched"
+	EventFailed   EventType = "failed"
+	EventSaved    EventType = "saved"
+	EventSkipped  EventType = "skipped" // refetched comic did not change
+	EventFinished EventType = "finished"
+)
+
+// UpdateEvent reports a step of an update job. ComicID is set for
+// per-comic events and Progress holds the job state right after the step.
+type UpdateEvent struct {
+	Type     EventType
+	ComicID  int
+	Error    string
+	Progress JobProgress
+}
+
+// UpdateRun is a persisted record of an update job.
+type UpdateRun struct {
+	ID         string
+	Trigger    Trigger
+	Mode       UpdateMode
+	State      JobState
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Total      int
+	Fetched    int
+	Failed     int
+	Error      string
+}
+
+// FailedComic is an entry of the failure ledger: a comic an update
+// gave up on. It is removed once the comic is saved.
+type FailedComic struct {
+	ID       int
+	RunID    string
+	Error    string
+	Attempts int
+	FailedAt time.Time
+}
@@ -2,31 +2,99 @@ package core
 
 import (
 	"context"
+	"time"
 )
 
 type Updater interface {
-	Update(context.Context) error
+	Update(ctx context.Context, trigger Trigger, opts UpdateOptions) (string, error)
+	Reindex(ctx context.Context, trigger Trigger) (string, error)
+	Progress(ctx context.Context, id string) (JobProgress, error)
+	Cancel(ctx context.Context, id string) error
+	Watch(context.Context) <-chan UpdateEvent
 	Stats(context.Context) (ServiceStats, error)
+	Runs(ctx context.Context, limit int) ([]UpdateRun, []FailedComic, error)
 	Status(context.Context) ServiceStatus
 	Drop(context.Context) error
+
+	DeleteComic(ctx context.Context, id int) error
+	HideComic(ctx context.Context, id int, hidden bool) error
+	RefetchComic(ctx context.Context, id int) error
+	// Image returns a stored comic image, ErrNotFound if there is none.
+	Image(ctx context.Context, id int) (ComicImage, []byte, error)
+	// Coverage lists poorly indexed comics, see DB.Coverage.
+	Coverage(ctx context.Context, limit int) ([]ComicCoverage, int, error)
+
+	Annotation(ctx context.Context, id int) (Annotation, error)
+	// Annotate replaces the annotation of a comic and indexes it again.
+	Annotate(ctx context.Context, note Annotation) error
+	DeleteAnnotation(ctx context.Context, id int) error
+}
+
+type Scheduler interface {
+	NextRun() time.Time
 }
 
 type DB interface {
+	// Add stores a comic and removes it from the failure ledger.
+	// A deleted comic is restored.
 	Add(context.Context, Comics) error
 	Stats(context.Context) (DBStats, error)
 	Drop(context.Context) error
+	// IDs lists all stored comics, deleted ones included.
 	IDs(context.Context) ([]int, error)
+	// Count counts the comics Scan goes through.
+	Count(context.Context) (int, error)
+	// Hashes returns content hashes of stored comics matching the filter.
+	Hashes(context.Context, RefreshFilter) (map[int]string, error)
+	// Touch marks a comic as fetched now without changing it.
+	Touch(ctx context.Context, id int) error
+	// Scan calls fn for every stored comic not deleted until fn returns an error.
+	Scan(ctx context.Context, fn func(Comics) error) error
+	SetWords(ctx context.Context, id int, words []string) error
+	// Delete marks a comic deleted, so it is not fetched again.
+	Delete(ctx context.Context, id int) error
+	SetHidden(ctx context.Context, id int, hidden bool) error
+	// Image returns the image of a visible comic, ErrNotFound if it has none.
+	Image(ctx context.Context, id int) (ComicImage, error)
+	// Coverage returns up to limit comics without a transcript, fewest
+	// tokens first, and how many such comics there are.
+	Coverage(ctx context.Context, limit int) ([]ComicCoverage, int, error)
+	// Comic returns the stored text of a comic, ErrNotFound if there is none.
+	Comic(ctx context.Context, id int) (Comics, error)
+
+	// Annotations returns annotations of the given comics, if any.
+	Annotations(ctx context.Context, ids []int) (map[int]Annotation, error)
+	// Annotation returns ErrNotFound if the comic is not annotated.
+	Annotation(ctx context.Context, id int) (Annotation, error)
+	SetAnnotation(ctx context.Context, note Annotation) error
+	DeleteAnnotation(ctx context.Context, id int) error
+
+	SaveRun(context.Context, UpdateRun) error
+	Runs(ctx context.Context, limit int) ([]UpdateRun, error)
+	AddFailed(context.Context, FailedComic) error
+	Failed(context.Context) ([]FailedComic, error)
 }
 
 type XKCD interface {
 	Get(context.Context, int) (XKCDInfo, error)
 	LastID(context.Context) (int, error)
+	// Image downloads a comic image by its URL.
+	Image(ctx context.Context, url string) ([]byte, error)
+}
+
+// ImageStore keeps comic images by content hash.
+type ImageStore interface {
+	Put(ctx context.Context, hash string, data []byte) error
+	// Get returns ErrNotFound for unknown hashes.
+	Get(ctx context.Context, hash string) ([]byte, error)
 }
 
 type Words interface {
-	Norm(ctx context.Context, phrase string) ([]string, error)
+	NormBatch(ctx context.Context, phrases map[int]string) (map[int][]string, error)
 }
 
 type EventBus interface {
 	PublishUpdate() error
+	// PublishComic tells a single comic was changed, hidden or deleted.
+	PublishComic(id int) error
 }
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Reindex starts a job normalizing stored comics again without fetching
+// them, for when stemming or stop-word rules of words service change.
+func (s *Service) Reindex(ctx context.Context, trigger Trigger) (string, error) {
+	return s.Update(ctx, trigger, UpdateOptions{Mode: ModeReindex})
+}
+
+func (s *Service) reindex(ctx context.Context, j *job) error {
+	count, err := s.db.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count stored comics: %w", err)
+	}
+	if count == 0 {
+		s.log.Info("no comics to reindex")
+		return nil
+	}
+	s.log.Info("comics to reindex", "count", count)
+	j.total.Store(int64(count))
+
+	var wg sync.WaitGroup
+	rows := make(chan Comics, s.concurrency)
+
+	for i := 0; i < s.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batch := make([]XKCDInfo, 0, normBatchSize)
+			for comic := range rows {
+				j.inFlight.Add(1)
+				batch = append(batch, XKCDInfo{
+					ID:         comic.ID,
+					Title:      comic.Title,
+					Alt:        comic.Alt,
+					Transcript: comic.Transcript,
+				})
+				if len(batch) == normBatchSize {
+					s.saveWords(ctx, j, batch)
+					batch = batch[:0]
+				}
+			}
+			s.saveWords(ctx, j, batch)
+		}()
+	}
+
+	err = s.db.Scan(ctx, func(comic Comics) error {
+		select {
+		case rows <- comic:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(rows)
+	wg.Wait()
+
+	// words of some comics may have changed even if the scan broke
+	if err := s.eb.PublishUpdate(); err != nil {
+		s.log.Error("failed to publish update event", "error", err)
+	}
+
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read stored comics: %w", err)
+	}
+	return nil
+}
+
+// saveWords normalizes a batch of stored comics and replaces their words.
+// Words are kept as is when normalization fails.
+func (s *Service) saveWords(ctx context.Context, j *job, batch []XKCDInfo) {
+	if len(batch) == 0 {
+		return
+	}
+
+	keywords, err := s.normalize(ctx, batch)
+	if ctx.Err() != nil {
+		j.inFlight.Add(-int64(len(batch)))
+		return
+	}
+	if err != nil {
+		s.log.Error("failed to get keywords", "count", len(batch), "error", err)
+		for _, comic := range batch {
+			j.inFlight.Add(-1)
+			s.fail(ctx, j, comic.ID, err)
+		}
+		return
+	}
+
+	for _, comic := range batch {
+		words, ok := keywords[comic.ID]
+		if !ok {
+			words = []string{}
+		}
+
+		err := s.db.SetWords(ctx, comic.ID, words)
+		j.inFlight.Add(-1)
+		if err != nil {
+			s.log.Warn("failed to update words", "id", comic.ID, "error", err)
+			s.fail(ctx, j, comic.ID, err)
+			continue
+		}
+		j.fetched.Add(1)
+		s.publish(j, EventSaved, comic.ID, nil)
+	}
+}
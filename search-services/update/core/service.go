@@ -2,26 +2,50 @@ package core
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
+// normBatchSize is how many fetched comics a worker normalizes in one Words call.
+const normBatchSize = 50
+
+// watchBuffer is how many events a watcher may lag behind before
+// new events are dropped for it.
+const watchBuffer = 256
+
+// latestTTL is how long Stats trusts the cached latest comic ID.
+const latestTTL = 10 * time.Minute
+
 type Service struct {
 	log         *slog.Logger
 	db          DB
 	xkcd        XKCD
 	words       Words
 	eb          EventBus
+	images      ImageStore // nil if images are not stored
 	concurrency int
 
-	mu     sync.Mutex
-	status ServiceStatus
+	mu      sync.Mutex
+	current *job   // running update, if any
+	jobs    []*job // recent updates, oldest first
+
+	watchersMu sync.Mutex
+	watchers   map[chan UpdateEvent]struct{}
+
+	latestMu sync.Mutex
+	latestID int       // latest published comic, as last seen on XKCD
+	latestAt time.Time // when latestID was checked
 }
 
 func NewService(
-	log *slog.Logger, db DB, xkcd XKCD, words Words, eb EventBus, concurrency int,
+	log *slog.Logger, db DB, xkcd XKCD, words Words, eb EventBus, images ImageStore, concurrency int,
 ) (*Service, error) {
 	if concurrency < 1 {
 		return nil, fmt.Errorf("wrong concurrency specified: %d", concurrency)
@@ -32,59 +56,252 @@ func NewService(
 		xkcd:        xkcd,
 		words:       words,
 		eb:          eb,
+		images:      images,
 		concurrency: concurrency,
-		status:      StatusIdle,
+		watchers:    make(map[chan UpdateEvent]struct{}),
 	}, nil
 }
 
-func (s *Service) Update(ctx context.Context) (err error) {
+// Update starts an update job in background and returns its ID. If an
+// update is already running, its ID is returned with ErrUpdateInProgress.
+func (s *Service) Update(_ context.Context, trigger Trigger, opts UpdateOptions) (string, error) {
+	if err := checkOptions(opts); err != nil {
+		return "", err
+	}
+
 	s.mu.Lock()
-	if s.status == StatusRunning {
-		s.mu.Unlock()
-		return ErrUpdateInProgress
+	defer s.mu.Unlock()
+
+	if s.current != nil {
+		return s.current.id, ErrUpdateInProgress
 	}
 
-	s.status = StatusRunning
+	// the job outlives the request that started it
+	ctx, cancel := context.WithCancel(context.Background())
+	j := newJob(cancel, trigger, opts)
+	s.current = j
+	s.jobs = append(s.jobs, j)
+	if len(s.jobs) > maxJobs {
+		s.jobs = s.jobs[len(s.jobs)-maxJobs:]
+	}
+
+	go s.run(ctx, j)
+	return j.id, nil
+}
+
+func checkOptions(opts UpdateOptions) error {
+	switch opts.Mode {
+	case ModeNew, ModeFailed, ModeReindex:
+		return nil
+	case ModeRefresh:
+		f := opts.Refresh
+		if f.FromID < 0 || f.ToID < 0 || f.OlderThan < 0 || (f.ToID > 0 && f.ToID < f.FromID) {
+			return fmt.Errorf("%w: bad refresh filter", ErrBadArguments)
+		}
+		if f.FromID == 0 && f.ToID == 0 && f.OlderThan == 0 {
+			return fmt.Errorf("%w: refresh needs an ID range or age", ErrBadArguments)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown update mode %q", ErrBadArguments, opts.Mode)
+	}
+}
+
+// ContentHash identifies the content of a comic, so a refetched comic
+// is normalized again only when it changed.
+func ContentHash(info XKCDInfo) string {
+	h := sha256.New()
+	for _, field := range []string{info.URL, info.Title, info.SafeTitle, info.Alt, info.Transcript} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Progress reports the state of a recent update job.
+func (s *Service) Progress(_ context.Context, id string) (JobProgress, error) {
+	j, err := s.job(id)
+	if err != nil {
+		return JobProgress{}, err
+	}
+	return j.progress(), nil
+}
+
+// Cancel stops a running update job. Comics saved so far are kept.
+// Cancelling a finished job does nothing.
+func (s *Service) Cancel(_ context.Context, id string) error {
+	j, err := s.job(id)
+	if err != nil {
+		return err
+	}
+	s.log.Info("cancelling update", "job", id)
+	j.cancel()
+	return nil
+}
+
+// Watch streams events of all update jobs until ctx is done. The first
+// event is a snapshot of the latest job, if any. A watcher that does not
+// keep up misses events, progress in the next one is still accurate.
+func (s *Service) Watch(ctx context.Context) <-chan UpdateEvent {
+	ch := make(chan UpdateEvent, watchBuffer)
+
+	s.mu.Lock()
+	snapshot := UpdateEvent{Type: EventProgress}
+	if len(s.jobs) > 0 {
+		snapshot.Progress = s.jobs[len(s.jobs)-1].progress()
+	}
 	s.mu.Unlock()
+	ch <- snapshot
+
+	s.watchersMu.Lock()
+	s.watchers[ch] = struct{}{}
+	s.watchersMu.Unlock()
 
-	s.log.Info("starting comics update")
-	defer func() {
-		s.mu.Lock()
-		s.status = StatusIdle
-		s.mu.Unlock()
-		s.log.Info("update finished")
+	go func() {
+		<-ctx.Done()
+		s.watchersMu.Lock()
+		delete(s.watchers, ch)
+		close(ch)
+		s.watchersMu.Unlock()
 	}()
 
-	maxID, err := s.xkcd.LastID(ctx)
+	return ch
+}
+
+func (s *Service) publish(j *job, eventType EventType, comicID int, err error) {
+	event := UpdateEvent{
+		Type:     eventType,
+		ComicID:  comicID,
+		Progress: j.progress(),
+	}
 	if err != nil {
-		return fmt.Errorf("failed to fetch latest comic: %w", err)
+		event.Error = err.Error()
 	}
 
-	savedIDs, err := s.db.IDs(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get saved IDs: %w", err)
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+	for ch := range s.watchers {
+		select {
+		case ch <- event:
+		default:
+		}
 	}
+}
 
-	savedIDsMap := make(map[int]struct{}, len(savedIDs))
-	for _, id := range savedIDs {
-		savedIDsMap[id] = struct{}{}
+func (s *Service) job(id string) (*job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.id == id {
+			return j, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *Service) run(ctx context.Context, j *job) {
+	s.log.Info("starting comics update", "job", j.id, "trigger", j.trigger, "mode", j.mode)
+	s.publish(j, EventStarted, 0, nil)
+
+	// failed comics refer to the run, so it does not go without its record
+	err := s.db.SaveRun(ctx, j.run())
+	switch {
+	case err != nil:
+		err = fmt.Errorf("failed to save update run: %w", err)
+	case j.mode == ModeReindex:
+		err = s.reindex(ctx, j)
+	default:
+		err = s.update(ctx, j)
+	}
+	switch {
+	case ctx.Err() != nil:
+		j.finish(JobCanceled, nil)
+	case err != nil:
+		s.log.Error("update failed", "job", j.id, "error", err)
+		j.finish(JobFailed, err)
+	default:
+		j.finish(JobDone, nil)
+	}
+	j.cancel()
+	s.saveRun(j)
+
+	s.mu.Lock()
+	s.current = nil
+	s.mu.Unlock()
+	s.publish(j, EventFinished, 0, err)
+	s.log.Info("update finished", "job", j.id)
+}
+
+// saveRun records the end of the job in the run history, a failure
+// to write it does not change the outcome of the update.
+func (s *Service) saveRun(j *job) {
+	if err := s.db.SaveRun(context.Background(), j.run()); err != nil {
+		s.log.Error("failed to save update run", "job", j.id, "error", err)
+	}
+}
+
+// skip marks an unchanged comic as fetched without normalizing it again.
+func (s *Service) skip(ctx context.Context, j *job, id int) {
+	j.inFlight.Add(-1)
+	if err := s.db.Touch(ctx, id); err != nil {
+		s.log.Warn("failed to mark comic as fetched", "id", id, "error", err)
+		s.fail(ctx, j, id, err)
+		return
+	}
+	j.fetched.Add(1)
+	s.publish(j, EventSkipped, id, nil)
+}
+
+// fail records a comic the job gave up on in the failure ledger.
+func (s *Service) fail(ctx context.Context, j *job, id int, err error) {
+	j.failed.Add(1)
+	s.publish(j, EventFailed, id, err)
+	failed := FailedComic{
+		ID:       id,
+		RunID:    j.id,
+		Error:    err.Error(),
+		FailedAt: time.Now(),
+	}
+	if err := s.db.AddFailed(ctx, failed); err != nil {
+		s.log.Error("failed to record failed comic", "id", id, "error", err)
 	}
+}
 
+func (s *Service) update(ctx context.Context, j *job) error {
 	var comicsToFetch []int
-	for id := 1; id <= maxID; id++ {
-		if id == 404 {
-			continue
+	var known map[int]string // content hashes of refetched comics
+	var err error
+	switch j.mode {
+	case ModeFailed:
+		comicsToFetch, err = s.failedIDs(ctx)
+	case ModeRefresh:
+		known, err = s.db.Hashes(ctx, j.refresh)
+		if err != nil {
+			return fmt.Errorf("failed to get stored comics: %w", err)
 		}
-		if _, exists := savedIDsMap[id]; !exists {
+		for id := range known {
 			comicsToFetch = append(comicsToFetch, id)
 		}
+		slices.Sort(comicsToFetch)
+	default:
+		comicsToFetch, err = s.missingIDs(ctx)
+	}
+	if err != nil {
+		return err
 	}
 
 	if len(comicsToFetch) == 0 {
-		s.log.Info("no new comics to fetch, database is up to date")
+		s.log.Info("no comics to fetch, database is up to date", "mode", j.mode)
 		return nil
 	}
 	s.log.Info("comics to fetch", "count", len(comicsToFetch))
+	j.total.Store(int64(len(comicsToFetch)))
+
+	// the run stops once XKCD is unavailable, comics left are not failed
+	// but fetched by the next run
+	ctx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
 
 	var wg sync.WaitGroup
 	jobs := make(chan int, s.concurrency)
@@ -93,69 +310,56 @@ func (s *Service) Update(ctx context.Context) (err error) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			batch := make([]XKCDInfo, 0, normBatchSize)
+			defer func() {
+				s.save(ctx, j, batch)
+			}()
 			for id := range jobs {
-				var comicData XKCDInfo
-				var err error
-				for attempt := 0; attempt < 10; attempt++ {
-					comicData, err = s.xkcd.Get(ctx, id)
-					if err == nil {
-						break
-					}
-					s.log.Warn("failed to fetch comic, retrying", "id", id, "attempt", attempt+1, "error", err)
-					select {
-					case <-ctx.Done():
-						return
-					case <-time.After(1 * time.Second):
-					}
-				}
+				j.inFlight.Add(1)
+				comicData, err := s.xkcd.Get(ctx, id)
 				if err != nil {
-					s.log.Error("failed to fetch comic after retries", "id", id, "error", err)
-					continue
-				}
-
-				textToNorm := comicData.Alt + " " + comicData.Title + " " + comicData.Transcript
-
-				var keywords []string
-
-				for attempt := 0; attempt < 10; attempt++ {
-					keywords, err = s.words.Norm(ctx, textToNorm)
-					if err == nil {
-						break
+					j.inFlight.Add(-1)
+					if ctx.Err() != nil {
+						return
 					}
-					s.log.Warn("failed to normalize words, retrying", "id", id, "attempt", attempt+1, "error", err)
-					select {
-					case <-ctx.Done():
+					if errors.Is(err, ErrUnavailable) {
+						abort(err)
 						return
-					case <-time.After(1 * time.Second):
 					}
+					if errors.Is(err, ErrNotFound) {
+						// never published, like #404: not a comic to count
+						s.log.Info("comic does not exist, skipping", "id", id)
+						j.total.Add(-1)
+						s.publish(j, EventSkipped, id, err)
+						continue
+					}
+					s.log.Error("failed to fetch comic", "id", id, "error", err)
+					s.fail(ctx, j, id, err)
+					continue
 				}
-				if err != nil {
-					s.log.Error("failed to normalize words after retries", "id", id, "error", err)
-					keywords = []string{}
-				}
+				s.publish(j, EventFetched, id, nil)
 
-				comicToSave := Comics{
-					ID:         comicData.ID,
-					URL:        comicData.URL,
-					Words:      keywords,
-					Title:      comicData.Title,
-					Alt:        comicData.Alt,
-					Transcript: comicData.Transcript,
-					SafeTitle:  comicData.SafeTitle,
+				if hash, ok := known[id]; ok && hash == ContentHash(comicData) {
+					s.skip(ctx, j, id)
+					continue
 				}
 
-				err = s.db.Add(ctx, comicToSave)
-				if err != nil {
-					s.log.Warn("failed to save comic", "id", id, "error", err)
-					continue
+				batch = append(batch, comicData)
+				if len(batch) == normBatchSize {
+					s.save(ctx, j, batch)
+					batch = batch[:0]
 				}
-				s.log.Debug("successfully saved comic", "id", id)
 			}
 		}()
 	}
 
+feed:
 	for _, id := range comicsToFetch {
-		jobs <- id
+		select {
+		case jobs <- id:
+		case <-ctx.Done():
+			break feed
+		}
 	}
 	close(jobs)
 	wg.Wait()
@@ -164,9 +368,135 @@ func (s *Service) Update(ctx context.Context) (err error) {
 		s.log.Error("failed to publish update event", "error", err)
 	}
 
+	if cause := context.Cause(ctx); errors.Is(cause, ErrUnavailable) {
+		return fmt.Errorf("update stopped: %w", cause)
+	}
 	return nil
 }
 
+// unpublishedComic is the ID XKCD skipped, it is never fetched.
+const unpublishedComic = 404
+
+// missingIDs lists comics published but not saved yet.
+func (s *Service) missingIDs(ctx context.Context) ([]int, error) {
+	maxID, err := s.lastID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest comic: %w", err)
+	}
+
+	savedIDs, err := s.db.IDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved IDs: %w", err)
+	}
+
+	savedIDsMap := make(map[int]struct{}, len(savedIDs))
+	for _, id := range savedIDs {
+		savedIDsMap[id] = struct{}{}
+	}
+
+	var ids []int
+	for id := 1; id <= maxID; id++ {
+		if id == unpublishedComic {
+			continue
+		}
+		if _, exists := savedIDsMap[id]; !exists {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// failedIDs lists comics from the failure ledger.
+func (s *Service) failedIDs(ctx context.Context) ([]int, error) {
+	failed, err := s.db.Failed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failed comics: %w", err)
+	}
+
+	ids := make([]int, 0, len(failed))
+	for _, f := range failed {
+		ids = append(ids, f.ID)
+	}
+	return ids, nil
+}
+
+// save normalizes a batch of fetched comics in one Words call and stores them.
+func (s *Service) save(ctx context.Context, j *job, batch []XKCDInfo) {
+	if len(batch) == 0 {
+		return
+	}
+
+	keywords, err := s.normalize(ctx, batch)
+	if ctx.Err() != nil {
+		j.inFlight.Add(-int64(len(batch)))
+		return
+	}
+	if err != nil {
+		// comics without words are never found, leave them to retry-failed
+		s.log.Error("failed to get keywords", "count", len(batch), "error", err)
+		for _, comicData := range batch {
+			j.inFlight.Add(-1)
+			s.fail(ctx, j, comicData.ID, err)
+		}
+		return
+	}
+
+	for _, comicData := range batch {
+		words, ok := keywords[comicData.ID]
+		if !ok {
+			words = []string{}
+		}
+
+		err := s.db.Add(ctx, s.comic(ctx, comicData, words))
+		j.inFlight.Add(-1)
+		if err != nil {
+			s.log.Warn("failed to save comic", "id", comicData.ID, "error", err)
+			s.fail(ctx, j, comicData.ID, err)
+			continue
+		}
+		j.fetched.Add(1)
+		s.publish(j, EventSaved, comicData.ID, nil)
+		s.log.Debug("successfully saved comic", "id", comicData.ID)
+	}
+}
+
+// normalize turns text of comics and their annotations into keywords in
+// one Words call, retried on failure until ctx is done.
+func (s *Service) normalize(ctx context.Context, batch []XKCDInfo) (map[int][]string, error) {
+	ids := make([]int, 0, len(batch))
+	for _, comicData := range batch {
+		ids = append(ids, comicData.ID)
+	}
+	notes, err := s.db.Annotations(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotations: %w", err)
+	}
+
+	phrases := make(map[int]string, len(batch))
+	for _, comicData := range batch {
+		phrase := comicData.Alt + " " + comicData.Title + " " + comicData.Transcript
+		if note, ok := notes[comicData.ID]; ok {
+			phrase += " " + note.Transcript + " " + strings.Join(note.Keywords, " ")
+		}
+		phrases[comicData.ID] = phrase
+	}
+
+	var keywords map[int][]string
+	for attempt := 0; attempt < 10; attempt++ {
+		keywords, err = s.words.NormBatch(ctx, phrases)
+		if err == nil {
+			return keywords, nil
+		}
+		s.log.Warn("failed to normalize words, retrying", "count", len(batch), "attempt", attempt+1, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
+	}
+	return nil, err
+}
+
 func (s *Service) Stats(ctx context.Context) (ServiceStats, error) {
 	s.log.Debug("getting stats")
 
@@ -176,29 +506,105 @@ func (s *Service) Stats(ctx context.Context) (ServiceStats, error) {
 		return ServiceStats{}, err
 	}
 
-	maxID, err := s.xkcd.LastID(ctx)
+	maxID, checkedAt, err := s.latest(ctx)
 	if err != nil {
 		s.log.Error("failed to fetch latest comic from xkcd", "error", err)
 		return ServiceStats{}, err
 	}
 
-	if maxID >= 404 {
+	if maxID >= unpublishedComic {
 		maxID--
 	}
 
 	stats := ServiceStats{
-		DBStats:     dbStats,
-		ComicsTotal: maxID,
+		DBStats:         dbStats,
+		ComicsTotal:     maxID,
+		LatestCheckedAt: checkedAt,
 	}
 
 	return stats, nil
 }
 
+// latest returns the cached latest comic ID, asking XKCD again once it
+// is older than latestTTL. A stale ID is kept if XKCD can't be reached.
+func (s *Service) latest(ctx context.Context) (int, time.Time, error) {
+	s.latestMu.Lock()
+	id, at := s.latestID, s.latestAt
+	s.latestMu.Unlock()
+	if !at.IsZero() && time.Since(at) < latestTTL {
+		return id, at, nil
+	}
+
+	fresh, err := s.lastID(ctx)
+	if err != nil {
+		if at.IsZero() {
+			return 0, time.Time{}, err
+		}
+		s.log.Warn("using stale latest comic", "id", id, "checked_at", at, "error", err)
+		return id, at, nil
+	}
+
+	s.latestMu.Lock()
+	defer s.latestMu.Unlock()
+	return fresh, s.latestAt, nil
+}
+
+// lastID asks XKCD for the latest comic and caches it for Stats.
+func (s *Service) lastID(ctx context.Context) (int, error) {
+	id, err := s.xkcd.LastID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	s.latestMu.Lock()
+	s.latestID, s.latestAt = id, time.Now()
+	s.latestMu.Unlock()
+	return id, nil
+}
+
+// Coverage returns up to limit comics lacking a transcript, fewest
+// tokens first, and the number of such comics.
+func (s *Service) Coverage(ctx context.Context, limit int) ([]ComicCoverage, int, error) {
+	if limit < 1 {
+		return nil, 0, fmt.Errorf("%w: limit must be positive", ErrBadArguments)
+	}
+
+	comics, total, err := s.db.Coverage(ctx, limit)
+	if err != nil {
+		s.log.Error("failed to get text coverage", "error", err)
+		return nil, 0, err
+	}
+	return comics, total, nil
+}
+
+// Runs returns up to limit latest update runs and the failure ledger.
+func (s *Service) Runs(ctx context.Context, limit int) ([]UpdateRun, []FailedComic, error) {
+	if limit < 1 {
+		return nil, nil, fmt.Errorf("%w: limit must be positive", ErrBadArguments)
+	}
+
+	runs, err := s.db.Runs(ctx, limit)
+	if err != nil {
+		s.log.Error("failed to get update runs", "error", err)
+		return nil, nil, err
+	}
+
+	failed, err := s.db.Failed(ctx)
+	if err != nil {
+		s.log.Error("failed to get failed comics", "error", err)
+		return nil, nil, err
+	}
+
+	return runs, failed, nil
+}
+
 func (s *Service) Status(ctx context.Context) ServiceStatus {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.status
+	if s.current != nil {
+		return StatusRunning
+	}
+	return StatusIdle
 }
 
 func (s *Service) Drop(ctx context.Context) error {
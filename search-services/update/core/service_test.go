@@ -1,13 +1,21 @@
 package core_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/png"
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"yadro.com/course/imagehash"
 	"yadro.com/course/update/core"
 )
 
@@ -38,6 +46,117 @@ func (m *MockDB) IDs(ctx context.Context) ([]int, error) {
 	return args.Get(0).([]int), args.Error(1)
 }
 
+func (m *MockDB) Count(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDB) Hashes(ctx context.Context, filter core.RefreshFilter) (map[int]string, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]string), args.Error(1)
+}
+
+func (m *MockDB) Touch(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// Scan feeds fn the comics given to Return.
+func (m *MockDB) Scan(ctx context.Context, fn func(core.Comics) error) error {
+	args := m.Called(ctx)
+	for _, comic := range args.Get(0).([]core.Comics) {
+		if err := fn(comic); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockDB) SetWords(ctx context.Context, id int, words []string) error {
+	args := m.Called(ctx, id, words)
+	return args.Error(0)
+}
+
+func (m *MockDB) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDB) SetHidden(ctx context.Context, id int, hidden bool) error {
+	args := m.Called(ctx, id, hidden)
+	return args.Error(0)
+}
+
+func (m *MockDB) Coverage(ctx context.Context, limit int) ([]core.ComicCoverage, int, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]core.ComicCoverage), args.Int(1), args.Error(2)
+}
+
+func (m *MockDB) Comic(ctx context.Context, id int) (core.Comics, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(core.Comics), args.Error(1)
+}
+
+func (m *MockDB) Annotations(ctx context.Context, ids []int) (map[int]core.Annotation, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]core.Annotation), args.Error(1)
+}
+
+func (m *MockDB) Annotation(ctx context.Context, id int) (core.Annotation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(core.Annotation), args.Error(1)
+}
+
+func (m *MockDB) SetAnnotation(ctx context.Context, note core.Annotation) error {
+	args := m.Called(ctx, note)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteAnnotation(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDB) Image(ctx context.Context, id int) (core.ComicImage, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(core.ComicImage), args.Error(1)
+}
+
+func (m *MockDB) SaveRun(ctx context.Context, run core.UpdateRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockDB) Runs(ctx context.Context, limit int) ([]core.UpdateRun, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]core.UpdateRun), args.Error(1)
+}
+
+func (m *MockDB) AddFailed(ctx context.Context, failed core.FailedComic) error {
+	args := m.Called(ctx, failed)
+	return args.Error(0)
+}
+
+func (m *MockDB) Failed(ctx context.Context) ([]core.FailedComic, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]core.FailedComic), args.Error(1)
+}
+
 type MockXKCD struct {
 	mock.Mock
 }
@@ -52,16 +171,41 @@ func (m *MockXKCD) LastID(ctx context.Context) (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockXKCD) Image(ctx context.Context, url string) ([]byte, error) {
+	args := m.Called(ctx, url)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+type MockImageStore struct {
+	mock.Mock
+}
+
+func (m *MockImageStore) Put(ctx context.Context, hash string, data []byte) error {
+	args := m.Called(ctx, hash, data)
+	return args.Error(0)
+}
+
+func (m *MockImageStore) Get(ctx context.Context, hash string) ([]byte, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
 type MockWords struct {
 	mock.Mock
 }
 
-func (m *MockWords) Norm(ctx context.Context, phrase string) ([]string, error) {
-	args := m.Called(ctx, phrase)
+func (m *MockWords) NormBatch(ctx context.Context, phrases map[int]string) (map[int][]string, error) {
+	args := m.Called(ctx, phrases)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]string), args.Error(1)
+	return args.Get(0).(map[int][]string), args.Error(1)
 }
 
 type MockEventBus struct {
@@ -73,12 +217,29 @@ func (m *MockEventBus) PublishUpdate() error {
 	return args.Error(0)
 }
 
+func (m *MockEventBus) PublishComic(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 var log = slog.New(slog.NewTextHandler(os.Stderr, nil))
 
+func waitJob(t *testing.T, service *core.Service, id string) core.JobProgress {
+	t.Helper()
+	var progress core.JobProgress
+	assert.Eventually(t, func() bool {
+		var err error
+		progress, err = service.Progress(context.Background(), id)
+		return err == nil && progress.State != core.JobRunning &&
+			service.Status(context.Background()) == core.StatusIdle
+	}, 5*time.Second, 10*time.Millisecond)
+	return progress
+}
+
 func TestUpdateStats(t *testing.T) {
 	mockDB := new(MockDB)
 	mockXKCD := new(MockXKCD)
-	service, err := core.NewService(log, mockDB, mockXKCD, nil, nil, 1)
+	service, err := core.NewService(log, mockDB, mockXKCD, nil, nil, nil, 1)
 	assert.NoError(t, err)
 
 	mockDB.On("Stats", mock.Anything).Return(core.DBStats{WordsTotal: 10}, nil).Once()
@@ -88,12 +249,21 @@ func TestUpdateStats(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 10, stats.WordsTotal)
 	assert.Equal(t, 2, stats.ComicsTotal)
+	assert.False(t, stats.LatestCheckedAt.IsZero())
+
+	// the latest ID is cached, XKCD is not asked again
+	mockDB.On("Stats", mock.Anything).Return(core.DBStats{WordsTotal: 11}, nil).Once()
+	again, err := service.Stats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, again.ComicsTotal)
+	assert.Equal(t, stats.LatestCheckedAt, again.LatestCheckedAt)
+	mockXKCD.AssertExpectations(t)
 }
 
 func TestDrop(t *testing.T) {
 	mockDB := new(MockDB)
 	mockBus := new(MockEventBus)
-	service, err := core.NewService(log, mockDB, nil, nil, mockBus, 1)
+	service, err := core.NewService(log, mockDB, nil, nil, mockBus, nil, 1)
 	assert.NoError(t, err)
 
 	mockDB.On("Drop", mock.Anything).Return(nil).Once()
@@ -105,7 +275,7 @@ func TestDrop(t *testing.T) {
 }
 
 func TestUpdate_AlreadyRunning(t *testing.T) {
-	service, err := core.NewService(log, nil, nil, nil, nil, 1)
+	service, err := core.NewService(log, nil, nil, nil, nil, nil, 1)
 	assert.NoError(t, err)
 	assert.Equal(t, core.StatusIdle, service.Status(context.Background()))
 }
@@ -116,9 +286,10 @@ func TestUpdate(t *testing.T) {
 	mockWords := new(MockWords)
 	mockBus := new(MockEventBus)
 
-	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, 1)
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
 	assert.NoError(t, err)
 
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
 	mockXKCD.On("LastID", mock.Anything).Return(2, nil).Once()
 
 	mockDB.On("IDs", mock.Anything).Return([]int{1}, nil).Once()
@@ -128,20 +299,693 @@ func TestUpdate(t *testing.T) {
 	}
 	mockXKCD.On("Get", mock.Anything, 2).Return(comic2, nil).Once()
 
-	mockWords.On("Norm", mock.Anything, "a t tr").Return([]string{"kw"}, nil).Once()
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(map[int]core.Annotation{}, nil)
+	mockWords.On("NormBatch", mock.Anything, map[int]string{2: "a t tr"}).Return(map[int][]string{2: {"kw"}}, nil).Once()
 
 	expectedComic := core.Comics{
 		ID: 2, URL: "url2", Title: "t", Alt: "a", Transcript: "tr", SafeTitle: "st", Words: []string{"kw"},
+		ContentHash: core.ContentHash(comic2),
+		Coverage:    core.TextCoverage{TitleTokens: 1, AltTokens: 1, TranscriptTokens: 1},
 	}
 	mockDB.On("Add", mock.Anything, expectedComic).Return(nil).Once()
 
 	mockBus.On("PublishUpdate").Return(nil).Once()
 
-	err = service.Update(context.Background())
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
 	assert.NoError(t, err)
 
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobDone, progress.State)
+	assert.Equal(t, 1, progress.Total)
+	assert.Equal(t, 1, progress.Fetched)
+	assert.Equal(t, 0, progress.Failed)
+	assert.Equal(t, 0, progress.InFlight)
+	assert.False(t, progress.FinishedAt.IsZero())
+
 	mockXKCD.AssertExpectations(t)
 	mockDB.AssertExpectations(t)
 	mockWords.AssertExpectations(t)
 	mockBus.AssertExpectations(t)
 }
+
+func TestUpdate_Batch(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(3, nil).Once()
+	mockDB.On("IDs", mock.Anything).Return([]int{}, nil).Once()
+	for id := 1; id <= 3; id++ {
+		mockXKCD.On("Get", mock.Anything, id).Return(core.XKCDInfo{ID: id, Title: "t"}, nil).Once()
+	}
+
+	hashT := core.ContentHash(core.XKCDInfo{Title: "t"})
+	coverT := core.TextCoverage{TitleTokens: 1}
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(map[int]core.Annotation{}, nil)
+	mockWords.On("NormBatch", mock.Anything, map[int]string{1: " t ", 2: " t ", 3: " t "}).
+		Return(map[int][]string{1: {"t"}, 2: {"t"}}, nil).Once()
+
+	mockDB.On("Add", mock.Anything, core.Comics{ID: 1, Title: "t", Words: []string{"t"}, ContentHash: hashT, Coverage: coverT}).Return(nil).Once()
+	mockDB.On("Add", mock.Anything, core.Comics{ID: 2, Title: "t", Words: []string{"t"}, ContentHash: hashT, Coverage: coverT}).Return(nil).Once()
+	mockDB.On("Add", mock.Anything, core.Comics{ID: 3, Title: "t", Words: []string{}, ContentHash: hashT, Coverage: coverT}).Return(nil).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+	waitJob(t, service, id)
+
+	mockWords.AssertExpectations(t)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdate_Failed(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	service, err := core.NewService(log, mockDB, mockXKCD, nil, nil, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(0, errors.New("unavailable")).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobFailed, progress.State)
+	assert.Contains(t, progress.Error, "unavailable")
+	assert.Equal(t, core.StatusIdle, service.Status(context.Background()))
+}
+
+func TestUpdate_RunNotSaved(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	service, err := core.NewService(log, mockDB, mockXKCD, nil, nil, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(errors.New("db is down")).Once()
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobFailed, progress.State)
+	assert.Contains(t, progress.Error, "db is down")
+	mockXKCD.AssertNotCalled(t, "LastID", mock.Anything)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdate_FetchErrors(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, nil, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(2, nil).Once()
+	mockDB.On("IDs", mock.Anything).Return([]int{}, nil).Once()
+	mockXKCD.On("Get", mock.Anything, 1).Return(core.XKCDInfo{}, core.ErrNotFound).Once()
+	mockXKCD.On("Get", mock.Anything, 2).Return(core.XKCDInfo{}, errors.New("timeout")).Once()
+	mockDB.On("AddFailed", mock.Anything, mock.MatchedBy(func(f core.FailedComic) bool {
+		return f.ID == 2
+	})).Return(nil).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobDone, progress.State)
+	assert.Equal(t, 1, progress.Total)
+	assert.Equal(t, 0, progress.Fetched)
+	assert.Equal(t, 1, progress.Failed)
+
+	mockXKCD.AssertExpectations(t)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdate_Unavailable(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, nil, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(3, nil).Once()
+	mockDB.On("IDs", mock.Anything).Return([]int{}, nil).Once()
+	mockXKCD.On("Get", mock.Anything, 1).Return(core.XKCDInfo{}, core.ErrUnavailable).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+
+	// the run stops without marking the comics left as failed
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobFailed, progress.State)
+	assert.Contains(t, progress.Error, core.ErrUnavailable.Error())
+	assert.Equal(t, 0, progress.Failed)
+	mockDB.AssertNotCalled(t, "AddFailed", mock.Anything, mock.Anything)
+	mockXKCD.AssertExpectations(t)
+}
+
+func TestUpdate_UnpublishedComic(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, nil, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	saved := make([]int, 0, 404)
+	for id := 1; id <= 405; id++ {
+		if id != 404 {
+			saved = append(saved, id)
+		}
+	}
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(405, nil).Once()
+	mockDB.On("IDs", mock.Anything).Return(saved, nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobDone, progress.State)
+	assert.Equal(t, 0, progress.Total, "#404 is never fetched")
+	mockXKCD.AssertNotCalled(t, "Get", mock.Anything, 404)
+}
+
+func TestUpdate_InProgressAndCancel(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, nil, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(1, nil).Once()
+	mockDB.On("IDs", mock.Anything).Return([]int{}, nil).Once()
+	mockXKCD.On("Get", mock.Anything, 1).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(core.XKCDInfo{}, context.Canceled).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+	assert.Equal(t, core.StatusRunning, service.Status(context.Background()))
+
+	again, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.ErrorIs(t, err, core.ErrUpdateInProgress)
+	assert.Equal(t, id, again)
+
+	assert.NoError(t, service.Cancel(context.Background(), id))
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobCanceled, progress.State)
+	assert.Equal(t, 0, progress.InFlight)
+	assert.Equal(t, core.StatusIdle, service.Status(context.Background()))
+}
+
+func TestProgress_NotFound(t *testing.T) {
+	service, err := core.NewService(log, nil, nil, nil, nil, nil, 1)
+	assert.NoError(t, err)
+
+	_, err = service.Progress(context.Background(), "unknown")
+	assert.ErrorIs(t, err, core.ErrNotFound)
+	assert.ErrorIs(t, service.Cancel(context.Background(), "unknown"), core.ErrNotFound)
+}
+
+func TestWatch(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(2, nil).Once()
+	mockDB.On("IDs", mock.Anything).Return([]int{}, nil).Once()
+	emptyHash := core.ContentHash(core.XKCDInfo{})
+	mockXKCD.On("Get", mock.Anything, 1).Return(core.XKCDInfo{ID: 1}, nil).Once()
+	mockXKCD.On("Get", mock.Anything, 2).Return(core.XKCDInfo{ID: 2}, nil).Once()
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(map[int]core.Annotation{}, nil)
+	mockWords.On("NormBatch", mock.Anything, mock.Anything).Return(map[int][]string{}, nil).Once()
+	mockDB.On("Add", mock.Anything, core.Comics{ID: 1, Words: []string{}, ContentHash: emptyHash}).Return(errors.New("gone")).Once()
+	mockDB.On("AddFailed", mock.Anything, mock.MatchedBy(func(f core.FailedComic) bool {
+		return f.ID == 1 && f.Error == "gone"
+	})).Return(nil).Once()
+	mockDB.On("Add", mock.Anything, core.Comics{ID: 2, Words: []string{}, ContentHash: emptyHash}).Return(nil).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := service.Watch(ctx)
+
+	snapshot := <-events
+	assert.Equal(t, core.EventProgress, snapshot.Type)
+	assert.Empty(t, snapshot.Progress.ID)
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+
+	var got []core.EventType
+	var last core.UpdateEvent
+	for event := range events {
+		got = append(got, event.Type)
+		last = event
+		if event.Type == core.EventFailed {
+			assert.Equal(t, 1, event.ComicID)
+			assert.Equal(t, "gone", event.Error)
+		}
+		if event.Type == core.EventFinished {
+			break
+		}
+	}
+	assert.Equal(t, []core.EventType{
+		core.EventStarted, core.EventFetched, core.EventFetched, core.EventFailed, core.EventSaved, core.EventFinished,
+	}, got)
+	assert.Equal(t, id, last.Progress.ID)
+	assert.Equal(t, core.JobDone, last.Progress.State)
+	assert.Equal(t, 1, last.Progress.Fetched)
+	assert.Equal(t, 1, last.Progress.Failed)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestUpdate_RetryFailed(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockDB.On("Failed", mock.Anything).Return([]core.FailedComic{{ID: 5, Error: "timeout"}}, nil).Once()
+	mockXKCD.On("Get", mock.Anything, 5).Return(core.XKCDInfo{ID: 5}, nil).Once()
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(map[int]core.Annotation{}, nil)
+	mockWords.On("NormBatch", mock.Anything, mock.Anything).Return(map[int][]string{5: {"w"}}, nil).Once()
+	mockDB.On("Add", mock.Anything, core.Comics{ID: 5, Words: []string{"w"}, ContentHash: core.ContentHash(core.XKCDInfo{})}).Return(nil).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeFailed})
+	assert.NoError(t, err)
+
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobDone, progress.State)
+	assert.Equal(t, 1, progress.Fetched)
+
+	mockDB.AssertCalled(t, "SaveRun", mock.Anything, mock.MatchedBy(func(run core.UpdateRun) bool {
+		return run.ID == id && run.State == core.JobDone && run.Mode == core.ModeFailed &&
+			run.Trigger == core.TriggerManual && run.Fetched == 1 && !run.FinishedAt.IsZero()
+	}))
+	mockXKCD.AssertNotCalled(t, "LastID", mock.Anything)
+	mockDB.AssertExpectations(t)
+	mockXKCD.AssertExpectations(t)
+}
+
+func TestUpdate_BadMode(t *testing.T) {
+	service, err := core.NewService(log, nil, nil, nil, nil, nil, 1)
+	assert.NoError(t, err)
+
+	_, err = service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: "all"})
+	assert.ErrorIs(t, err, core.ErrBadArguments)
+	assert.Equal(t, core.StatusIdle, service.Status(context.Background()))
+}
+
+func TestRuns(t *testing.T) {
+	mockDB := new(MockDB)
+	service, err := core.NewService(log, mockDB, nil, nil, nil, nil, 1)
+	assert.NoError(t, err)
+
+	runs := []core.UpdateRun{{ID: "j2", State: core.JobDone}, {ID: "j1", State: core.JobFailed}}
+	failed := []core.FailedComic{{ID: 7, RunID: "j1", Error: "timeout", Attempts: 2}}
+	mockDB.On("Runs", mock.Anything, 10).Return(runs, nil).Once()
+	mockDB.On("Failed", mock.Anything).Return(failed, nil).Once()
+
+	gotRuns, gotFailed, err := service.Runs(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, runs, gotRuns)
+	assert.Equal(t, failed, gotFailed)
+
+	_, _, err = service.Runs(context.Background(), 0)
+	assert.ErrorIs(t, err, core.ErrBadArguments)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdate_Refresh(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	same := core.XKCDInfo{ID: 1, Title: "same"}
+	changed := core.XKCDInfo{ID: 2, Title: "fixed", Transcript: "new"}
+	filter := core.RefreshFilter{FromID: 1, ToID: 2}
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockDB.On("Hashes", mock.Anything, filter).Return(map[int]string{
+		1: core.ContentHash(same),
+		2: core.ContentHash(core.XKCDInfo{ID: 2, Title: "typo"}),
+	}, nil).Once()
+	mockXKCD.On("Get", mock.Anything, 1).Return(same, nil).Once()
+	mockXKCD.On("Get", mock.Anything, 2).Return(changed, nil).Once()
+	mockDB.On("Touch", mock.Anything, 1).Return(nil).Once()
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(map[int]core.Annotation{}, nil)
+	mockWords.On("NormBatch", mock.Anything, map[int]string{2: " fixed new"}).
+		Return(map[int][]string{2: {"fix", "new"}}, nil).Once()
+	mockDB.On("Add", mock.Anything, core.Comics{
+		ID: 2, Title: "fixed", Transcript: "new", Words: []string{"fix", "new"}, ContentHash: core.ContentHash(changed),
+		Coverage: core.TextCoverage{TitleTokens: 1, TranscriptTokens: 1},
+	}).Return(nil).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual,
+		core.UpdateOptions{Mode: core.ModeRefresh, Refresh: filter})
+	assert.NoError(t, err)
+
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobDone, progress.State)
+	assert.Equal(t, 2, progress.Total)
+	assert.Equal(t, 2, progress.Fetched)
+	assert.Equal(t, 0, progress.InFlight)
+
+	mockDB.AssertExpectations(t)
+	mockWords.AssertExpectations(t)
+}
+
+func TestUpdate_RefreshBadFilter(t *testing.T) {
+	service, err := core.NewService(log, nil, nil, nil, nil, nil, 1)
+	assert.NoError(t, err)
+
+	for _, filter := range []core.RefreshFilter{
+		{},
+		{FromID: 10, ToID: 5},
+		{OlderThan: -time.Hour},
+	} {
+		_, err = service.Update(context.Background(), core.TriggerManual,
+			core.UpdateOptions{Mode: core.ModeRefresh, Refresh: filter})
+		assert.ErrorIs(t, err, core.ErrBadArguments, "filter %+v", filter)
+	}
+}
+
+func TestReindex(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockDB.On("Count", mock.Anything).Return(2, nil).Once()
+	mockDB.On("Scan", mock.Anything).Return([]core.Comics{
+		{ID: 1, Title: "running", Alt: "a", Transcript: "t", Words: []string{"old"}},
+		{ID: 2, Title: "jumps"},
+	}, nil).Once()
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(map[int]core.Annotation{}, nil)
+	mockWords.On("NormBatch", mock.Anything, map[int]string{1: "a running t", 2: " jumps "}).
+		Return(map[int][]string{1: {"run"}, 2: {"jump"}}, nil).Once()
+	mockDB.On("SetWords", mock.Anything, 1, []string{"run"}).Return(nil).Once()
+	mockDB.On("SetWords", mock.Anything, 2, []string{"jump"}).Return(nil).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Reindex(context.Background(), core.TriggerManual)
+	assert.NoError(t, err)
+
+	progress := waitJob(t, service, id)
+	assert.Equal(t, core.JobDone, progress.State)
+	assert.Equal(t, 2, progress.Total)
+	assert.Equal(t, 2, progress.Fetched)
+	assert.Equal(t, 0, progress.InFlight)
+
+	mockXKCD.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	mockDB.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+	mockDB.AssertExpectations(t)
+	mockWords.AssertExpectations(t)
+	mockBus.AssertExpectations(t)
+}
+
+func TestDeleteAndHideComic(t *testing.T) {
+	mockDB := new(MockDB)
+	mockBus := new(MockEventBus)
+	service, err := core.NewService(log, mockDB, nil, nil, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("Delete", mock.Anything, 3).Return(nil).Once()
+	mockDB.On("Delete", mock.Anything, 4).Return(core.ErrNotFound).Once()
+	mockDB.On("SetHidden", mock.Anything, 5, true).Return(nil).Once()
+	mockBus.On("PublishComic", 3).Return(nil).Once()
+	mockBus.On("PublishComic", 5).Return(nil).Once()
+
+	assert.NoError(t, service.DeleteComic(context.Background(), 3))
+	assert.ErrorIs(t, service.DeleteComic(context.Background(), 4), core.ErrNotFound)
+	assert.NoError(t, service.HideComic(context.Background(), 5, true))
+
+	mockDB.AssertExpectations(t)
+	mockBus.AssertExpectations(t)
+	mockBus.AssertNotCalled(t, "PublishComic", 4)
+}
+
+func TestRefetchComic(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	info := core.XKCDInfo{ID: 7, Title: "new"}
+	mockXKCD.On("Get", mock.Anything, 7).Return(info, nil).Once()
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(map[int]core.Annotation{}, nil)
+	mockWords.On("NormBatch", mock.Anything, map[int]string{7: " new "}).Return(map[int][]string{7: {"new"}}, nil).Once()
+	mockDB.On("Add", mock.Anything, core.Comics{
+		ID: 7, Title: "new", Words: []string{"new"}, ContentHash: core.ContentHash(info),
+		Coverage: core.TextCoverage{TitleTokens: 1},
+	}).Return(nil).Once()
+	mockBus.On("PublishComic", 7).Return(nil).Once()
+
+	assert.NoError(t, service.RefetchComic(context.Background(), 7))
+	assert.ErrorIs(t, service.RefetchComic(context.Background(), 0), core.ErrBadArguments)
+
+	mockXKCD.AssertExpectations(t)
+	mockDB.AssertExpectations(t)
+	mockBus.AssertExpectations(t)
+}
+
+func TestRefetchComic_Image(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+	mockImages := new(MockImageStore)
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, mockImages, 1)
+	assert.NoError(t, err)
+
+	src := image.NewGray(image.Rect(0, 0, 2, 3))
+	src.Pix[1] = 255
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, src))
+	data := buf.Bytes()
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	info := core.XKCDInfo{ID: 7, URL: "https://imgs.xkcd.com/comics/7.png"}
+	mockXKCD.On("Get", mock.Anything, 7).Return(info, nil).Once()
+	mockXKCD.On("Image", mock.Anything, info.URL).Return(data, nil).Once()
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(map[int]core.Annotation{}, nil)
+	mockWords.On("NormBatch", mock.Anything, mock.Anything).Return(map[int][]string{}, nil).Once()
+	mockImages.On("Put", mock.Anything, hash, data).Return(nil).Once()
+	img := core.ComicImage{Hash: hash, Width: 2, Height: 3, PHash: imagehash.DHash(src), HasPHash: true}
+	mockDB.On("Add", mock.Anything, core.Comics{
+		ID: 7, URL: info.URL, Words: []string{}, ContentHash: core.ContentHash(info), Image: img,
+	}).Return(nil).Once()
+	mockBus.On("PublishComic", 7).Return(nil).Once()
+
+	assert.NoError(t, service.RefetchComic(context.Background(), 7))
+
+	mockDB.On("Image", mock.Anything, 7).Return(img, nil).Once()
+	mockImages.On("Get", mock.Anything, hash).Return(data, nil).Once()
+	gotImg, gotData, err := service.Image(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, img, gotImg)
+	assert.Equal(t, data, gotData)
+
+	mockDB.On("Image", mock.Anything, 8).Return(core.ComicImage{}, core.ErrNotFound).Once()
+	_, _, err = service.Image(context.Background(), 8)
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	mockXKCD.AssertExpectations(t)
+	mockDB.AssertExpectations(t)
+	mockImages.AssertExpectations(t)
+}
+
+func TestImage_NoStore(t *testing.T) {
+	service, err := core.NewService(log, nil, nil, nil, nil, nil, 1)
+	assert.NoError(t, err)
+
+	_, _, err = service.Image(context.Background(), 1)
+	assert.ErrorIs(t, err, core.ErrNotFound)
+}
+
+func TestCoverage(t *testing.T) {
+	mockDB := new(MockDB)
+	service, err := core.NewService(log, mockDB, nil, nil, nil, nil, 1)
+	assert.NoError(t, err)
+
+	poor := []core.ComicCoverage{{ID: 3, Title: "t", TextCoverage: core.TextCoverage{TitleTokens: 1}}}
+	mockDB.On("Coverage", mock.Anything, 10).Return(poor, 5, nil).Once()
+
+	comics, total, err := service.Coverage(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, poor, comics)
+	assert.Equal(t, 5, total)
+
+	_, _, err = service.Coverage(context.Background(), 0)
+	assert.ErrorIs(t, err, core.ErrBadArguments)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdate_Annotated(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(1, nil).Once()
+	mockDB.On("IDs", mock.Anything).Return([]int{}, nil).Once()
+	info := core.XKCDInfo{ID: 1, Title: "t"}
+	mockXKCD.On("Get", mock.Anything, 1).Return(info, nil).Once()
+	mockDB.On("Annotations", mock.Anything, []int{1}).Return(map[int]core.Annotation{
+		1: {ID: 1, Transcript: "man says hi", Keywords: []string{"greeting", "wave"}},
+	}, nil).Once()
+	mockWords.On("NormBatch", mock.Anything, map[int]string{1: " t  man says hi greeting wave"}).
+		Return(map[int][]string{1: {"t", "man", "say", "hi", "greet", "wave"}}, nil).Once()
+	mockDB.On("Add", mock.Anything, core.Comics{
+		ID: 1, Title: "t", Words: []string{"t", "man", "say", "hi", "greet", "wave"},
+		ContentHash: core.ContentHash(info), Coverage: core.TextCoverage{TitleTokens: 1},
+	}).Return(nil).Once()
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+	progress := waitJob(t, service, id)
+	assert.Equal(t, 1, progress.Fetched)
+
+	mockDB.AssertExpectations(t)
+	mockWords.AssertExpectations(t)
+}
+
+func TestUpdate_AnnotationsError(t *testing.T) {
+	mockDB := new(MockDB)
+	mockXKCD := new(MockXKCD)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+
+	service, err := core.NewService(log, mockDB, mockXKCD, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockXKCD.On("LastID", mock.Anything).Return(2, nil).Once()
+	mockDB.On("IDs", mock.Anything).Return([]int{}, nil).Once()
+	for id := 1; id <= 2; id++ {
+		mockXKCD.On("Get", mock.Anything, id).Return(core.XKCDInfo{ID: id, Title: "t"}, nil).Once()
+	}
+	mockDB.On("Annotations", mock.Anything, mock.Anything).Return(nil, errors.New("db is down"))
+	// comics are left to retry-failed rather than saved without words
+	for id := 1; id <= 2; id++ {
+		mockDB.On("AddFailed", mock.Anything, mock.MatchedBy(func(f core.FailedComic) bool {
+			return f.ID == id
+		})).Return(nil).Once()
+	}
+	mockBus.On("PublishUpdate").Return(nil).Once()
+
+	id, err := service.Update(context.Background(), core.TriggerManual, core.UpdateOptions{Mode: core.ModeNew})
+	assert.NoError(t, err)
+	progress := waitJob(t, service, id)
+	assert.Equal(t, 0, progress.Fetched)
+	assert.Equal(t, 2, progress.Failed)
+	assert.Equal(t, 0, progress.InFlight)
+
+	mockDB.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+	mockWords.AssertNotCalled(t, "NormBatch", mock.Anything, mock.Anything)
+	mockDB.AssertExpectations(t)
+}
+
+func TestAnnotate(t *testing.T) {
+	mockDB := new(MockDB)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+	service, err := core.NewService(log, mockDB, nil, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	note := core.Annotation{ID: 5, Transcript: "cat", Keywords: []string{"pet"}}
+	mockDB.On("SetAnnotation", mock.Anything, note).Return(nil).Once()
+	mockDB.On("Comic", mock.Anything, 5).Return(core.Comics{ID: 5, Title: "t"}, nil).Once()
+	mockDB.On("Annotations", mock.Anything, []int{5}).Return(map[int]core.Annotation{5: note}, nil).Once()
+	mockWords.On("NormBatch", mock.Anything, map[int]string{5: " t  cat pet"}).
+		Return(map[int][]string{5: {"t", "cat", "pet"}}, nil).Once()
+	mockDB.On("SetWords", mock.Anything, 5, []string{"t", "cat", "pet"}).Return(nil).Once()
+	mockBus.On("PublishComic", 5).Return(nil).Once()
+
+	// keywords are trimmed, blank ones dropped
+	assert.NoError(t, service.Annotate(context.Background(), core.Annotation{
+		ID: 5, Transcript: " cat ", Keywords: []string{" pet", ""},
+	}))
+
+	// not fetched yet: only saved
+	mockDB.On("SetAnnotation", mock.Anything, core.Annotation{ID: 6, Keywords: []string{"x"}}).Return(nil).Once()
+	mockDB.On("Comic", mock.Anything, 6).Return(core.Comics{}, core.ErrNotFound).Once()
+	assert.NoError(t, service.Annotate(context.Background(), core.Annotation{ID: 6, Keywords: []string{"x"}}))
+
+	assert.ErrorIs(t, service.Annotate(context.Background(), core.Annotation{ID: 0, Transcript: "x"}), core.ErrBadArguments)
+	assert.ErrorIs(t, service.Annotate(context.Background(), core.Annotation{ID: 7, Keywords: []string{" "}}), core.ErrBadArguments)
+
+	mockDB.AssertExpectations(t)
+	mockWords.AssertExpectations(t)
+	mockBus.AssertExpectations(t)
+}
+
+func TestDeleteAnnotation(t *testing.T) {
+	mockDB := new(MockDB)
+	mockWords := new(MockWords)
+	mockBus := new(MockEventBus)
+	service, err := core.NewService(log, mockDB, nil, mockWords, mockBus, nil, 1)
+	assert.NoError(t, err)
+
+	mockDB.On("DeleteAnnotation", mock.Anything, 5).Return(nil).Once()
+	mockDB.On("DeleteAnnotation", mock.Anything, 6).Return(core.ErrNotFound).Once()
+	mockDB.On("Comic", mock.Anything, 5).Return(core.Comics{ID: 5, Title: "t"}, nil).Once()
+	mockDB.On("Annotations", mock.Anything, []int{5}).Return(map[int]core.Annotation{}, nil).Once()
+	mockWords.On("NormBatch", mock.Anything, map[int]string{5: " t "}).Return(map[int][]string{5: {"t"}}, nil).Once()
+	mockDB.On("SetWords", mock.Anything, 5, []string{"t"}).Return(nil).Once()
+	mockBus.On("PublishComic", 5).Return(nil).Once()
+
+	assert.NoError(t, service.DeleteAnnotation(context.Background(), 5))
+	assert.ErrorIs(t, service.DeleteAnnotation(context.Background(), 6), core.ErrNotFound)
+
+	mockDB.AssertExpectations(t)
+	mockBus.AssertExpectations(t)
+	mockBus.AssertNotCalled(t, "PublishComic", 6)
+}
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -15,6 +16,8 @@ import (
 	"yadro.com/course/update/adapters/db"
 	"yadro.com/course/update/adapters/eventbus"
 	updategrpc "yadro.com/course/update/adapters/grpc"
+	"yadro.com/course/update/adapters/images"
+	"yadro.com/course/update/adapters/scheduler"
 	"yadro.com/course/update/adapters/words"
 	"yadro.com/course/update/adapters/xkcd"
 	"yadro.com/course/update/config"
@@ -30,6 +33,18 @@ func main() {
 
 	log := mustMakeLogger(cfg.LogLevel)
 
+	if args := flag.Args(); len(args) > 0 && args[0] == "export" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: update [-config file] export <dir|archive.tar.gz>")
+			os.Exit(2)
+		}
+		if err := export(cfg, log, args[1]); err != nil {
+			log.Error("export failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(cfg, log); err != nil {
 		log.Error("server failed", "error", err)
 		os.Exit(1)
@@ -48,12 +63,12 @@ func run(cfg config.Config, log *slog.Logger) error {
 		return fmt.Errorf("failed to migrate db: %v", err)
 	}
 
-	xkcd, err := xkcd.NewClient(cfg.XKCD.URL, cfg.XKCD.Timeout, log)
+	xkcd, err := newXKCD(cfg.XKCD, log)
 	if err != nil {
 		return fmt.Errorf("failed create XKCD client: %v", err)
 	}
 
-	words, err := words.NewClient(cfg.WordsAddress, log)
+	words, err := words.NewClient(cfg.WordsAddress, cfg.WordsShingles, log)
 	if err != nil {
 		return fmt.Errorf("failed create Words client: %v", err)
 	}
@@ -64,7 +79,14 @@ func run(cfg config.Config, log *slog.Logger) error {
 	}
 	defer eb.Close()
 
-	updater, err := core.NewService(log, storage, xkcd, words, eb, cfg.XKCD.Concurrency)
+	var store core.ImageStore
+	if cfg.ImagesDir != "" {
+		if store, err = images.NewStore(cfg.ImagesDir); err != nil {
+			return fmt.Errorf("failed create image store: %v", err)
+		}
+	}
+
+	updater, err := core.NewService(log, storage, xkcd, words, eb, store, cfg.XKCD.Concurrency)
 	if err != nil {
 		return fmt.Errorf("failed create Update service: %v", err)
 	}
@@ -74,13 +96,18 @@ func run(cfg config.Config, log *slog.Logger) error {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
-	updatepb.RegisterUpdateServer(s, updategrpc.NewServer(updater))
+	sched := scheduler.NewScheduler(log, updater, cfg.XKCD.CheckPeriod, cfg.XKCD.CheckJitter)
+
+	// comic images are sent in a single message
+	s := grpc.NewServer(grpc.MaxSendMsgSize(20 << 20))
+	updatepb.RegisterUpdateServer(s, updategrpc.NewServer(updater, sched))
 	reflection.Register(s)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	sched.Start(ctx)
+
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
@@ -93,6 +120,61 @@ func run(cfg config.Config, log *slog.Logger) error {
 	return nil
 }
 
+// newXKCD reads comics from a local mirror for file:// URLs
+// and from the XKCD site otherwise.
+func newXKCD(cfg config.XKCD, log *slog.Logger) (core.XKCD, error) {
+	if root, ok := strings.CutPrefix(cfg.URL, "file://"); ok {
+		return xkcd.NewMirror(root, log)
+	}
+	return xkcd.NewClient(cfg.URL, cfg.Timeout, xkcd.Policy{
+		Retries:          cfg.Retries,
+		MinBackoff:       cfg.MinBackoff,
+		MaxBackoff:       cfg.MaxBackoff,
+		RPS:              cfg.RPS,
+		BreakerThreshold: cfg.BreakerThreshold,
+		BreakerCooldown:  cfg.BreakerCooldown,
+	}, log)
+}
+
+// export dumps the comics table into a mirror readable via file:// URL.
+func export(cfg config.Config, log *slog.Logger, path string) error {
+	storage, err := db.New(log, cfg.DBAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to db: %v", err)
+	}
+
+	w, err := xkcd.NewMirrorWriter(path)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	count := 0
+	err = storage.Scan(ctx, func(c core.Comics) error {
+		count++
+		return w.Put(core.XKCDInfo{
+			ID:         c.ID,
+			URL:        c.URL,
+			Title:      c.Title,
+			Alt:        c.Alt,
+			Transcript: c.Transcript,
+			SafeTitle:  c.SafeTitle,
+		})
+	})
+	if err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to export comics: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write mirror: %v", err)
+	}
+
+	log.Info("exported comics", "count", count, "path", path)
+	return nil
+}
+
 func mustMakeLogger(logLevel string) *slog.Logger {
 	var level slog.Level
 	switch logLevel {
@@ -8,7 +8,9 @@ import (
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	wordspb "yadro.com/course/proto/words"
 	"yadro.com/course/words/words"
@@ -16,6 +18,8 @@ import (
 
 type server struct {
 	wordspb.UnimplementedWordsServer
+	configPath string
+	normalizer *words.Normalizer
 }
 
 func (s *server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
@@ -23,11 +27,41 @@ func (s *server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, erro
 }
 
 func (s *server) Norm(ctx context.Context, in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
-	return words.Norm(ctx, in)
+	return s.normalizer.Norm(ctx, in)
+}
+
+func (s *server) NormBatch(ctx context.Context, in *wordspb.NormBatchRequest) (*wordspb.NormBatchReply, error) {
+	return s.normalizer.NormBatch(ctx, in)
+}
+
+func (s *server) NormStream(stream wordspb.Words_NormStreamServer) error {
+	return s.normalizer.NormStream(stream)
+}
+
+func (s *server) Analyze(ctx context.Context, in *wordspb.WordsRequest) (*wordspb.AnalyzeReply, error) {
+	return s.normalizer.Analyze(ctx, in)
+}
+
+func (s *server) Reload(_ context.Context, _ *emptypb.Empty) (*wordspb.ReloadReply, error) {
+	var cfg Config
+	if err := cleanenv.ReadConfig(s.configPath, &cfg); err != nil {
+		log.Printf("failed to reload config %q: %v", s.configPath, err)
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot read config: %v", err)
+	}
+	s.normalizer.Reload(cfg.StopWords, cfg.ProtectedWords)
+
+	stopWords, protected := s.normalizer.Sizes()
+	log.Printf("dictionaries reloaded: %d stop-words, %d protected words", stopWords, protected)
+	return &wordspb.ReloadReply{
+		StopWords:      int64(stopWords),
+		ProtectedWords: int64(protected),
+	}, nil
 }
 
 type Config struct {
-	Address string `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"80"`
+	Address        string   `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"80"`
+	StopWords      []string `yaml:"stop_words" env:"WORDS_STOP_WORDS"`
+	ProtectedWords []string `yaml:"protected_words" env:"WORDS_PROTECTED_WORDS"`
 }
 
 func main() {
@@ -46,7 +80,10 @@ func main() {
 	}
 
 	s := grpc.NewServer()
-	wordspb.RegisterWordsServer(s, &server{})
+	wordspb.RegisterWordsServer(s, &server{
+		configPath: configPath,
+		normalizer: words.NewNormalizer(cfg.StopWords, cfg.ProtectedWords),
+	})
 	reflection.Register(s)
 
 	if err := s.Serve(listener); err != nil {
@@ -2,8 +2,11 @@ package words
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log"
 	"strings"
+	"sync"
 
 	"github.com/kljensen/snowball"
 	"github.com/kljensen/snowball/english"
@@ -13,43 +16,334 @@ import (
 	wordspb "yadro.com/course/proto/words"
 )
 
-func Norm(_ context.Context, in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
-	if len(in.Phrase) > 4096 {
-		return nil, status.Errorf(codes.ResourceExhausted, "Phrase lenght > 4 KiB")
+// Normalizer turns phrases into stems. On top of the built-in english
+// stop-words it drops configured domain stop-words and keeps protected
+// words as is, without stemming.
+type Normalizer struct {
+	mu        sync.RWMutex
+	stopWords map[string]struct{}
+	protected map[string]struct{}
+}
+
+func NewNormalizer(stopWords, protected []string) *Normalizer {
+	n := &Normalizer{}
+	n.Reload(stopWords, protected)
+	return n
+}
+
+// Reload replaces the custom stop-word and protected word lists.
+func (n *Normalizer) Reload(stopWords, protected []string) {
+	stop := toSet(stopWords)
+	prot := toSet(protected)
+
+	n.mu.Lock()
+	n.stopWords = stop
+	n.protected = prot
+	n.mu.Unlock()
+}
+
+// Sizes returns the number of custom stop-words and protected words.
+func (n *Normalizer) Sizes() (stopWords, protected int) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return len(n.stopWords), len(n.protected)
+}
+
+func (n *Normalizer) Norm(_ context.Context, in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
+	if err := checkPhrase(in.Phrase); err != nil {
+		return nil, err
 	}
 
-	lowerPhrase := strings.ToLower(in.Phrase)
-	phraseSlice := strings.FieldsFunc(lowerPhrase, func(r rune) bool {
-		isAlphanumeric := (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
-		return !isAlphanumeric
+	stems := n.newStemSet(in.Shingles)
+	stems.add(in.Phrase)
+
+	return &wordspb.WordsReply{
+		Words: stems.words(),
+	}, nil
+}
+
+// NormBatch normalizes every document separately and keeps their IDs.
+func (n *Normalizer) NormBatch(_ context.Context, in *wordspb.NormBatchRequest) (*wordspb.NormBatchReply, error) {
+	result := make([]*wordspb.DocumentWords, 0, len(in.Documents))
+	for _, doc := range in.Documents {
+		if err := checkPhrase(doc.Phrase); err != nil {
+			return nil, err
+		}
+
+		stems := n.newStemSet(in.Shingles)
+		stems.add(doc.Phrase)
+		result = append(result, &wordspb.DocumentWords{
+			Id:    doc.Id,
+			Words: stems.words(),
+		})
+	}
+
+	return &wordspb.NormBatchReply{
+		Documents: result,
+	}, nil
+}
+
+// ChunkStream is the receiving side of a client stream of phrase chunks.
+type ChunkStream interface {
+	Recv() (*wordspb.WordsRequest, error)
+	SendAndClose(*wordspb.WordsReply) error
+}
+
+// NormStream normalizes text of any length sent in chunks of up to 4 KiB.
+// A word cut between two chunks is glued back together. Shingles are
+// enabled by the first chunk.
+func (n *Normalizer) NormStream(stream ChunkStream) error {
+	var stems *stemSet
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkPhrase(in.Phrase); err != nil {
+			return err
+		}
+		if stems == nil {
+			stems = n.newStemSet(in.Shingles)
+		}
+		stems.add(in.Phrase)
+	}
+
+	if stems == nil {
+		stems = n.newStemSet(false)
+	}
+	return stream.SendAndClose(&wordspb.WordsReply{
+		Words: stems.words(),
 	})
+}
 
-	uniqueStems := make(map[string]struct{})
+// Analyze returns every token of the phrase in order of appearance,
+// stop-words included, so callers can highlight or rank by position.
+func (n *Normalizer) Analyze(_ context.Context, in *wordspb.WordsRequest) (*wordspb.AnalyzeReply, error) {
+	if err := checkPhrase(in.Phrase); err != nil {
+		return nil, err
+	}
 
-	for _, word := range phraseSlice {
-		if word == "" {
-			continue
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	tokens := tokenize(in.Phrase)
+	result := make([]*wordspb.Token, 0, len(tokens))
+	for i, tok := range tokens {
+		stem, stop, err := n.stem(tok.text)
+		if err != nil {
+			log.Printf("Error stemming word '%s': %v", tok.text, err)
+			stem = tok.text
 		}
+		result = append(result, &wordspb.Token{
+			Original: in.Phrase[tok.start:tok.end],
+			Stem:     stem,
+			Position: int32(i),
+			Start:    int32(tok.start),
+			End:      int32(tok.end),
+			StopWord: stop,
+		})
+	}
 
-		if english.IsStopWord(word) {
+	return &wordspb.AnalyzeReply{
+		Tokens: result,
+	}, nil
+}
+
+// stem returns the stem of a lower-cased word and whether it is a stop-word.
+// Protected words win over any stop-word list, so "it" or "go" can be kept.
+// Must be called with n.mu held.
+func (n *Normalizer) stem(word string) (string, bool, error) {
+	if _, ok := n.protected[word]; ok {
+		return word, false, nil
+	}
+
+	stemmedWord, err := snowball.Stem(word, "english", false)
+	if err != nil {
+		return "", false, err
+	}
+	return stemmedWord, n.isStopWord(word), nil
+}
+
+func (n *Normalizer) isStopWord(word string) bool {
+	if _, ok := n.stopWords[word]; ok {
+		return true
+	}
+	return english.IsStopWord(word)
+}
+
+// Norm normalizes a phrase using the built-in english stop-words only.
+func Norm(ctx context.Context, in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
+	return NewNormalizer(nil, nil).Norm(ctx, in)
+}
+
+func checkPhrase(phrase string) error {
+	if len(phrase) > 4096 {
+		return status.Errorf(codes.ResourceExhausted, "Phrase lenght > 4 KiB")
+	}
+	return nil
+}
+
+// stemSet collects unique stems of the text fed to it chunk by chunk.
+//
+// Words joined by a single hyphen, like "e-mail" or "machine-learning",
+// also yield their compound stem ("email", "machinelearn"). With shingles
+// enabled every two adjacent non stop-words yield a bigram of their stems
+// joined by ShingleSep ("machin_learn"). At most maxVariants compound
+// stems and shingles are added per text.
+type stemSet struct {
+	n        *Normalizer
+	shingles bool
+	stems    map[string]struct{}
+	variants int
+	tail     string   // last word of the last chunk with separators around it
+	prev     string   // stem of the previous word, empty after a stop-word
+	compound []string // words of the current hyphenated compound
+}
+
+// ShingleSep joins stems of a shingle. It never occurs in a plain stem.
+const ShingleSep = "_"
+
+// maxVariants bounds compound stems and shingles of a text, so a long
+// text does not grow the set well beyond its words.
+const maxVariants = 256
+
+func (n *Normalizer) newStemSet(shingles bool) *stemSet {
+	return &stemSet{n: n, shingles: shingles, stems: make(map[string]struct{})}
+}
+
+func (s *stemSet) add(chunk string) {
+	text := s.tail + chunk
+	tokens := tokenize(text)
+
+	// the last word may go on in the next chunk, so it is held back
+	// together with the separators around it
+	s.tail = shortSep(text)
+	if len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		from := 0
+		if len(tokens) > 1 {
+			from = tokens[len(tokens)-2].end
+		}
+		s.tail = shortSep(text[from:last.start]) + text[last.start:last.end] + shortSep(text[last.end:])
+		text = text[:from]
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	s.n.mu.RLock()
+	defer s.n.mu.RUnlock()
+	s.collect(text, tokens)
+}
+
+func (s *stemSet) words() []string {
+	s.n.mu.RLock()
+	s.collect(s.tail, tokenize(s.tail))
+	s.flushCompound()
+	s.n.mu.RUnlock()
+	s.tail = ""
+
+	result := make([]string, 0, len(s.stems))
+	for stem := range s.stems {
+		result = append(result, stem)
+	}
+	return result
+}
+
+// collect must be called with s.n.mu held.
+func (s *stemSet) collect(text string, tokens []token) {
+	prevEnd := 0
+	for _, tok := range tokens {
+		if text[prevEnd:tok.start] != "-" || len(s.compound) == 0 {
+			s.flushCompound()
+		}
+		s.compound = append(s.compound, tok.text)
+		prevEnd = tok.end
+
+		stem, stop, err := s.n.stem(tok.text)
+		if err != nil {
+			log.Printf("Error stemming word '%s': %v", tok.text, err)
+			s.prev = ""
 			continue
 		}
+		if stop {
+			s.prev = ""
+			continue
+		}
+
+		s.stems[stem] = struct{}{}
+		if s.shingles && s.prev != "" {
+			s.addVariant(s.prev + ShingleSep + stem)
+		}
+		s.prev = stem
+	}
+}
 
-		stemmedWord, err := snowball.Stem(word, "english", false)
+// flushCompound must be called with s.n.mu held.
+func (s *stemSet) flushCompound() {
+	if len(s.compound) > 1 && s.variants < maxVariants {
+		word := strings.Join(s.compound, "")
+		stem, _, err := s.n.stem(word)
 		if err != nil {
 			log.Printf("Error stemming word '%s': %v", word, err)
-			continue
+		} else {
+			s.addVariant(stem)
 		}
+	}
+	s.compound = s.compound[:0]
+}
 
-		uniqueStems[stemmedWord] = struct{}{}
+func (s *stemSet) addVariant(stem string) {
+	if _, ok := s.stems[stem]; ok || s.variants >= maxVariants {
+		return
 	}
+	s.stems[stem] = struct{}{}
+	s.variants++
+}
 
-	result := make([]string, 0, len(uniqueStems))
-	for stem := range uniqueStems {
-		result = append(result, stem)
+// shortSep keeps a hyphen separator as is and shrinks any other to a space.
+func shortSep(sep string) string {
+	if sep == "" || sep == "-" {
+		return sep
+	}
+	return " "
+}
+
+type token struct {
+	text       string // lower-cased
+	start, end int    // byte offsets in the original phrase
+}
+
+// tokenize splits a phrase into lower-cased runs of latin letters and digits.
+func tokenize(phrase string) []token {
+	var tokens []token
+	start := -1
+	for i := 0; i <= len(phrase); i++ {
+		isAlphanumeric := false
+		if i < len(phrase) {
+			c := phrase[i]
+			isAlphanumeric = (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		}
+		switch {
+		case isAlphanumeric && start < 0:
+			start = i
+		case !isAlphanumeric && start >= 0:
+			tokens = append(tokens, token{text: strings.ToLower(phrase[start:i]), start: start, end: i})
+			start = -1
+		}
 	}
+	return tokens
+}
 
-	return &wordspb.WordsReply{
-		Words: result,
-	}, nil
+func toSet(words []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w == "" {
+			continue
+		}
+		set[w] = struct{}{}
+	}
+	return set
 }
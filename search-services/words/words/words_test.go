@@ -2,6 +2,9 @@ package words
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -72,3 +75,138 @@ func TestNorm(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizerDictionaries(t *testing.T) {
+	n := NewNormalizer([]string{"xkcd", " Comic "}, []string{"linux", "it"})
+
+	resp, err := n.Norm(context.Background(), &wordspb.WordsRequest{Phrase: "xkcd comic about linux running it"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"linux", "run", "it"}, resp.Words)
+
+	n.Reload([]string{"linux"}, nil)
+	stopWords, protected := n.Sizes()
+	assert.Equal(t, 1, stopWords)
+	assert.Equal(t, 0, protected)
+
+	resp, err = n.Norm(context.Background(), &wordspb.WordsRequest{Phrase: "xkcd comic about linux running it"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"xkcd", "comic", "run"}, resp.Words)
+}
+
+func TestAnalyze(t *testing.T) {
+	n := NewNormalizer(nil, []string{"sql"})
+
+	resp, err := n.Analyze(context.Background(), &wordspb.WordsRequest{Phrase: "Running the SQL-server!"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*wordspb.Token{
+		{Original: "Running", Stem: "run", Position: 0, Start: 0, End: 7},
+		{Original: "the", Stem: "the", Position: 1, Start: 8, End: 11, StopWord: true},
+		{Original: "SQL", Stem: "sql", Position: 2, Start: 12, End: 15},
+		{Original: "server", Stem: "server", Position: 3, Start: 16, End: 22},
+	}, resp.Tokens)
+
+	_, err = n.Analyze(context.Background(), &wordspb.WordsRequest{Phrase: string(make([]byte, 5000))})
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+}
+
+type chunkStream struct {
+	chunks []string
+	reply  *wordspb.WordsReply
+}
+
+func (s *chunkStream) Recv() (*wordspb.WordsRequest, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return &wordspb.WordsRequest{Phrase: chunk}, nil
+}
+
+func (s *chunkStream) SendAndClose(reply *wordspb.WordsReply) error {
+	s.reply = reply
+	return nil
+}
+
+func TestNormStream(t *testing.T) {
+	n := NewNormalizer(nil, nil)
+
+	stream := &chunkStream{chunks: []string{"apple tr", "ee runn", "ing", " hello"}}
+	assert.NoError(t, n.NormStream(stream))
+	assert.ElementsMatch(t, []string{"appl", "tree", "run", "hello"}, stream.reply.Words)
+
+	long := strings.Repeat("apple ", 1000) + "tree"
+	var chunks []string
+	for len(long) > 4096 {
+		chunks = append(chunks, long[:4096])
+		long = long[4096:]
+	}
+	stream = &chunkStream{chunks: append(chunks, long)}
+	assert.NoError(t, n.NormStream(stream))
+	assert.ElementsMatch(t, []string{"appl", "tree"}, stream.reply.Words)
+
+	stream = &chunkStream{chunks: []string{string(make([]byte, 5000))}}
+	st, ok := status.FromError(n.NormStream(stream))
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+}
+
+func TestNormBatch(t *testing.T) {
+	n := NewNormalizer(nil, nil)
+
+	resp, err := n.NormBatch(context.Background(), &wordspb.NormBatchRequest{
+		Documents: []*wordspb.Document{
+			{Id: 1, Phrase: "apple tree"},
+			{Id: 2, Phrase: "the a an"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Documents, 2)
+	assert.Equal(t, int64(1), resp.Documents[0].Id)
+	assert.ElementsMatch(t, []string{"appl", "tree"}, resp.Documents[0].Words)
+	assert.Equal(t, int64(2), resp.Documents[1].Id)
+	assert.Empty(t, resp.Documents[1].Words)
+
+	_, err = n.NormBatch(context.Background(), &wordspb.NormBatchRequest{
+		Documents: []*wordspb.Document{{Id: 3, Phrase: string(make([]byte, 5000))}},
+	})
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+}
+
+func TestNormCompoundsAndShingles(t *testing.T) {
+	n := NewNormalizer(nil, nil)
+
+	resp, err := n.Norm(context.Background(), &wordspb.WordsRequest{Phrase: "e-mail about machine-learning"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"e", "mail", "email", "machin", "learn", "machinelearn"}, resp.Words)
+
+	resp, err = n.Norm(context.Background(), &wordspb.WordsRequest{Phrase: "machine learning is python3 magic", Shingles: true})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"machin", "learn", "machin_learn", "python3", "magic", "python3_magic"}, resp.Words)
+
+	stream := &chunkStream{chunks: []string{"e-", "mail machine ", "learning"}}
+	assert.NoError(t, n.NormStream(stream))
+	assert.ElementsMatch(t, []string{"e", "mail", "email", "machin", "learn"}, stream.reply.Words)
+
+	// distinct words near the phrase limit yield a bounded number of variants
+	var phrase strings.Builder
+	for i := 0; phrase.Len() < 4000; i++ {
+		phrase.WriteString(fmt.Sprintf("w%d-x%d ", i, i))
+	}
+	resp, err = n.Norm(context.Background(), &wordspb.WordsRequest{Phrase: phrase.String(), Shingles: true})
+	assert.NoError(t, err)
+	var words, variants int
+	for _, w := range resp.Words {
+		if strings.Contains(w, ShingleSep) || strings.HasPrefix(w, "w") && strings.Contains(w, "x") {
+			variants++
+		} else {
+			words++
+		}
+	}
+	assert.Equal(t, maxVariants, variants)
+	assert.Greater(t, words, maxVariants)
+}
@@ -12,8 +12,7 @@ import (
 func TestExtra(t *testing.T) {
 	// Ensure DB is ready
 	token := login(t)
-	_, err := update(token)
-	require.NoError(t, err)
+	update(t, token)
 
 	t.Run("search special chars", SearchSpecialChars)
 	t.Run("access with invalid token", AccessWithInvalidToken)
@@ -2,6 +2,7 @@ package api_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 	"sync"
@@ -17,8 +18,7 @@ func TestSearchConcurrency(t *testing.T) {
 	const packSize = 20
 	const concurrency = 10
 	token := login(t)
-	_, err := update(token)
-	require.NoError(t, err, "could not update")
+	update(t, token)
 	var countOK atomic.Int64
 	var countBusy atomic.Int64
 	for range numPacks {
@@ -52,8 +52,7 @@ func TestSearchRateLong(t *testing.T) {
 	const rate = 100
 	const numReq = 1000
 	token := login(t)
-	_, err := update(token)
-	require.NoError(t, err, "could not update")
+	update(t, token)
 	time.Sleep(30 * time.Second)
 	var wg sync.WaitGroup
 	wg.Add(numReq)
@@ -102,9 +101,13 @@ func TestGoodLogin(t *testing.T) {
 	require.NoError(t, err, "could not send login command")
 	defer resp.Body.Close()
 	require.Equal(t, http.StatusOK, resp.StatusCode)
-	token, err := io.ReadAll(resp.Body)
-	require.NoError(t, err)
-	require.True(t, len(token) > 0)
+	var tokens struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+	require.True(t, len(tokens.AccessToken) > 0)
+	require.True(t, len(tokens.RefreshToken) > 0)
 }
 
 func TestLoginExpiredVeryLong(t *testing.T) {
@@ -22,8 +22,7 @@ type ComicsReply struct {
 
 func TestSearch(t *testing.T) {
 	token := login(t)
-	_, err := update(token)
-	require.NoError(t, err, "could not run update")
+	update(t, token)
 	t.Run("no phrase", SearchNoPhrase)
 	t.Run("bad limit minus", SearchBadLimitMinus)
 	t.Run("bad limit alpha", SearchBadLimitAlpha)
@@ -136,8 +135,7 @@ func IndexSearchPhrases(t *testing.T) {
 
 	// update DB and wait 30 sec for index update
 	token := login(t)
-	_, err = update(token)
-	require.NoError(t, err, "could not run update")
+	update(t, token)
 	time.Sleep(30 * time.Second)
 
 	testCases := []struct {
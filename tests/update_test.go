@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sync"
 	"testing"
@@ -34,14 +33,15 @@ func TestUpdate(t *testing.T) {
 	wg.Add(3)
 	var err1, err2, err3 error
 	var res1, res2 int
+	var job1, job2 jobReply
 	var res3 string
 	token := login(t)
 	go func() {
-		res1, err1 = update(token)
+		res1, job1, err1 = startUpdate(token)
 		wg.Done()
 	}()
 	go func() {
-		res2, err2 = update(token)
+		res2, job2, err2 = startUpdate(token)
 		wg.Done()
 	}()
 	go func() {
@@ -53,12 +53,12 @@ func TestUpdate(t *testing.T) {
 	require.NoError(t, err1, "error from update")
 	require.NoError(t, err2, "error from update")
 	require.NoError(t, err3, "erorr from status")
-	require.True(t,
-		res1 == http.StatusOK && res2 == http.StatusAccepted ||
-			res2 == http.StatusOK && res1 == http.StatusAccepted,
-		"wrong statuses from concurrent updates, expect ok && accepted",
-	)
+	require.Equal(t, http.StatusAccepted, res1, "updates run in background")
+	require.Equal(t, http.StatusAccepted, res2, "updates run in background")
+	require.Equal(t, job1.Job, job2.Job, "concurrent updates share the running job")
+	require.True(t, job1.AlreadyRunning != job2.AlreadyRunning, "only one update starts a job")
 	require.Equal(t, "running", res3, "need running status while update")
+	waitUpdate(t, job1.Job)
 	st := stats(t)
 	require.Equal(t, st.ComicsTotal, st.ComicsFetched)
 	require.True(t, st.ComicsTotal > 3000, "there are more than 3000 comics in XKCD")
@@ -76,9 +76,11 @@ func login(t *testing.T) string {
 	require.NoError(t, err, "could not send login command")
 	defer resp.Body.Close()
 	require.Equal(t, http.StatusOK, resp.StatusCode)
-	token, err := io.ReadAll(resp.Body)
-	require.NoError(t, err)
-	return string(token)
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+	return tokens.AccessToken
 }
 
 func prepare(t *testing.T) {
@@ -100,19 +102,62 @@ func prepare(t *testing.T) {
 	require.Equal(t, "idle", updateStatus, err)
 }
 
+// update runs an update to the end, the API only starts it.
+func update(t *testing.T, token string) {
+	code, job, err := startUpdate(token)
+	require.NoError(t, err, "could not start update")
+	require.Equal(t, http.StatusAccepted, code)
+	waitUpdate(t, job.Job)
+}
+
+type jobReply struct {
+	Job            string `json:"job"`
+	AlreadyRunning bool   `json:"already_running"`
+}
+
 // this must not contain t because it runs in a waited goroutine
-func update(token string) (int, error) {
+func startUpdate(token string) (int, jobReply, error) {
 	req, err := http.NewRequest(http.MethodPost, address+"/api/db/update", nil)
 	if err != nil {
-		return 0, err
+		return 0, jobReply{}, err
 	}
 	req.Header.Add("Authorization", "Token "+token)
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, err
+		return 0, jobReply{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return resp.StatusCode, jobReply{}, nil
+	}
+	var reply jobReply
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return 0, jobReply{}, fmt.Errorf("could not decode: %v", err)
+	}
+	return resp.StatusCode, reply, nil
+}
+
+// waitUpdate polls the update job until it is over and expects it done.
+func waitUpdate(t *testing.T, job string) {
+	deadline := time.Now().Add(10 * time.Minute)
+	for {
+		resp, err := client.Get(address + "/api/db/update/" + job)
+		require.NoError(t, err, "could not get update progress")
+		var progress struct {
+			State string `json:"state"`
+			Error string `json:"error"`
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		err = json.NewDecoder(resp.Body).Decode(&progress)
+		resp.Body.Close()
+		require.NoError(t, err, "cannot decode")
+		if progress.State != "running" {
+			require.Equal(t, "done", progress.State, progress.Error)
+			return
+		}
+		require.True(t, time.Now().Before(deadline), "update takes too long")
+		time.Sleep(time.Second)
 	}
-	resp.Body.Close()
-	return resp.StatusCode, nil
 }
 
 // this must not contain t because it runs in a waited goroutine